@@ -19,6 +19,9 @@ func main() {
 	countFlag := flag.Int("count", 0, "airdroperc count")
 	batchSizeFlag := flag.Int("batchSize", 0, "batch size of airdroperc")
 	amountFlag := flag.String("amount", "", "amount range of airdroperc: 0-amount")
+	dryRunFlag := flag.Bool("dryRun", false, "only estimate batches, gas cost and balances without sending")
+	persistWalletsFlag := flag.Bool("persistWallets", false, "encrypt and save generated wallets for later export")
+	campaignFlag := flag.String("campaign", "", "campaign label recorded with saved wallets")
 
 	flag.Parse()
 
@@ -94,6 +97,37 @@ func main() {
 	}
 
 	airdropService := service.NewAirdropService(clientInfo, log)
+	if *dryRunFlag {
+		plan, err := airdropService.PlanAirdropBNB(*countFlag, *batchSizeFlag, amountArray)
+		if err != nil {
+			log.WithFields(map[string]interface{}{
+				"action":     "airdrop_bnb_dry_run",
+				"error_code": "DRY_RUN_FAIL",
+				"detail":     err.Error(),
+			}).Fatal("Airdrop BNB dry run failed")
+		}
+		log.WithFields(map[string]interface{}{
+			"action":               "airdrop_bnb_dry_run",
+			"total_batches":        plan.TotalBatches,
+			"failed_batches":       plan.FailedBatches,
+			"total_amount":         plan.TotalAmount,
+			"gas_price":            plan.GasPrice,
+			"estimated_gas":        plan.EstimatedGas,
+			"estimated_cost":       plan.EstimatedCost,
+			"bnb_balance":          plan.BnbBalance,
+			"bnb_sufficient":       plan.BnbSufficient,
+			"token_balance":        plan.TokenBalance,
+			"token_sufficient":     plan.TokenSufficient,
+			"allowance":            plan.Allowance,
+			"allowance_sufficient": plan.AllowanceSufficient,
+			"executable":           plan.Executable,
+			"batches":              plan.Batches,
+		}).Info("Airdrop BNB dry run finished")
+		os.Exit(0)
+	}
+	if *persistWalletsFlag {
+		airdropService.PersistWallets(*campaignFlag)
+	}
 	response, err := airdropService.AirdropBNB(*countFlag, *batchSizeFlag, amountArray)
 	if err != nil {
 		log.WithFields(map[string]interface{}{
@@ -112,6 +146,7 @@ func main() {
 		"SuccessBatches":   response.SuccessBatches,
 		"FailBatches":      response.FailBatches,
 		"Data":             response.Data,
+		"GenerationID":     response.GenerationID,
 	}).Info("Airdrop BNB succeeded")
 
 	if !utils.IsEmptyOrSpaceString(response.Error) {
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"os/user"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/common/logger"
+	"staking-interaction/service"
+	"time"
+)
+
+// 子命令与治理操作的对应关系
+var subcommands = map[string]string{
+	"set-gov":            config.GovActionSetGov,
+	"remove-gov":         config.GovActionRemoveGov,
+	"transfer-ownership": config.GovActionTransferOwnership,
+	"renounce-ownership": config.GovActionRenounceOwnership,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: airdropgov <command> [flags]
+
+commands:
+  list                                         list owner, gov addresses and owner history
+  set-gov            -address <addr> [-confirm] add a gov address
+  remove-gov         -address <addr> [-confirm] remove a gov address
+  transfer-ownership -address <addr> [-confirm] transfer contract ownership
+  renounce-ownership                 [-confirm] renounce contract ownership
+
+without -confirm the change is only previewed and no transaction is sent`)
+}
+
+func main() {
+	log := logger.GetLogger()
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	action, ok := subcommands[command]
+	if command != "list" && !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	addressFlag := flags.String("address", "", "target address")
+	confirmFlag := flags.Bool("confirm", false, "send the transaction, otherwise only preview the change")
+	_ = flags.Parse(os.Args[2:])
+
+	// 连接数据库
+	err := adapter.MysqlConn()
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"action":     "init_db",
+			"error_code": "DB_CONN_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("MySQL database connect failed")
+		return
+	}
+	defer func() {
+		err := adapter.CloseConn()
+		if err != nil {
+			log.WithFields(map[string]interface{}{
+				"action":     "close_db",
+				"error_code": "DB_CLOSE_FAIL",
+				"detail":     err.Error(),
+			}).Error("Close database failed")
+		}
+	}()
+
+	clientInfo, err := adapter.NewInitEthClient()
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"action":     "init_client",
+			"error_code": "CLIENT_INIT_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Init client failed")
+	}
+	defer clientInfo.CloseEthClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	govService := service.NewAirdropGovService(clientInfo, nil)
+
+	if command == "list" {
+		res, err := govService.ListGovernors(ctx)
+		if err != nil {
+			log.WithFields(map[string]interface{}{
+				"action":     "list_governors",
+				"error_code": "LIST_GOV_FAIL",
+				"detail":     err.Error(),
+			}).Fatal("List governors failed")
+		}
+		log.WithFields(map[string]interface{}{
+			"action":            "list_governors",
+			"contract":          res.Contract,
+			"owner":             res.Owner,
+			"hot_wallet":        res.HotWallet,
+			"hot_wallet_is_gov": res.HotWalletIsGov,
+			"governors":         res.Governors,
+			"owner_history":     res.OwnerHistory,
+		}).Info("Airdrop governance")
+		return
+	}
+
+	preview, err := govService.PrepareGovChange(ctx, actor(), action, *addressFlag)
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"action":     action,
+			"error_code": "GOV_PREPARE_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Gov change check failed")
+	}
+	log.WithFields(map[string]interface{}{
+		"action":        action,
+		"target":        preview.Target,
+		"contract":      preview.Contract,
+		"owner":         preview.Owner,
+		"hot_wallet":    preview.HotWallet,
+		"target_is_gov": preview.TargetIsGov,
+		"warning":       preview.Warning,
+	}).Info("Gov change preview")
+	if !*confirmFlag {
+		log.Info("Preview only, rerun with -confirm to send the transaction")
+		return
+	}
+
+	result, err := govService.ExecuteGovChange(ctx, actor(), action, common.HexToAddress(preview.Target))
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"action":     action,
+			"error_code": "GOV_EXECUTE_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Gov change failed")
+	}
+	log.WithFields(map[string]interface{}{
+		"action":       action,
+		"audit_id":     result.AuditID,
+		"tx_hash":      result.TxHash,
+		"status":       result.Status,
+		"block_number": result.BlockNumber,
+	}).Info("Gov change sent")
+}
+
+// actor 审计日志中的 CLI 操作人
+func actor() string {
+	if u, err := user.Current(); err == nil {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
@@ -108,6 +108,7 @@ func main() {
 		"amount":        *amountFlag,
 		"period":        *periodFlag,
 		"tx_hash":       response.Hash,
+		"approve_hash":  response.ApproveHash,
 		"contract_addr": response.ContractAddress,
 		"from_addr":     response.FromAddress.Hex(),
 		"method":        response.Method,
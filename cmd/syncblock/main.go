@@ -8,6 +8,7 @@ import (
 	"staking-interaction/common/logger"
 	redisClient "staking-interaction/common/redis"
 	"staking-interaction/listener"
+	"staking-interaction/repository"
 	"staking-interaction/service"
 	"syscall"
 )
@@ -30,6 +31,20 @@ func main() {
 		return
 	}
 
+	// 同步充值、提现按钱包查账户，先补写历史账户的钱包关联
+	backfilled, err := repository.BackfillAccountWallets()
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"action":     "backfill_account_wallets",
+			"error_code": "BACKFILL_WALLET_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Backfill account wallets failed")
+	}
+	log.WithFields(map[string]interface{}{
+		"action": "backfill_account_wallets",
+		"rows":   backfilled,
+	}).Info("Account wallets backfilled")
+
 	defer func() {
 		err := adapter.CloseConn()
 		if err != nil {
@@ -75,7 +90,7 @@ func main() {
 		"action": "init_sync_block",
 		"detail": "Initializing sync block",
 	}).Info("Initializing sync block...")
-	syncBlock := listener.NewSyncBlockInfo(clientInfo, conf.BlockchainConfig, lockManager, log)
+	syncBlock := listener.NewSyncBlockInfo(clientInfo, conf.BlockchainConfig, lockManager, service.NewPendingDepositStore(redis), log)
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -158,13 +173,99 @@ func main() {
 		syncWithdraw.Stop()
 	}()
 
+	// 8. stake tvl checker
+	log.WithFields(map[string]interface{}{
+		"action": "init_stake_tvl_checker",
+		"detail": "Initializing stake tvl checker",
+	}).Info("Initializing stake tvl checker...")
+	tvlChecker := listener.NewStakeTvlChecker(service.NewStakeStatsService(clientInfo), conf.BlockchainConfig.Stake, log)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(map[string]interface{}{
+					"action": "stake_tvl_checker_panic",
+					"detail": r,
+				}).Error("StakeTvlChecker panic")
+			}
+		}()
+		tvlChecker.Start()
+	}()
+	defer tvlChecker.Stop()
+
+	// 9. stake maturity scheduler
+	log.WithFields(map[string]interface{}{
+		"action": "init_stake_maturity_scheduler",
+		"detail": "Initializing stake maturity scheduler",
+	}).Info("Initializing stake maturity scheduler...")
+	maturityConf := conf.BlockchainConfig.Stake.Maturity
+	notifiers, err := service.NewNotifiers(maturityConf)
+	if err != nil {
+		log.WithFields(map[string]interface{}{
+			"action":     "init_notifiers",
+			"error_code": "NOTIFIER_INIT_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Init notifiers failed")
+	}
+	maturityScheduler := listener.NewStakeMaturityScheduler(
+		service.NewStakeMaturityService(clientInfo, notifiers, maturityConf), maturityConf, log)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(map[string]interface{}{
+					"action": "stake_maturity_scheduler_panic",
+					"detail": r,
+				}).Error("StakeMaturityScheduler panic")
+			}
+		}()
+		maturityScheduler.Start()
+	}()
+	defer maturityScheduler.Stop()
+
+	// 10. merkle claim listener
+	log.WithFields(map[string]interface{}{
+		"action": "init_merkle_claim_listener",
+		"detail": "Initializing merkle claim listener",
+	}).Info("Initializing merkle claim listener...")
+	claimListener := listener.NewMerkleClaimListener(clientInfo, conf.BlockchainConfig, log)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(map[string]interface{}{
+					"action": "merkle_claim_listener_panic",
+					"detail": r,
+				}).Error("MerkleClaimListener panic")
+			}
+		}()
+		claimListener.Start()
+	}()
+	defer claimListener.Stop()
+
+	// 11. airdrop event listener
+	log.WithFields(map[string]interface{}{
+		"action": "init_airdrop_event_listener",
+		"detail": "Initializing airdrop event listener",
+	}).Info("Initializing airdrop event listener...")
+	airdropEventListener := listener.NewAirdropEventListener(clientInfo, conf.BlockchainConfig, log)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(map[string]interface{}{
+					"action": "airdrop_event_listener_panic",
+					"detail": r,
+				}).Error("AirdropEventListener panic")
+			}
+		}()
+		airdropEventListener.Start()
+	}()
+	defer airdropEventListener.Stop()
+
 	log.WithFields(map[string]interface{}{
 		"action": "service_initialized",
-		"detail": "SyncWithdrawHandler launched, all services initialized",
+		"detail": "AirdropEventListener launched, all services initialized",
 	}).Info("Services initialized")
 
-	// 8. 等待关闭信号
-	signalChan := make(chan os.Signal)
+	// 12. 等待关闭信号
+	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	log.WithFields(map[string]interface{}{
 		"action": "wait_signal",
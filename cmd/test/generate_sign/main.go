@@ -2,55 +2,68 @@ package main
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/crypto"
 	"log"
-	"math/rand"
 	"staking-interaction/common/config"
+	"staking-interaction/utils"
+	"strconv"
 	"time"
 )
 
-// 构造挑战消息
-func ChallengeMessage(nonce int, timestamp int64) string {
-	return fmt.Sprintf("Welcome to DApp! Please sign this message to login.\nNonce: %d\nTimestamp: %d\n", nonce, timestamp)
-}
-
+// 本地生成 EIP-4361 登录消息和签名，nonce 需先通过 GET /v1/login/nonce 获取
 func main() {
+	nonceFlag := flag.String("nonce", "", "nonce issued by GET /v1/login/nonce")
+	flag.Parse()
+	if *nonceFlag == "" {
+		log.Fatal("-nonce is required, get one from GET /v1/login/nonce")
+	}
+
 	conf := config.Get()
-	key, _ := crypto.GenerateKey()
-	fmt.Println("PrivKey hex:", hex.EncodeToString(key.D.Bytes()))
+	siwe := conf.AuthConfig.Siwe
+
 	// 本地测试参数
-	privateKeyHex := conf.BlockchainConfig.PrivateKey
-	walletAddr := conf.BlockchainConfig.Owners[1]
-	nonce := rand.Intn(999999)
-	timestamp := time.Now().Unix()
+	privateKey, err := crypto.HexToECDSA(conf.BlockchainConfig.PrivateKey)
+	if err != nil {
+		log.Fatalf("私钥格式错误: %v", err)
+	}
+	walletAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
 
 	// 构造消息
-	msg := ChallengeMessage(nonce, timestamp)
-	fmt.Println("Challenge Message:")
+	issuedAt := time.Now().UTC()
+	expiration := issuedAt.Add(siwe.NonceTTL)
+	message := &utils.SignInMessage{
+		Domain:         siwe.Domain,
+		Chain:          utils.SignInChainEthereum,
+		Address:        walletAddr.Hex(),
+		Statement:      siwe.Statement,
+		URI:            siwe.URI,
+		Version:        "1",
+		ChainID:        strconv.FormatInt(siwe.ChainID, 10),
+		Nonce:          *nonceFlag,
+		IssuedAt:       issuedAt,
+		ExpirationTime: &expiration,
+	}
+	msg := message.String()
+	fmt.Println("Sign-In Message:")
 	fmt.Println(msg)
 
-	// 按 EIP-191 进行消息哈希（个人签名标准）
-	msgPrefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(msg), msg)
-	msgHash := crypto.Keccak256([]byte(msgPrefix))
-
-	// 用私钥签名
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		log.Fatalf("私钥格式错误: %v", err)
-	}
-	signature, err := crypto.Sign(msgHash, privateKey)
+	// 按 EIP-191 进行消息哈希（个人签名标准），与钱包 personal_sign 一致
+	signature, err := crypto.Sign(accounts.TextHash([]byte(msg)), privateKey)
 	if err != nil {
 		log.Fatalf("签名失败: %v", err)
 	}
+	// 钱包返回的 V 为 27/28，服务端两种格式都接受
+	signature[crypto.RecoveryIDOffset] += 27
 
-	// V 值处理：crypto.Sign 输出的 V 已经是 0/1，无需处理
-	// 如需兼容部分前端钱包，可以将 V 改为 27 或 28
-	// signature[64] += 27
-
-	// 输出结果
-	fmt.Printf("钱包地址: %s\n", walletAddr)
-	fmt.Printf("nonce: %d\n", nonce)
-	fmt.Printf("timestamp: %d\n", timestamp)
-	fmt.Printf("signature: 0x%s\n", hex.EncodeToString(signature))
+	body, _ := json.MarshalIndent(map[string]string{
+		"message":   msg,
+		"signature": "0x" + hex.EncodeToString(signature),
+	}, "", "  ")
+	fmt.Printf("钱包地址: %s\n", walletAddr.Hex())
+	fmt.Println("POST /v1/login/bsc body:")
+	fmt.Println(string(body))
 }
@@ -2,51 +2,70 @@ package main
 
 import (
 	"crypto/ed25519"
-	rander "crypto/rand"
+	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"math/rand"
+	"log"
+	"staking-interaction/common/config"
+	"staking-interaction/utils"
 	"time"
 
 	"github.com/mr-tron/base58"
 )
 
-// 测试数据结构
-type TestUser struct {
-	Nonce     int // base58 公钥
-	Wallet    string
-	Signature string // base58 签名
-	Timestamp int64
-}
-
+// 本地生成 SIWS 登录消息和签名，nonce 需先通过 GET /v1/login/nonce?chain=solana 获取
 func main() {
-	rand.Seed(time.Now().UnixNano())
-	var users []TestUser
-	publicKey, privateKey, _ := ed25519.GenerateKey(rander.Reader)
-
-	fmt.Printf("privateKey (len=%d): %s\n", len(privateKey), hex.EncodeToString(privateKey)) // 64字节
-	fmt.Printf("publicKey (len=%d): %s\n", len(publicKey), hex.EncodeToString(publicKey))    // 32字节
-
-	for i := 0; i < 5; i++ {
-		pub, priv, _ := ed25519.GenerateKey(nil)
-		wallet := base58.Encode(pub)
-		timestamp := time.Now().Unix()
-		nonce := rand.Intn(999999)
-		challenge := fmt.Sprintf("SOLANA LOGIN: %d - %d", nonce, timestamp)
-
-		signature := ed25519.Sign(priv, []byte(challenge))
-		signatureB58 := base58.Encode(signature)
+	nonceFlag := flag.String("nonce", "", "nonce issued by GET /v1/login/nonce?chain=solana")
+	seedFlag := flag.String("seed", "", "hex ed25519 seed of the test wallet, random when empty")
+	flag.Parse()
+	if *nonceFlag == "" {
+		log.Fatal("-nonce is required, get one from GET /v1/login/nonce?chain=solana")
+	}
 
-		users = append(users, TestUser{
-			Nonce:     nonce,
-			Wallet:    wallet,
-			Signature: signatureB58,
-			Timestamp: timestamp,
-		})
+	var privateKey ed25519.PrivateKey
+	if *seedFlag != "" {
+		seed, err := hex.DecodeString(*seedFlag)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			log.Fatalf("seed 格式错误: %v", err)
+		}
+		privateKey = ed25519.NewKeyFromSeed(seed)
+	} else {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.Fatalf("生成密钥失败: %v", err)
+		}
+		privateKey = key
+		fmt.Printf("seed: %s\n", hex.EncodeToString(key.Seed()))
 	}
+	wallet := base58.Encode(privateKey.Public().(ed25519.PublicKey))
 
-	// 打印测试数据（可存到文件或数据库）
-	for _, u := range users {
-		fmt.Printf("Nonce: %d\nWallet: %s\nSignature: %s\nTimestamp: %d\n\n", u.Nonce, u.Wallet, u.Signature, u.Timestamp)
+	siws := config.Get().AuthConfig.Siws
+	issuedAt := time.Now().UTC()
+	expiration := issuedAt.Add(siws.NonceTTL)
+	message := &utils.SignInMessage{
+		Domain:         siws.Domain,
+		Chain:          utils.SignInChainSolana,
+		Address:        wallet,
+		Statement:      siws.Statement,
+		URI:            siws.URI,
+		Version:        "1",
+		ChainID:        siws.Cluster,
+		Nonce:          *nonceFlag,
+		IssuedAt:       issuedAt,
+		ExpirationTime: &expiration,
 	}
+	msg := message.String()
+	fmt.Println("Sign-In Message:")
+	fmt.Println(msg)
+
+	signature := ed25519.Sign(privateKey, []byte(msg))
+	body, _ := json.MarshalIndent(map[string]string{
+		"message":   msg,
+		"signature": base58.Encode(signature),
+	}, "", "  ")
+	fmt.Printf("Wallet: %s\n", wallet)
+	fmt.Println("POST /v1/login/solana body:")
+	fmt.Println(string(body))
 }
@@ -1,13 +1,12 @@
 package config
 
 import (
-	"crypto/ecdsa"
-	"crypto/ed25519"
 	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -31,6 +30,37 @@ type AppConfig struct {
 	Port        int           `yaml:"port"`
 	LogLevel    string        `yaml:"log_level"`
 	Timeout     time.Duration `yaml:"timeout"`
+	// 信任的反向代理地址，只有来自这些地址的请求才读取 X-Forwarded-For 作为客户端 IP
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// 接口限流，计数保存在 Redis，多实例共享
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// Idempotency-Key 请求的结果缓存
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+// IdempotencyConfig 带 Idempotency-Key 的写请求保存首次响应，重试时直接返回
+type IdempotencyConfig struct {
+	TTL         time.Duration `yaml:"ttl"`          // 首次响应的保存时长
+	LockTimeout time.Duration `yaml:"lock_timeout"` // 首次请求处理中的占用时长，超时后允许重新执行
+}
+
+// RateLimitConfig 按策略限流，签名校验连续失败的客户端 IP 临时封禁
+type RateLimitConfig struct {
+	Policies map[string]RateLimitRule `yaml:"policies"` // login, transfer, airdrop
+	Lockout  LockoutConfig            `yaml:"lockout"`
+}
+
+// RateLimitRule 令牌桶规则，每个 IP、钱包、API Key 各自一个桶，Period 内补充 Limit 个令牌，桶容量为 Burst
+type RateLimitRule struct {
+	Limit  int           `yaml:"limit"`
+	Period time.Duration `yaml:"period"`
+	Burst  int           `yaml:"burst"` // 为 0 时等于 Limit
+}
+
+type LockoutConfig struct {
+	MaxFailures int           `yaml:"max_failures"` // Window 内签名校验失败达到该次数后封禁
+	Window      time.Duration `yaml:"window"`
+	Duration    time.Duration `yaml:"duration"` // 封禁时长
 }
 
 // ===== 数据库配置模块 =====
@@ -109,6 +139,15 @@ type BlockchainConfig struct {
 	// 同步配置
 	Sync SyncConfig `yaml:"sync"`
 
+	// 质押配置
+	Stake StakeConfig `yaml:"stake"`
+
+	// 交易配置
+	Transaction TransactionConfig `yaml:"transaction"`
+
+	// 空投活动执行配置
+	Airdrop AirdropConfig `yaml:"airdrop"`
+
 	// 合约地址
 	Contracts ContractAddresses `yaml:"contracts"`
 }
@@ -122,21 +161,103 @@ type SyncConfig struct {
 	RetryDelay    time.Duration `yaml:"retry_delay"`
 }
 
+type StakeConfig struct {
+	ApproveMax       bool          `yaml:"approve_max"`        // true: 授权最大额度, false: 按质押金额精确授权
+	ApproveTimeout   time.Duration `yaml:"approve_timeout"`    // 等待 approve 交易上链的超时时间
+	TvlCheckInterval time.Duration `yaml:"tvl_check_interval"` // 链上 TVL 对账间隔
+
+	// 到期通知配置
+	Maturity MaturityConfig `yaml:"maturity"`
+}
+
+type MaturityConfig struct {
+	ScanInterval   time.Duration `yaml:"scan_interval"`   // 扫描质押到期的间隔
+	UpcomingWindow time.Duration `yaml:"upcoming_window"` // 距离到期多久发送即将到期通知
+	Channels       []string      `yaml:"channels"`        // 启用的通知渠道: webhook, email, inbox
+	Webhook        WebhookConfig `yaml:"webhook"`
+	Email          EmailConfig   `yaml:"email"`
+}
+
+type WebhookConfig struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type EmailConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"` // 为空时不做 SMTP 认证，适用于本地 SMTP 服务
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"` // 运维收件人，邮件渠道只发给这些地址，不发给用户
+}
+
+type TransactionConfig struct {
+	GasLimit      uint64        `yaml:"gas_limit"`
+	GasPrice      string        `yaml:"gas_price"`
+	ConfirmBlocks uint64        `yaml:"confirm_blocks"` // 默认确认区块数
+	Timeout       time.Duration `yaml:"timeout"`        // wait 模式最长等待时间
+	RetryAttempts int           `yaml:"retry_attempts"`
+	RetryDelay    time.Duration `yaml:"retry_delay"`
+	TrackInterval time.Duration `yaml:"track_interval"` // 回执轮询间隔
+	TrackMaxAge   time.Duration `yaml:"track_max_age"`  // 超过该时长仍未上链的交易标记为已丢弃
+}
+
+type AirdropConfig struct {
+	ExecuteInterval time.Duration `yaml:"execute_interval"` // 执行器轮询间隔
+	MaxInFlight     int           `yaml:"max_in_flight"`    // 每个活动同时等待回执的批次数上限
+	MaxAttempts     int           `yaml:"max_attempts"`     // 批次签名失败后自动重试的次数上限
+	DroppedPolls    int           `yaml:"dropped_polls"`    // nonce 被占用且查不到交易的连续轮询次数达到该值后转人工核对
+
+	WalletKeystorePassword string `yaml:"wallet_keystore_password"` // 生成钱包 keystore 的加密密码，为空时不支持保存生成的钱包
+}
+
 type ContractAddresses struct {
 	Stake   string `yaml:"stake_address"`
 	Airdrop string `yaml:"airdrop_address"`
 	Token   string `yaml:"token_address"`
+	Claim   string `yaml:"claim_address"` // Merkle 领取合约
+
+	TokenDecimals uint8 `yaml:"token_decimals"` // 代币精度，与代币合约 decimals() 一致，用于格式化余额
 }
 
 type AuthConfig struct {
-	JwtSecret        string             `yaml:"jwt_secret"`
-	JwtExpiration    time.Duration      `yaml:"jwt_expiration"`
-	Prefix           string             `yaml:"prefix"`
-	MaxDelta         int                `yaml:"max_delta"` //分钟
-	EcdsaPublicKey   *ecdsa.PublicKey   `yaml:"ecdsa_public_key"`
-	EcdsaPrivateKey  string             `yaml:"ecdsa_private_key"`
-	Ed25519PublicKey *ed25519.PublicKey `yaml:"ed25519_public_key"`
-	Ed25519Seed      string             `yaml:"ed25519_seed"`
+	JwtSecret         string           `yaml:"jwt_secret"`
+	JwtExpiration     time.Duration    `yaml:"jwt_expiration"`     // access token 有效期
+	RefreshExpiration time.Duration    `yaml:"refresh_expiration"` // refresh token 有效期，每次刷新后重新计算
+	Prefix            string           `yaml:"prefix"`
+	MaxDelta          int              `yaml:"max_delta"` //分钟
+	SigningKeys       SigningKeyConfig `yaml:"signing_keys"`
+	Admins            []string         `yaml:"admins"`      // 引导管理员钱包地址，始终拥有 admin 角色，用于首次分配角色
+	ConfirmTTL        time.Duration    `yaml:"confirm_ttl"` // 管理操作确认令牌有效期
+	Siwe              SignInConfig     `yaml:"siwe"`
+	Siws              SignInConfig     `yaml:"siws"`
+	ApiKey            ApiKeyConfig     `yaml:"api_key"`
+}
+
+// ApiKeyConfig 服务间调用的 API Key，请求使用 HMAC-SHA256 签名
+type ApiKeyConfig struct {
+	MasterKey     string        `yaml:"master_key"`     // 加密保存 API Key 密钥的 AES-256 密钥，64 位十六进制
+	MaxSkew       time.Duration `yaml:"max_skew"`       // 请求时间戳允许的误差，nonce 在该窗口内不可重复
+	RotationGrace time.Duration `yaml:"rotation_grace"` // 轮换后旧密钥继续有效的时长
+}
+
+// SigningKeyConfig JWT 签名密钥，私钥以 PKCS#8 PEM 保存在 Dir 下，keys.json 清单记录 kid 和轮换时间，多实例需共享该目录
+type SigningKeyConfig struct {
+	Dir              string        `yaml:"dir"`
+	RotationInterval time.Duration `yaml:"rotation_interval"` // 当前密钥使用超过该时长后自动轮换，0 表示不自动轮换
+	GraceWindow      time.Duration `yaml:"grace_window"`      // 轮换后旧密钥继续用于验签的时长，不小于 jwt_expiration
+	CheckInterval    time.Duration `yaml:"check_interval"`    // 轮换检查间隔，下一把密钥提前该时长发布到 JWKS
+}
+
+// SignInConfig 签名登录消息的域名绑定，服务端只接受与配置一致的消息
+type SignInConfig struct {
+	Domain    string        `yaml:"domain"`
+	URI       string        `yaml:"uri"`
+	Statement string        `yaml:"statement"`
+	ChainID   int64         `yaml:"chain_id"`  // EIP-155 链 ID
+	Cluster   string        `yaml:"cluster"`   // Solana 消息中的 Chain ID: mainnet/devnet/testnet
+	NonceTTL  time.Duration `yaml:"nonce_ttl"` // 服务端下发 nonce 的有效期
 }
 
 type LogConfig struct {
@@ -211,7 +332,23 @@ func getDefaultConfigPath() string {
 	if env == "" {
 		env = "local"
 	}
-	return fmt.Sprintf("common/config/config.%s.yaml", env)
+	configPath := fmt.Sprintf("common/config/config.%s.yaml", env)
+	// 在子目录运行（如 go test）时向上查找项目根目录下的配置文件
+	dir, err := os.Getwd()
+	if err != nil {
+		return configPath
+	}
+	for {
+		candidate := filepath.Join(dir, configPath)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return configPath
+		}
+		dir = parent
+	}
 }
 
 // expandEnvVars 替换配置文件中的环境变量
@@ -238,6 +375,42 @@ func setDefaults(config *Config) {
 		config.AppConfig.Timeout = 30 * time.Second
 	}
 
+	if config.AppConfig.RateLimit.Policies == nil {
+		config.AppConfig.RateLimit.Policies = map[string]RateLimitRule{}
+	}
+	defaultPolicies := map[string]RateLimitRule{
+		RateLimitPolicyLogin:    {Limit: 10, Period: time.Minute},
+		RateLimitPolicyTransfer: {Limit: 30, Period: time.Minute},
+		RateLimitPolicyAirdrop:  {Limit: 60, Period: time.Minute},
+	}
+	for policy, rule := range defaultPolicies {
+		if _, exists := config.AppConfig.RateLimit.Policies[policy]; !exists {
+			config.AppConfig.RateLimit.Policies[policy] = rule
+		}
+	}
+	for policy, rule := range config.AppConfig.RateLimit.Policies {
+		if rule.Burst == 0 {
+			rule.Burst = rule.Limit
+			config.AppConfig.RateLimit.Policies[policy] = rule
+		}
+	}
+	if config.AppConfig.RateLimit.Lockout.MaxFailures == 0 {
+		config.AppConfig.RateLimit.Lockout.MaxFailures = 5
+	}
+	if config.AppConfig.RateLimit.Lockout.Window == 0 {
+		config.AppConfig.RateLimit.Lockout.Window = 15 * time.Minute
+	}
+	if config.AppConfig.RateLimit.Lockout.Duration == 0 {
+		config.AppConfig.RateLimit.Lockout.Duration = 15 * time.Minute
+	}
+
+	if config.AppConfig.Idempotency.TTL == 0 {
+		config.AppConfig.Idempotency.TTL = 24 * time.Hour
+	}
+	if config.AppConfig.Idempotency.LockTimeout == 0 {
+		config.AppConfig.Idempotency.LockTimeout = 5 * time.Minute
+	}
+
 	// DatabaseConfig 默认值
 	if config.DatabaseConfig.Driver == "" {
 		config.DatabaseConfig.Driver = "mysql"
@@ -328,6 +501,99 @@ func setDefaults(config *Config) {
 	if config.BlockchainConfig.Sync.RetryDelay == 0 {
 		config.BlockchainConfig.Sync.RetryDelay = 5 * time.Second
 	}
+	if config.BlockchainConfig.Transaction.ConfirmBlocks == 0 {
+		config.BlockchainConfig.Transaction.ConfirmBlocks = 3
+	}
+	if config.BlockchainConfig.Transaction.Timeout == 0 {
+		config.BlockchainConfig.Transaction.Timeout = 30 * time.Second
+	}
+	if config.BlockchainConfig.Transaction.TrackInterval == 0 {
+		config.BlockchainConfig.Transaction.TrackInterval = 5 * time.Second
+	}
+	if config.BlockchainConfig.Transaction.TrackMaxAge == 0 {
+		config.BlockchainConfig.Transaction.TrackMaxAge = 24 * time.Hour
+	}
+	if config.BlockchainConfig.Contracts.TokenDecimals == 0 {
+		config.BlockchainConfig.Contracts.TokenDecimals = 18
+	}
+	if config.BlockchainConfig.Airdrop.ExecuteInterval == 0 {
+		config.BlockchainConfig.Airdrop.ExecuteInterval = 5 * time.Second
+	}
+	if config.BlockchainConfig.Airdrop.MaxInFlight == 0 {
+		config.BlockchainConfig.Airdrop.MaxInFlight = 5
+	}
+	if config.BlockchainConfig.Airdrop.MaxAttempts == 0 {
+		config.BlockchainConfig.Airdrop.MaxAttempts = 3
+	}
+	if config.BlockchainConfig.Airdrop.DroppedPolls == 0 {
+		config.BlockchainConfig.Airdrop.DroppedPolls = 3
+	}
+	if config.BlockchainConfig.Stake.ApproveTimeout == 0 {
+		config.BlockchainConfig.Stake.ApproveTimeout = 60 * time.Second
+	}
+	if config.BlockchainConfig.Stake.TvlCheckInterval == 0 {
+		config.BlockchainConfig.Stake.TvlCheckInterval = time.Hour
+	}
+	if config.BlockchainConfig.Stake.Maturity.ScanInterval == 0 {
+		config.BlockchainConfig.Stake.Maturity.ScanInterval = 10 * time.Minute
+	}
+	if config.BlockchainConfig.Stake.Maturity.UpcomingWindow == 0 {
+		config.BlockchainConfig.Stake.Maturity.UpcomingWindow = 24 * time.Hour
+	}
+	if len(config.BlockchainConfig.Stake.Maturity.Channels) == 0 {
+		config.BlockchainConfig.Stake.Maturity.Channels = []string{NotifyChannelInbox}
+	}
+	if config.BlockchainConfig.Stake.Maturity.Webhook.Timeout == 0 {
+		config.BlockchainConfig.Stake.Maturity.Webhook.Timeout = 10 * time.Second
+	}
+	if config.AuthConfig.JwtExpiration == 0 {
+		config.AuthConfig.JwtExpiration = 15 * time.Minute
+	}
+	if config.AuthConfig.RefreshExpiration == 0 {
+		config.AuthConfig.RefreshExpiration = 7 * 24 * time.Hour
+	}
+	if config.AuthConfig.ConfirmTTL == 0 {
+		config.AuthConfig.ConfirmTTL = 5 * time.Minute
+	}
+	if config.AuthConfig.SigningKeys.Dir == "" {
+		config.AuthConfig.SigningKeys.Dir = "data/jwt_keys"
+	}
+	if config.AuthConfig.SigningKeys.GraceWindow < config.AuthConfig.JwtExpiration {
+		config.AuthConfig.SigningKeys.GraceWindow = config.AuthConfig.JwtExpiration
+	}
+	if config.AuthConfig.SigningKeys.CheckInterval == 0 {
+		config.AuthConfig.SigningKeys.CheckInterval = 10 * time.Minute
+	}
+	if config.AuthConfig.ApiKey.MaxSkew == 0 {
+		config.AuthConfig.ApiKey.MaxSkew = 5 * time.Minute
+	}
+	if config.AuthConfig.ApiKey.RotationGrace == 0 {
+		config.AuthConfig.ApiKey.RotationGrace = 24 * time.Hour
+	}
+	if config.AuthConfig.Siwe.Domain == "" {
+		config.AuthConfig.Siwe.Domain = fmt.Sprintf("%s:%d", config.AppConfig.Host, config.AppConfig.Port)
+	}
+	if config.AuthConfig.Siwe.URI == "" {
+		config.AuthConfig.Siwe.URI = "http://" + config.AuthConfig.Siwe.Domain
+	}
+	if config.AuthConfig.Siwe.ChainID == 0 {
+		config.AuthConfig.Siwe.ChainID = 97
+	}
+	if config.AuthConfig.Siwe.NonceTTL == 0 {
+		config.AuthConfig.Siwe.NonceTTL = 5 * time.Minute
+	}
+	if config.AuthConfig.Siws.Domain == "" {
+		config.AuthConfig.Siws.Domain = config.AuthConfig.Siwe.Domain
+	}
+	if config.AuthConfig.Siws.URI == "" {
+		config.AuthConfig.Siws.URI = config.AuthConfig.Siwe.URI
+	}
+	if config.AuthConfig.Siws.Cluster == "" {
+		config.AuthConfig.Siws.Cluster = "devnet"
+	}
+	if config.AuthConfig.Siws.NonceTTL == 0 {
+		config.AuthConfig.Siws.NonceTTL = 5 * time.Minute
+	}
 
 	if config.LogConfig.Level == 0 {
 		if config.AppConfig.Environment == "local" {
@@ -344,6 +610,12 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("app.environment is required")
 	}
 
+	for policy, rule := range config.AppConfig.RateLimit.Policies {
+		if rule.Limit <= 0 || rule.Period <= 0 {
+			return fmt.Errorf("app.rate_limit.policies.%s requires positive limit and period", policy)
+		}
+	}
+
 	if config.DatabaseConfig.Host == "" {
 		return fmt.Errorf("database.host is required")
 	}
@@ -364,11 +636,3 @@ func validateConfig(config *Config) error {
 
 	return nil
 }
-
-func SetEcdsaPublicKey(config *Config, publicKey *ecdsa.PublicKey) {
-	config.AuthConfig.EcdsaPublicKey = publicKey
-}
-
-func SetEd25519PublicKey(config *Config, publicKey *ed25519.PublicKey) {
-	config.AuthConfig.Ed25519PublicKey = publicKey
-}
@@ -29,3 +29,151 @@ const (
 	StakedEventName    = "Staked"
 	WithdrawnEventName = "Withdrawn"
 )
+
+// OutboundTxStatus 接口发起交易的状态
+const (
+	OutboundTxStatusPending = 1
+	OutboundTxStatusSuccess = 2
+	OutboundTxStatusFailed  = 3
+	OutboundTxStatusDropped = 4 // nonce 已被其他交易占用且节点查不到该交易，或超过最长跟踪时间仍未上链
+)
+
+// 接口发起交易的用途
+const (
+	TxPurposeApprove      = "approve"
+	TxPurposeStake        = "stake"
+	TxPurposeWithdraw     = "withdraw"
+	TxPurposeTransferERC  = "transfer_erc20"
+	TxPurposeTransferBNB  = "transfer_bnb"
+	TxPurposeAirdropERC20 = "airdrop_erc20"
+	TxPurposeAirdropBNB   = "airdrop_bnb"
+	TxPurposeAirdropGov   = "airdrop_gov"
+)
+
+// 质押到期通知节点
+const (
+	StakeMilestoneUpcoming = "upcoming"
+	StakeMilestoneMatured  = "matured"
+)
+
+// 通知渠道
+const (
+	NotifyChannelWebhook = "webhook"
+	NotifyChannelEmail   = "email"
+	NotifyChannelInbox   = "inbox"
+)
+
+// NotifyStatus 通知发送状态
+const (
+	NotifyStatusSent   = 1
+	NotifyStatusFailed = 2
+)
+
+// 空投类型
+const (
+	AirdropKindERC20 = "erc20"
+	AirdropKindBNB   = "bnb"
+)
+
+// AirdropCampaignStatus 空投活动状态
+const (
+	AirdropCampaignStatusPending   = 1
+	AirdropCampaignStatusRunning   = 2
+	AirdropCampaignStatusCompleted = 3
+	AirdropCampaignStatusFailed    = 4 // 执行结束但存在失败批次，可重试
+)
+
+// AirdropBatchStatus 空投批次状态
+const (
+	AirdropBatchStatusPending = 1 // 待签名
+	AirdropBatchStatusSigned  = 2 // 已签名并落库，尚未确认广播成功
+	AirdropBatchStatusSent    = 3 // 已广播，等待回执
+	AirdropBatchStatusSuccess = 4
+	AirdropBatchStatusFailed  = 5 // 预执行失败、交易 revert 或多次被丢弃，未发生转账
+	AirdropBatchStatusReview  = 6 // nonce 已被占用但连续多轮查不到交易，不再自动重发，需通过释放接口核对链上状态后才能重发
+)
+
+// AirdropDeliveryStatus 空投批次与链上 Airdropped 事件的核对结果
+const (
+	AirdropDeliveryStatusUnchecked = 0
+	AirdropDeliveryStatusMatched   = 1
+	AirdropDeliveryStatusMismatch  = 2
+)
+
+// 空投合约治理操作
+const (
+	GovActionSetGov            = "set_gov"
+	GovActionRemoveGov         = "remove_gov"
+	GovActionTransferOwnership = "transfer_ownership"
+	GovActionRenounceOwnership = "renounce_ownership"
+)
+
+// 其他管理操作
+const (
+	AdminActionExportWallets       = "export_wallets"
+	AdminActionReleaseAirdropBatch = "release_airdrop_batch"
+)
+
+// AdminAuditStatus 管理操作审计状态
+const (
+	AdminAuditStatusPending = 1 // 已确认，交易尚未上链
+	AdminAuditStatusSuccess = 2
+	AdminAuditStatusFailed  = 3
+)
+
+// 生成钱包的来源，空投生成的钱包使用对应的交易用途
+const WalletSourceGenerate = "generate_wallet"
+
+// 登录链
+const (
+	LoginChainBSC    = "bsc"
+	LoginChainSolana = "solana"
+)
+
+// 账户角色，user 为所有登录账户的默认角色，其余角色记录在 account_role 表
+const (
+	RoleUser     = "user"
+	RoleOperator = "operator" // 使用平台热钱包发起转账、空投、质押
+	RoleAdmin    = "admin"
+	RoleAuditor  = "auditor" // 只读审计日志和生成钱包
+)
+
+// 接口权限，角色与权限的对应关系见 service.rolePermissions
+const (
+	PermTransfer     = "transfer:execute"
+	PermAirdrop      = "airdrop:execute"
+	PermStake        = "stake:execute"
+	PermGovernance   = "airdrop:governance"
+	PermWalletRead   = "wallet:read"
+	PermWalletExport = "wallet:export"
+	PermAuditRead    = "audit:read"
+	PermRoleManage   = "role:manage"
+	PermAPIKeyManage = "apikey:manage"
+	PermLedgerRead   = "ledger:read" // 查询任意账户的账单和充值记录
+)
+
+// API Key 状态
+const (
+	ApiKeyStatusActive  = 1
+	ApiKeyStatusRevoked = 2
+)
+
+// API Key 管理审计操作
+const (
+	AdminActionCreateApiKey = "create_api_key"
+	AdminActionRotateApiKey = "rotate_api_key"
+	AdminActionRevokeApiKey = "revoke_api_key"
+)
+
+// 角色管理审计操作
+const (
+	AdminActionGrantRole  = "grant_role"
+	AdminActionRevokeRole = "revoke_role"
+)
+
+// 限流策略，对应配置 app.rate_limit.policies
+const (
+	RateLimitPolicyLogin    = "login"
+	RateLimitPolicyTransfer = "transfer"
+	RateLimitPolicyAirdrop  = "airdrop"
+)
@@ -30,12 +30,16 @@ var LockTimeouts = map[string]time.Duration{
 	"transaction_lock": 10 * time.Second,
 	"asset_lock":       10 * time.Second,
 	"lock":             10 * time.Second,
+	"airdrop_lock":     60 * time.Second,
+	"jwt_key_lock":     30 * time.Second,
 }
 
 var LockAcquisitionTimeouts = map[string]time.Duration{
 	"withdraw_lock":    10 * time.Second,
 	"transaction_lock": 10 * time.Second,
 	"asset_lock":       10 * time.Second,
+	"airdrop_lock":     30 * time.Second,
+	"jwt_key_lock":     30 * time.Second,
 }
 
 // GetAssetLock 获取资产锁
@@ -77,6 +81,50 @@ func (l *LockManager) GetWithdrawLock(withdrawId int) *DistributedLock {
 	}
 }
 
+// GetAirdropExecutorLock 获取空投执行锁，同一时间只允许一个实例使用热钱包签名空投交易
+func (l *LockManager) GetAirdropExecutorLock() *DistributedLock {
+	return &DistributedLock{
+		redis:      l.redis,
+		lockKey:    "airdrop_lock:executor",
+		lockVal:    generateLockValue(),
+		expiration: LockTimeouts["airdrop_lock"],
+	}
+}
+
+// GetJWTKeyRotationLock 获取签名密钥轮换锁，多实例共享密钥目录时只允许一个实例写清单
+func (l *LockManager) GetJWTKeyRotationLock() *DistributedLock {
+	return &DistributedLock{
+		redis:      l.redis,
+		lockKey:    "jwt_key_lock:rotation",
+		lockVal:    generateLockValue(),
+		expiration: LockTimeouts["jwt_key_lock"],
+	}
+}
+
+// AcquireAirdropExecutorLock 等待执行器当前一轮结束后获取空投执行锁，接口使用热钱包签名或修改批次前调用
+func (l *LockManager) AcquireAirdropExecutorLock(ctx context.Context) (*DistributedLock, error) {
+	lock := l.GetAirdropExecutorLock()
+	timeout := LockAcquisitionTimeouts["airdrop_lock"]
+
+	if err := lock.Lock(ctx, timeout); err != nil {
+		return nil, fmt.Errorf("acquire airdrop executor lock: %w", err)
+	}
+
+	return lock, nil
+}
+
+// AcquireJWTKeyRotationLock 等待其他实例轮换或生成密钥结束后获取签名密钥轮换锁
+func (l *LockManager) AcquireJWTKeyRotationLock(ctx context.Context) (*DistributedLock, error) {
+	lock := l.GetJWTKeyRotationLock()
+	timeout := LockAcquisitionTimeouts["jwt_key_lock"]
+
+	if err := lock.Lock(ctx, timeout); err != nil {
+		return nil, fmt.Errorf("acquire jwt key rotation lock: %w", err)
+	}
+
+	return lock, nil
+}
+
 // AcquireAssetLock 直接获取并加锁
 func (l *LockManager) AcquireAssetLock(ctx context.Context, accountID int, tokenType int) (*DistributedLock, error) {
 	lock := l.GetAssetLock(accountID, tokenType)
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+)
+
+// requireAccount 账户接口只对钱包登录的账户开放，API Key 身份没有账户
+func requireAccount(c *gin.Context) (*dto.Principal, bool) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return nil, false
+	}
+	if principal.AccountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"msg": "account login required"})
+		return nil, false
+	}
+	return principal, true
+}
+
+// GetAccountWallets 当前账户关联的全部钱包
+func GetAccountWallets(c *gin.Context) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	wallets, err := service.GetAccountWallets(principal.AccountID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get account wallets failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": wallets})
+}
+
+// GetLinkWalletNonce 下发关联钱包的 nonce，query: chain=bsc|solana, address 可选
+func GetLinkWalletNonce(c *gin.Context, redis *redis.Client) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	res, err := service.NewAccountService(redis).IssueLinkNonce(c.Request.Context(), principal.AccountID, c.Query("chain"), c.Query("address"))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "issue link nonce failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// LinkAccountWallet 提交新钱包签名的关联消息
+func LinkAccountWallet(c *gin.Context, redis *redis.Client) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	var req dto.LinkWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	wallet, err := service.NewAccountService(redis).LinkWallet(c.Request.Context(), principal.AccountID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrWalletAlreadyLinked) {
+			abortWithError(c, http.StatusConflict, "link wallet failed", err)
+			return
+		}
+		abortSignatureError(c, redis, "link wallet failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": wallet})
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+	"strconv"
+)
+
+// GetAccountRoles 查询账户已分配的角色
+func GetAccountRoles(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "account id invalid"})
+		return
+	}
+	res, err := service.GetAccountRoleList(accountID)
+	if err != nil {
+		abortRoleError(c, "get account roles failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// GrantAccountRole 为账户授予角色
+func GrantAccountRole(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "account id invalid"})
+		return
+	}
+	var request dto.AccountRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	if err := service.GrantAccountRole(principal.WalletAddress, accountID, request.Role); err != nil {
+		abortRoleError(c, "grant role failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success"})
+}
+
+// RevokeAccountRole 撤销账户角色
+func RevokeAccountRole(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "account id invalid"})
+		return
+	}
+	if err := service.RevokeAccountRole(principal, accountID, c.Param("role")); err != nil {
+		abortRoleError(c, "revoke role failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success"})
+}
+
+func abortRoleError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": msg, "error": err.Error()})
+	case errors.Is(err, service.ErrInvalidRole),
+		errors.Is(err, service.ErrSelfAdminRevoke):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+	case errors.Is(err, service.ErrRoleAlreadyGranted),
+		errors.Is(err, service.ErrRoleNotGranted):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"msg": msg, "error": err.Error()})
+	default:
+		abortWithError(c, http.StatusInternalServerError, msg, err)
+	}
+}
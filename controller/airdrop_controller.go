@@ -1,31 +1,41 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"log"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 	"math/big"
 	"net/http"
 	"staking-interaction/adapter"
+	"staking-interaction/common/config"
 	log2 "staking-interaction/common/logger"
+	redisClient "staking-interaction/common/redis"
 	"staking-interaction/dto"
 	"staking-interaction/service"
 	"staking-interaction/utils"
+	"strconv"
 )
 
 func GenerateMultiWallets(c *gin.Context) {
 	var request dto.AirdropRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(500, gin.H{"msg": "request body invalid", "err": err})
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
 	}
-	wallets, err := service.GetMultiWallets(request.Count)
+	wallets, generationID, err := service.GenerateWallets(request.Count, request.PersistWallets, config.WalletSourceGenerate, request.Campaign)
 	if err != nil {
-		c.JSON(500, gin.H{"msg": "generate wallet failed", "err": err})
+		abortWalletError(c, "generate wallet failed", err)
+		return
 	}
-	c.JSON(200, gin.H{"msg": "generate success!", "data": wallets})
+	c.JSON(200, gin.H{"msg": "generate success!", "data": wallets, "generationId": generationID})
 
 }
 
-func AirdropERC20(c *gin.Context) {
+// AirdropERC20 随机生成钱包并同步空投，与后台执行器共用热钱包，发送期间持有空投执行锁
+func AirdropERC20(c *gin.Context, redis *redis.Client) {
 	logger := log2.GetLogger()
 	logger.WithFields(map[string]interface{}{
 		"module": "controller/airdroperc",
@@ -33,7 +43,8 @@ func AirdropERC20(c *gin.Context) {
 	var request dto.AirdropRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.AbortWithStatusJSON(500, gin.H{"msg": "request body invalid", "err": err})
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
 	}
 
 	reqCount := request.Count
@@ -55,24 +66,44 @@ func AirdropERC20(c *gin.Context) {
 
 	client, err := adapter.NewInitEthClient()
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "client init failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
 		return
 	}
 	amountArray, err := utils.GenerateRandomAmount(reqCount, reqAmount)
 	if err != nil {
-		log.Fatalf("failed to generate random amounts: %v", err)
+		abortWithError(c, http.StatusInternalServerError, "generate random amounts failed", err)
+		return
 	}
 
 	airdropService := service.NewAirdropService(client, logger)
+	if request.DryRun {
+		plan, err := airdropService.PlanAirdropERC20(reqCount, reqBatchSize, amountArray)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "Airdrop dry run failed", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"msg": "dry run", "data": plan})
+		return
+	}
+	if request.PersistWallets {
+		airdropService.PersistWallets(request.Campaign)
+	}
+	// 持锁期间执行器不会分配 nonce
+	lock, ok := acquireAirdropExecutorLock(c, redis)
+	if !ok {
+		return
+	}
+	defer lock.Unlock(context.Background())
 	responses, err := airdropService.AirdropERC20(reqCount, reqBatchSize, amountArray)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "Airdrop failed", "err": err.Error()})
+		abortWithError(c, http.StatusBadRequest, "Airdrop failed", err)
 		return
 	}
 	c.JSON(http.StatusOK, responses)
 }
 
-func AirdropBNB(c *gin.Context) {
+// AirdropBNB 同 AirdropERC20，空投原生币
+func AirdropBNB(c *gin.Context, redis *redis.Client) {
 	logger := log2.GetLogger()
 	logger.WithFields(map[string]interface{}{
 		"module": "controller/airdropbnb",
@@ -82,7 +113,8 @@ func AirdropBNB(c *gin.Context) {
 	)
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.AbortWithStatusJSON(500, gin.H{"msg": "request body invalid", "err": err})
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
 	}
 
 	reqCount := request.Count
@@ -103,20 +135,232 @@ func AirdropBNB(c *gin.Context) {
 	}
 	amountArray, err := utils.GenerateRandomAmount(reqCount, reqAmount)
 	if err != nil {
-		log.Fatalf("failed to generate random amounts: %v", err)
+		abortWithError(c, http.StatusInternalServerError, "generate random amounts failed", err)
+		return
 	}
 
 	client, err := adapter.NewInitEthClient()
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "client init failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
 		return
 	}
 	airdropService := service.NewAirdropService(client, logger)
+	if request.DryRun {
+		plan, err := airdropService.PlanAirdropBNB(reqCount, reqBatchSize, amountArray)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "Airdrop dry run failed", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"msg": "dry run", "data": plan})
+		return
+	}
+	if request.PersistWallets {
+		airdropService.PersistWallets(request.Campaign)
+	}
+	// 持锁期间执行器不会分配 nonce
+	lock, ok := acquireAirdropExecutorLock(c, redis)
+	if !ok {
+		return
+	}
+	defer lock.Unlock(context.Background())
 	responses, err := airdropService.AirdropBNB(reqCount, reqBatchSize, amountArray)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "Airdrop failed", "err": err})
+		abortWithError(c, http.StatusBadRequest, "Airdrop failed", err)
 		return
 	}
 	c.JSON(http.StatusOK, responses)
 
 }
+
+func AirdropCampaignERC20(c *gin.Context) {
+	airdropCampaign(c, (*service.AirdropService).CampaignERC20, (*service.AirdropService).PlanCampaignERC20)
+}
+
+func AirdropCampaignBNB(c *gin.Context) {
+	airdropCampaign(c, (*service.AirdropService).CampaignBNB, (*service.AirdropService).PlanCampaignBNB)
+}
+
+type campaignFunc func(*service.AirdropService, []dto.AirdropRecipient, int) (*dto.AirdropCampaignResponse, error)
+type campaignPlanFunc func(*service.AirdropService, []dto.AirdropRecipient, int) (*dto.AirdropPlan, error)
+
+// airdropCampaign dryRun=true 时只返回执行计划，不创建活动
+func airdropCampaign(c *gin.Context, create campaignFunc, plan campaignPlanFunc) {
+	request, err := bindCampaignRequest(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	if request.BatchSize <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "batchSize 必须大于 0"})
+		return
+	}
+
+	client, err := adapter.NewInitEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	airdropService := service.NewAirdropService(client, log2.GetLogger())
+	var response interface{}
+	if request.DryRun {
+		response, err = plan(airdropService, request.Recipients, request.BatchSize)
+	} else {
+		response, err = create(airdropService, request.Recipients, request.BatchSize)
+	}
+	if err != nil {
+		var recipientErr *service.RecipientError
+		switch {
+		case errors.As(err, &recipientErr):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "recipient list invalid", "error": err.Error(), "issues": recipientErr.Issues})
+		case errors.Is(err, service.ErrInsufficientBalance):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "insufficient balance", "error": err.Error()})
+		default:
+			abortWithError(c, http.StatusBadRequest, "Airdrop campaign failed", err)
+		}
+		return
+	}
+	if request.DryRun {
+		c.JSON(http.StatusOK, gin.H{"msg": "dry run", "data": response})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"msg": "campaign created", "data": response})
+}
+
+// GetAirdropCampaign 查询空投活动及批次执行情况
+func GetAirdropCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid campaign id"})
+		return
+	}
+	res, err := service.GetCampaign(id)
+	if err != nil {
+		abortCampaignError(c, "get campaign failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// GetAirdropCampaignDeliveries 查询活动的链上投递记录及批次核对结果
+func GetAirdropCampaignDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid campaign id"})
+		return
+	}
+	res, err := service.GetCampaignDeliveries(id)
+	if err != nil {
+		abortCampaignError(c, "get campaign deliveries failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// RetryAirdropCampaign 重置失败批次，由后台执行器重新签名发送
+func RetryAirdropCampaign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid campaign id"})
+		return
+	}
+	count, err := service.RetryCampaign(id)
+	if err != nil {
+		abortCampaignError(c, "retry campaign failed", err)
+		return
+	}
+	if count == 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"msg": "no failed batches to retry"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"msg": "retry scheduled", "data": gin.H{"retriedBatches": count}})
+}
+
+// ReleaseAirdropBatch 核对待核对批次的原交易，确认未上链后重置为待签名，由后台执行器重新签名发送
+func ReleaseAirdropBatch(c *gin.Context, redis *redis.Client) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid campaign id"})
+		return
+	}
+	batchNum, err := strconv.Atoi(c.Param("batchNum"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid batch number"})
+		return
+	}
+	client, err := adapter.NewInitEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseEthClient()
+
+	lock, ok := acquireAirdropExecutorLock(c, redis)
+	if !ok {
+		return
+	}
+	defer lock.Unlock(context.Background())
+
+	res, err := service.NewAirdropService(client, log2.GetLogger()).
+		ReleaseReviewBatch(c.Request.Context(), principal.WalletAddress, id, batchNum)
+	if err != nil {
+		abortCampaignError(c, "release airdrop batch failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// acquireAirdropExecutorLock 与后台执行器共用热钱包时先获取执行锁，避免 nonce 冲突或同时更新批次
+func acquireAirdropExecutorLock(c *gin.Context, redis *redis.Client) (*redisClient.DistributedLock, bool) {
+	lock, err := redisClient.NewLockManager(redis).AcquireAirdropExecutorLock(c.Request.Context())
+	if err != nil {
+		c.Header("Retry-After", "5")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"msg": "airdrop executor is busy", "error": err.Error()})
+		return nil, false
+	}
+	return lock, true
+}
+
+func abortCampaignError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": "campaign not found", "error": err.Error()})
+	case errors.Is(err, service.ErrBatchNotInReview), errors.Is(err, service.ErrBatchTxUnresolved):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"msg": msg, "error": err.Error()})
+	default:
+		abortWithError(c, http.StatusInternalServerError, msg, err)
+	}
+}
+
+// bindCampaignRequest 支持 JSON 请求体，或 multipart 上传 CSV 文件(file 字段) 加 batchSize 表单字段
+func bindCampaignRequest(c *gin.Context) (*dto.AirdropCampaignRequest, error) {
+	var request dto.AirdropCampaignRequest
+	if c.ContentType() != "multipart/form-data" {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			return nil, err
+		}
+		return &request, nil
+	}
+
+	if err := c.ShouldBind(&request); err != nil {
+		return nil, err
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("csv file is required: %w", err)
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open csv file failed: %w", err)
+	}
+	defer file.Close()
+	recipients, err := service.ParseRecipientsCSV(file)
+	if err != nil {
+		return nil, err
+	}
+	request.Recipients = recipients
+	return &request, nil
+}
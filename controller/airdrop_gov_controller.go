@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"staking-interaction/adapter"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+	"strconv"
+)
+
+// GetAirdropGovernance 查询空投合约 owner、gov 地址及 owner 变更历史
+func GetAirdropGovernance(c *gin.Context) {
+	client, err := adapter.NewInitEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseEthClient()
+	res, err := service.NewAirdropGovService(client, nil).ListGovernors(c.Request.Context())
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get airdrop governance failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// PrepareAirdropGovChange 预览治理操作并返回确认令牌，此时不会发送交易
+func PrepareAirdropGovChange(c *gin.Context, redis *redis.Client) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	var request dto.GovChangeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	client, err := adapter.NewInitEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseEthClient()
+	preview, err := service.NewAirdropGovService(client, redis).
+		PrepareGovChange(c.Request.Context(), principal.WalletAddress, request.Action, request.Target)
+	if err != nil {
+		abortGovError(c, "prepare gov change failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "confirm within the token lifetime to execute", "data": preview})
+}
+
+// ConfirmAirdropGovChange 使用确认令牌执行治理操作
+func ConfirmAirdropGovChange(c *gin.Context, redis *redis.Client) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	var request dto.GovConfirmRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	client, err := adapter.NewInitEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseEthClient()
+	res, err := service.NewAirdropGovService(client, redis).
+		ConfirmGovChange(c.Request.Context(), principal.WalletAddress, request.Token)
+	if err != nil {
+		abortGovError(c, "confirm gov change failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// GetAdminAuditLogs 查询管理操作审计日志
+func GetAdminAuditLogs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "limit should be between 1 and 500"})
+		return
+	}
+	logs, err := service.GetAdminAuditLogs(c.Query("action"), limit)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get audit logs failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": logs})
+}
+
+func abortGovError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidGovAction), errors.Is(err, service.ErrInvalidGovTarget), errors.Is(err, service.ErrConfirmTokenInvalid):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+	case errors.Is(err, service.ErrGovStateUnchanged):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"msg": msg, "error": err.Error()})
+	default:
+		abortWithError(c, http.StatusInternalServerError, msg, err)
+	}
+}
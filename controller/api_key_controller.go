@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+)
+
+// ListApiKeys 查询全部 API Key，不返回密钥
+func ListApiKeys(c *gin.Context, redis *redis.Client) {
+	keys, err := service.NewApiKeyService(redis).List()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get api keys failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": keys})
+}
+
+// CreateApiKey 为后台或合作方系统签发 API Key，密钥只返回一次
+func CreateApiKey(c *gin.Context, redis *redis.Client) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	var request dto.ApiKeyCreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	res, err := service.NewApiKeyService(redis).Create(principal.WalletAddress, request)
+	if err != nil {
+		abortApiKeyError(c, "create api key failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "store the secret now, it will not be shown again", "data": res})
+}
+
+// RotateApiKey 轮换密钥，旧密钥在宽限期内仍然有效
+func RotateApiKey(c *gin.Context, redis *redis.Client) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	res, err := service.NewApiKeyService(redis).Rotate(principal.WalletAddress, c.Param("keyId"))
+	if err != nil {
+		abortApiKeyError(c, "rotate api key failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "store the secret now, it will not be shown again", "data": res})
+}
+
+// RevokeApiKey 立即吊销 API Key
+func RevokeApiKey(c *gin.Context, redis *redis.Client) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	if err := service.NewApiKeyService(redis).Revoke(principal.WalletAddress, c.Param("keyId")); err != nil {
+		abortApiKeyError(c, "revoke api key failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success"})
+}
+
+func abortApiKeyError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrApiKeyNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": msg, "error": err.Error()})
+	case errors.Is(err, service.ErrInvalidApiKeyScope),
+		errors.Is(err, service.ErrInvalidAllowedIP),
+		errors.Is(err, service.ErrApiKeyInvalid):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+	default:
+		abortWithError(c, http.StatusInternalServerError, msg, err)
+	}
+}
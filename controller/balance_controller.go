@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"staking-interaction/adapter"
+	"staking-interaction/service"
+	"strconv"
+)
+
+// GetAccountBalances 当前账户各代币的可用、冻结和待确认充值金额
+func GetAccountBalances(c *gin.Context, redis *redis.Client) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	res, err := service.NewBalanceService(redis).GetAccountBalances(c.Request.Context(), principal.AccountID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get balances failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// GetAdminAccountBalances 客服查询指定账户余额，并对照其钱包的链上余额
+func GetAdminAccountBalances(c *gin.Context, redis *redis.Client) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "account id invalid"})
+		return
+	}
+	client, err := adapter.NewSyncEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseSyncEthClient()
+
+	res, err := service.NewBalanceService(redis).GetAdminAccountBalances(c.Request.Context(), client, accountID)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": "get balances failed", "error": err.Error()})
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, "get balances failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
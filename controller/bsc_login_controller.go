@@ -15,10 +15,32 @@ func LoginBSC(c *gin.Context, redis *redis.Client) {
 		return
 	}
 	bscService := service.NewAuthBSCService(redis)
-	token, err := bscService.Login(req.Signature, req.WalletAddress, req.Nonce, req.Timestamp)
+	res, err := bscService.Login(req.Message, req.Signature)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "login failed", "error": err})
+		abortSignatureError(c, redis, "login failed", err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, res)
+}
+
+// GetLoginNonce 下发一次性登录 nonce，chain 为 bsc(默认)或 solana，传入 address 时返回完整的待签名消息
+func GetLoginNonce(c *gin.Context, redis *redis.Client) {
+	var (
+		res *dto.LoginNonceResponse
+		err error
+	)
+	switch c.DefaultQuery("chain", "bsc") {
+	case "bsc":
+		res, err = service.NewAuthBSCService(redis).IssueLoginNonce(c.Request.Context(), c.Query("address"))
+	case "solana":
+		res, err = service.NewAuthSolanaService(redis).IssueLoginNonce(c.Request.Context(), c.Query("address"))
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "chain should be bsc or solana"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "issue nonce failed", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
 }
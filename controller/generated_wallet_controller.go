@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+	"strconv"
+	"time"
+)
+
+// ListGeneratedWallets 查询已保存的生成钱包地址
+func ListGeneratedWallets(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 10000 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "limit should be between 1 and 10000"})
+		return
+	}
+	wallets, err := service.GetGeneratedWallets(c.Query("generationId"), c.Query("campaign"), limit)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get generated wallets failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": wallets})
+}
+
+// ExportGeneratedWallets 下载 keystore zip 包，keystore 使用请求中的密码加密
+func ExportGeneratedWallets(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+	var request dto.WalletExportRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	data, count, err := service.ExportGeneratedWallets(principal.WalletAddress, request)
+	if err != nil {
+		abortWalletError(c, "export wallets failed", err)
+		return
+	}
+	filename := fmt.Sprintf("wallets-%s.zip", time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("X-Wallet-Count", strconv.Itoa(count))
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+func abortWalletError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrWalletPersistDisabled),
+		errors.Is(err, service.ErrExportPasswordTooWeak),
+		errors.Is(err, service.ErrExportFilterRequired):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+	case errors.Is(err, service.ErrNoWalletsToExport):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": msg, "error": err.Error()})
+	default:
+		abortWithError(c, http.StatusInternalServerError, msg, err)
+	}
+}
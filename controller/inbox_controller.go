@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"staking-interaction/service"
+)
+
+// GetInboxMessages 查询当前账户关联钱包的站内信，最近 100 条
+func GetInboxMessages(c *gin.Context) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	res, err := service.GetInboxMessages(principal.AccountID)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get inbox messages failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
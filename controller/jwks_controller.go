@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"staking-interaction/service"
+)
+
+// GetJWKS 公开 access token 的验签公钥，其他服务按 token header 中的 kid 选择公钥；遇到未知 kid 时应重新拉取
+func GetJWKS(c *gin.Context) {
+	keyManager, err := service.GetJWTKeyManager()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "load signing keys failed", err)
+		return
+	}
+	res, err := keyManager.JWKS()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "load signing keys failed", err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+	"strconv"
+	"time"
+)
+
+// GetAccountBills 当前账户的账单，支持 format=csv|json 导出
+func GetAccountBills(c *gin.Context) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	listBills(c, principal.AccountID)
+}
+
+// GetAccountDeposits 当前账户的充值记录，支持 format=csv|json 导出
+func GetAccountDeposits(c *gin.Context) {
+	principal, ok := requireAccount(c)
+	if !ok {
+		return
+	}
+	listDeposits(c, principal.AccountID)
+}
+
+// GetAdminAccountBills 客服查询指定账户的账单
+func GetAdminAccountBills(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "account id invalid"})
+		return
+	}
+	listBills(c, accountID)
+}
+
+// GetAdminAccountDeposits 客服查询指定账户的充值记录
+func GetAdminAccountDeposits(c *gin.Context) {
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || accountID <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "account id invalid"})
+		return
+	}
+	listDeposits(c, accountID)
+}
+
+func listBills(c *gin.Context, accountID int) {
+	var query dto.LedgerQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "query invalid", "error": err.Error()})
+		return
+	}
+	if query.Format != "" {
+		data, err := service.ExportAccountBills(accountID, query)
+		if err != nil {
+			abortLedgerError(c, "export bills failed", err)
+			return
+		}
+		writeLedgerExport(c, "bills", accountID, query.Format, data)
+		return
+	}
+	page, err := service.GetAccountBills(accountID, query)
+	if err != nil {
+		abortLedgerError(c, "get bills failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": page})
+}
+
+func listDeposits(c *gin.Context, accountID int) {
+	var query dto.LedgerQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "query invalid", "error": err.Error()})
+		return
+	}
+	if query.Format != "" {
+		data, err := service.ExportAccountDeposits(accountID, query)
+		if err != nil {
+			abortLedgerError(c, "export deposits failed", err)
+			return
+		}
+		writeLedgerExport(c, "deposits", accountID, query.Format, data)
+		return
+	}
+	page, err := service.GetAccountDeposits(accountID, query)
+	if err != nil {
+		abortLedgerError(c, "get deposits failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": page})
+}
+
+// writeLedgerExport 以附件形式下载对账单
+func writeLedgerExport(c *gin.Context, kind string, accountID int, format string, data []byte) {
+	contentType := "text/csv; charset=utf-8"
+	if format == service.LedgerFormatJSON {
+		contentType = "application/json"
+	}
+	filename := fmt.Sprintf("%s-%d-%s.%s", kind, accountID, time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func abortLedgerError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLedgerQuery), errors.Is(err, service.ErrLedgerExportTooLarge):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+	default:
+		abortWithError(c, http.StatusInternalServerError, msg, err)
+	}
+}
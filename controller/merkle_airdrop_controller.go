@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+	"strconv"
+)
+
+// CreateMerkleAirdrop 创建领取式空投，返回的 root 需写入领取合约
+func CreateMerkleAirdrop(c *gin.Context) {
+	var request dto.MerkleAirdropRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	res, err := service.CreateMerkleAirdrop(&request)
+	if err != nil {
+		var recipientErr *service.RecipientError
+		if errors.As(err, &recipientErr) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "recipient list invalid", "error": err.Error(), "issues": recipientErr.Issues})
+			return
+		}
+		abortWithError(c, http.StatusBadRequest, "create merkle airdrop failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+func GetMerkleAirdrop(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid airdrop id"})
+		return
+	}
+	res, err := service.GetMerkleAirdrop(id)
+	if err != nil {
+		abortMerkleError(c, "get merkle airdrop failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// GetMerkleProof 查询地址的领取证明
+func GetMerkleProof(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid airdrop id"})
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid address"})
+		return
+	}
+	res, err := service.GetMerkleProof(id, common.HexToAddress(address))
+	if err != nil {
+		abortMerkleError(c, "get merkle proof failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+func abortMerkleError(c *gin.Context, msg string, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": "not found", "error": err.Error()})
+		return
+	}
+	abortWithError(c, http.StatusInternalServerError, msg, err)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"staking-interaction/common/logger"
+	"staking-interaction/dto"
+	"staking-interaction/middleware"
+	"staking-interaction/service"
+	"strconv"
+)
+
+// requirePrincipal 取认证中间件写入的请求身份，缺失时返回 401
+func requirePrincipal(c *gin.Context) (*dto.Principal, bool) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "login required"})
+		return nil, false
+	}
+	return principal, true
+}
+
+// abortWithError 统一输出错误信息，合约预执行失败时附带错误码和解码后的 revert 原因；
+// revert、余额不足返回 400，节点调用失败（SIMULATION_FAILED）不是请求问题，返回 502
+func abortWithError(c *gin.Context, status int, msg string, err error) {
+	var contractErr *service.ContractError
+	if errors.As(err, &contractErr) {
+		status = http.StatusBadRequest
+		if contractErr.Code == service.ErrCodeSimulationFailed {
+			status = http.StatusBadGateway
+		}
+		c.AbortWithStatusJSON(status, gin.H{
+			"msg":    msg,
+			"code":   contractErr.Code,
+			"method": contractErr.Method,
+			"reason": contractErr.Reason,
+			"error":  err.Error(),
+		})
+		return
+	}
+	c.AbortWithStatusJSON(status, gin.H{"msg": msg, "error": err.Error()})
+}
+
+// abortSignatureError 验签失败时记录客户端 IP 的失败次数，达到上限后返回 429 并封禁
+func abortSignatureError(c *gin.Context, redis *redis.Client, msg string, err error) {
+	if !errors.Is(err, service.ErrInvalidSignature) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+		return
+	}
+	locked, recordErr := service.NewRateLimiter(redis).RecordFailure(c.Request.Context(), c.ClientIP())
+	if recordErr != nil {
+		logger.GetLogger().WithFields(logrus.Fields{
+			"module":     "rate_limit",
+			"action":     "record_failure",
+			"error_code": "RATE_LIMIT_UNAVAILABLE",
+			"detail":     recordErr.Error(),
+		}).Warn("Record signature failure failed")
+	}
+	if locked > 0 {
+		seconds := service.RetryAfterSeconds(locked)
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"msg": msg, "error": err.Error(), "retryAfter": seconds})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": msg, "error": err.Error()})
+}
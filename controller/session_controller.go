@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"net/http"
+	"staking-interaction/dto"
+	"staking-interaction/service"
+)
+
+// RefreshLogin 使用 refresh token 换取新的 access token，refresh token 同时轮换
+func RefreshLogin(c *gin.Context, redis *redis.Client) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	res, err := service.NewSessionService(redis).Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		abortSessionError(c, "refresh failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Logout 吊销当前会话
+func Logout(c *gin.Context, redis *redis.Client) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	if err := service.NewSessionService(redis).Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		abortSessionError(c, "logout failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success"})
+}
+
+// LogoutAll 吊销该钱包的全部会话
+func LogoutAll(c *gin.Context, redis *redis.Client) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
+		return
+	}
+	count, err := service.NewSessionService(redis).LogoutAll(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		abortSessionError(c, "logout all sessions failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": gin.H{"revoked": count}})
+}
+
+func abortSessionError(c *gin.Context, msg string, err error) {
+	if errors.Is(err, service.ErrRefreshTokenInvalid) || errors.Is(err, service.ErrRefreshTokenReused) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": msg, "error": err.Error()})
+		return
+	}
+	abortWithError(c, http.StatusInternalServerError, msg, err)
+}
@@ -9,16 +9,16 @@ import (
 )
 
 func LoginSolana(c *gin.Context, redis *redis.Client) {
-	var req dto.LoginBSCRequest
+	var req dto.LoginSolanaRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "request body invalid", "error": err.Error()})
 		return
 	}
 	solanaService := service.NewAuthSolanaService(redis)
-	token, err := solanaService.Login(req.Signature, req.WalletAddress, req.Nonce, req.Timestamp)
+	res, err := solanaService.Login(req.Message, req.Signature)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "login failed", "error": err.Error()})
+		abortSignatureError(c, redis, "login failed", err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, res)
 }
@@ -6,6 +6,7 @@ import (
 	"staking-interaction/adapter"
 	"staking-interaction/dto"
 	"staking-interaction/service"
+	"time"
 )
 
 func Stake(c *gin.Context) {
@@ -16,13 +17,14 @@ func Stake(c *gin.Context) {
 	}
 	client, err := adapter.NewInitEthClient()
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "client init failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
 		return
 	}
 	stakeService := service.NewStakeService(client)
 	response, err := stakeService.Stake(request.Amount, request.Period)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "stake transaction error", "error": err})
+		abortWithError(c, http.StatusInternalServerError, "stake transaction error", err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"msg": "Stake success!", "data": response})
@@ -37,13 +39,13 @@ func Withdraw(c *gin.Context) {
 	}
 	client, err := adapter.NewInitEthClient()
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "client init failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
 		return
 	}
 	stakeService := service.NewStakeService(client)
 	response, err := stakeService.Withdraw(&request.Index)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "withdrawn transaction error", "error": err})
+		abortWithError(c, http.StatusBadRequest, "withdrawn transaction error", err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"msg": "Withdrawn success!", "data": response})
@@ -54,3 +56,52 @@ func GetAllStakesByFromAddress(c *gin.Context) {
 	res := service.GetAllStakesByFromAddress(id)
 	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
 }
+
+// GetStakeStats 查询当前 TVL、活跃质押人数和各周期分布
+func GetStakeStats(c *gin.Context) {
+	client, err := adapter.NewSyncEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseSyncEthClient()
+	res, err := service.NewStakeStatsService(client).GetStats(c.Request.Context())
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get stake stats failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+// GetStakeDailyStats 查询 [from, to] 日期区间内每日质押与提取量，默认最近 30 天
+func GetStakeDailyStats(c *gin.Context) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "from must be in YYYY-MM-DD format"})
+			return
+		}
+		from = parsed
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "to must be in YYYY-MM-DD format"})
+			return
+		}
+		to = parsed
+	}
+	if to.Before(from) || to.Sub(from) > 366*24*time.Hour {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "date range must be within 366 days and from <= to"})
+		return
+	}
+
+	res, err := service.NewStakeStatsService(nil).GetDailyStats(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "get stake daily stats failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
@@ -21,13 +21,13 @@ func SendErc20(c *gin.Context) {
 	}
 	client, err := adapter.NewInitEthClient()
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "client init failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
 		return
 	}
 	transactionService := service.NewTransactionService(client)
 	res, err := transactionService.SendErc20(req.ToAddress, req.Amount)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "Transaction failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "Transaction failed", err)
 		return
 	}
 
@@ -46,13 +46,13 @@ func SendBNB(c *gin.Context) {
 	}
 	client, err := adapter.NewInitEthClient()
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "client init failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
 		return
 	}
 	transactionService := service.NewTransactionService(client)
 	res, err := transactionService.SendBNB(req.ToAddress, req.Amount)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "Transaction failed", "err": err})
+		abortWithError(c, http.StatusInternalServerError, "Transaction failed", err)
 		return
 	}
 
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/service"
+	"strconv"
+)
+
+// GetTxStatus 查询接口发起交易的状态，wait=true 时阻塞直到达到 confirmations 个确认或超时
+func GetTxStatus(c *gin.Context) {
+	hash := c.Param("hash")
+	if decoded, err := hexutil.Decode(hash); err != nil || len(decoded) != 32 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "invalid transaction hash"})
+		return
+	}
+
+	txConf := config.Get().BlockchainConfig.Transaction
+	confirmations := txConf.ConfirmBlocks
+	if v := c.Query("confirmations"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || n == 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "confirmations must be a positive integer"})
+			return
+		}
+		confirmations = n
+	}
+
+	client, err := adapter.NewSyncEthClient()
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, "client init failed", err)
+		return
+	}
+	defer client.CloseSyncEthClient()
+	trackerService := service.NewTxTrackerService(client)
+
+	if c.Query("wait") != "true" {
+		res, err := trackerService.GetTxStatus(c.Request.Context(), hash)
+		if err != nil {
+			abortTxStatusError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), txConf.Timeout)
+	defer cancel()
+	res, err := trackerService.WaitForConfirmations(ctx, hash, confirmations)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && res != nil {
+			c.JSON(http.StatusAccepted, gin.H{"msg": "wait for confirmations timeout", "data": res})
+			return
+		}
+		abortTxStatusError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"msg": "success", "data": res})
+}
+
+func abortTxStatusError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"msg": "transaction not found", "error": err.Error()})
+		return
+	}
+	abortWithError(c, http.StatusInternalServerError, "get transaction status failed", err)
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type AccountRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type AccountRoleInfo struct {
+	Role      string    `json:"role"`
+	GrantedBy string    `json:"grantedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AccountRolesResponse 账户在 account_role 表中的角色，不包含默认的 user 角色
+type AccountRolesResponse struct {
+	AccountID     int               `json:"accountId"`
+	WalletAddress string            `json:"walletAddress"`
+	Roles         []AccountRoleInfo `json:"roles"`
+}
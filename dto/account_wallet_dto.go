@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// LinkWalletRequest 关联钱包的签名消息，消息的 Request ID 必须是关联 nonce 接口返回的值
+type LinkWalletRequest struct {
+	Chain     string `json:"chain" binding:"required,oneof=bsc solana"`
+	Message   string `json:"message" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+type AccountWalletInfo struct {
+	Chain    string    `json:"chain"`
+	Address  string    `json:"address"`
+	LinkedAt time.Time `json:"linkedAt"`
+}
+
+// TokenBalance 金额均按代币精度格式化为十进制字符串
+type TokenBalance struct {
+	Token          string `json:"token"`
+	Decimals       uint8  `json:"decimals"`
+	Available      string `json:"available"`      // 账户余额扣除冻结金额
+	Frozen         string `json:"frozen"`         // 处理中的提现
+	PendingDeposit string `json:"pendingDeposit"` // 已上链、未达到确认区块数的充值
+}
+
+type AccountBalances struct {
+	AccountID int            `json:"accountId"`
+	Balances  []TokenBalance `json:"balances"`
+}
+
+// WalletOnChainBalance 账户关联的 BSC 钱包链上余额，查询失败时 Error 非空
+type WalletOnChainBalance struct {
+	Address  string            `json:"address"`
+	Balances map[string]string `json:"balances,omitempty"` // token -> 格式化后的余额
+	Error    string            `json:"error,omitempty"`
+}
+
+// AdminAccountBalances 客服排查用，账户余额和钱包链上余额对照
+type AdminAccountBalances struct {
+	AccountBalances
+	BlockNumber uint64                 `json:"blockNumber"`
+	OnChain     []WalletOnChainBalance `json:"onChain"`
+}
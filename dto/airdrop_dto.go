@@ -3,6 +3,7 @@ package dto
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
+	"time"
 )
 
 type Wallet struct {
@@ -11,9 +12,12 @@ type Wallet struct {
 }
 
 type AirdropRequest struct {
-	Count     int      `json:"count"`
-	BatchSize int      `json:"batchSize"`
-	Amount    *big.Int `json:"amount"`
+	Count          int      `json:"count"`
+	BatchSize      int      `json:"batchSize"`
+	Amount         *big.Int `json:"amount"`
+	DryRun         bool     `json:"dryRun"`
+	PersistWallets bool     `json:"persistWallets"` // 加密保存生成的钱包，供管理员导出
+	Campaign       string   `json:"campaign"`       // 保存钱包时记录的活动标记
 }
 
 type AirdropInfo struct {
@@ -23,6 +27,7 @@ type AirdropInfo struct {
 	FromAddress     common.Address   `json:"fromAddress"`
 	WalletAddress   []common.Address `json:"walletAddress"`
 	Error           string           `json:"error"`
+	Code            string           `json:"code,omitempty"`
 }
 
 type AirdropResponse struct {
@@ -32,4 +37,148 @@ type AirdropResponse struct {
 	FailBatches      int           `json:"failBatches"`
 	Data             []AirdropInfo `json:"data"`
 	Error            string        `json:"error"`
+	GenerationID     string        `json:"generationId,omitempty"` // 保存生成钱包时的批次 ID
+}
+
+// AirdropRecipient 空投收款人，amount 为最小单位的十进制字符串
+type AirdropRecipient struct {
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+}
+
+type AirdropCampaignRequest struct {
+	BatchSize  int                `json:"batchSize" form:"batchSize"`
+	DryRun     bool               `json:"dryRun" form:"dryRun"`
+	Recipients []AirdropRecipient `json:"recipients"`
+}
+
+// RecipientIssue 收款人校验问题，line 为收款人在列表中的序号(从 1 开始)
+type RecipientIssue struct {
+	Line    int    `json:"line"`
+	Address string `json:"address"`
+	Amount  string `json:"amount"`
+	Reason  string `json:"reason"`
+}
+
+type AirdropCampaignResponse struct {
+	CampaignID      uint64           `json:"campaignId"`
+	TotalRecipients int              `json:"totalRecipients"`
+	TotalBatches    int              `json:"totalBatches"`
+	TotalAmount     string           `json:"totalAmount"`
+	Balance         string           `json:"balance"`
+	Duplicates      []RecipientIssue `json:"duplicates"`
+}
+
+type AirdropBatchInfo struct {
+	BatchNum    int      `json:"batchNum"`
+	Recipients  []string `json:"recipients"`
+	Amounts     []string `json:"amounts"`
+	TotalAmount string   `json:"totalAmount"`
+	Nonce       uint64   `json:"nonce"`
+	TxHash      string   `json:"txHash"`
+	Status      string   `json:"status"`
+	Attempts    int      `json:"attempts"`
+	BlockNumber uint64   `json:"blockNumber"`
+	Error       string   `json:"error,omitempty"`
+	Code        string   `json:"code,omitempty"`
+	Delivery    string   `json:"delivery"` // 链上投递核对结果: unchecked/matched/mismatch
+}
+
+// AirdropBatchReleaseResult 待核对批次的核对结果，released 为 false 时原交易已上链，批次按回执更新状态
+type AirdropBatchReleaseResult struct {
+	BatchNum    int    `json:"batchNum"`
+	TxHash      string `json:"txHash"`
+	Released    bool   `json:"released"`
+	Status      string `json:"status"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+}
+
+type AirdropCampaignDetail struct {
+	ID              uint64             `json:"id"`
+	Kind            string             `json:"kind"`
+	ContractAddress string             `json:"contractAddress"`
+	FromAddress     string             `json:"fromAddress"`
+	TotalRecipients int                `json:"totalRecipients"`
+	TotalAmount     string             `json:"totalAmount"`
+	PaidAmount      string             `json:"paidAmount"`
+	Status          string             `json:"status"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
+	Batches         []AirdropBatchInfo `json:"batches"`
+}
+
+type AirdropBatchPlan struct {
+	BatchNum     int    `json:"batchNum"`
+	Recipients   int    `json:"recipients"`
+	Amount       string `json:"amount"`
+	EstimatedGas uint64 `json:"estimatedGas"`
+	GasFallback  bool   `json:"gasFallback,omitempty"` // 预执行失败，按成功批次的单人 gas 估算
+	Error        string `json:"error,omitempty"`
+	Code         string `json:"code,omitempty"`
+}
+
+// AirdropPlan 空投试运行结果，不发送任何交易；金额均为最小单位
+type AirdropPlan struct {
+	Kind                string             `json:"kind"`
+	TotalRecipients     int                `json:"totalRecipients"`
+	TotalAmount         string             `json:"totalAmount"`
+	BatchSize           int                `json:"batchSize"`
+	TotalBatches        int                `json:"totalBatches"`
+	Batches             []AirdropBatchPlan `json:"batches"`
+	Duplicates          []RecipientIssue   `json:"duplicates,omitempty"`
+	GasPrice            string             `json:"gasPrice"`
+	EstimatedGas        uint64             `json:"estimatedGas"`
+	EstimatedCost       string             `json:"estimatedCost"` // 预估 gas 费用(wei)，无法估算时为空
+	CostUnknown         bool               `json:"costUnknown"`   // 全部批次预执行失败，gas 费用未知，bnbSufficient 恒为 false
+	BnbBalance          string             `json:"bnbBalance"`
+	BnbRequired         string             `json:"bnbRequired"` // gas 费用，BNB 空投另加空投总额；费用未知时不含 gas
+	BnbSufficient       bool               `json:"bnbSufficient"`
+	TokenAddress        string             `json:"tokenAddress,omitempty"`
+	TokenBalance        string             `json:"tokenBalance,omitempty"`
+	TokenSufficient     bool               `json:"tokenSufficient"`
+	Allowance           string             `json:"allowance,omitempty"`
+	AllowanceSufficient bool               `json:"allowanceSufficient"`
+	FailedBatches       int                `json:"failedBatches"`
+	Executable          bool               `json:"executable"` // 余额、授权充足且全部批次预执行成功
+}
+
+type AirdropDeliveryInfo struct {
+	TxHash      string    `json:"txHash"`
+	BlockNumber uint64    `json:"blockNumber"`
+	Address     string    `json:"address"`
+	Amount      string    `json:"amount"`
+	Symbol      string    `json:"symbol"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type AirdropBatchDelivery struct {
+	BatchNum       int    `json:"batchNum"`
+	TxHash         string `json:"txHash"`
+	DeliveryStatus string `json:"deliveryStatus"` // unchecked/matched/mismatch
+	DeliveryError  string `json:"deliveryError,omitempty"`
+}
+
+type AirdropDeliveryResponse struct {
+	CampaignID          uint64                 `json:"campaignId"`
+	DeliveredRecipients int                    `json:"deliveredRecipients"`
+	DeliveredAmount     string                 `json:"deliveredAmount"`
+	MismatchedBatches   int                    `json:"mismatchedBatches"`
+	Batches             []AirdropBatchDelivery `json:"batches"`
+	Deliveries          []AirdropDeliveryInfo  `json:"deliveries"`
+}
+
+type WalletExportRequest struct {
+	Password     string `json:"password" binding:"required"` // 导出 keystore 的加密密码
+	GenerationID string `json:"generationId"`
+	Campaign     string `json:"campaign"`
+}
+
+// ExportedWallet 导出包 manifest.json 中的钱包信息
+type ExportedWallet struct {
+	Address      string    `json:"address"`
+	File         string    `json:"file"`
+	Source       string    `json:"source"`
+	Campaign     string    `json:"campaign"`
+	GenerationID string    `json:"generationId"`
+	CreatedAt    time.Time `json:"createdAt"`
 }
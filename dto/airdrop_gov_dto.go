@@ -0,0 +1,58 @@
+package dto
+
+import "time"
+
+type GovChangeRequest struct {
+	Action string `json:"action" binding:"required"` // set_gov/remove_gov/transfer_ownership/renounce_ownership
+	Target string `json:"target"`                    // renounce_ownership 时为空
+}
+
+type GovConfirmRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// GovChangePreview 治理操作确认前的预览，确认令牌在有效期内使用一次
+type GovChangePreview struct {
+	Action       string    `json:"action"`
+	Target       string    `json:"target,omitempty"`
+	Contract     string    `json:"contract"`
+	Owner        string    `json:"owner"`
+	HotWallet    string    `json:"hotWallet"`
+	TargetIsGov  bool      `json:"targetIsGov"`
+	Warning      string    `json:"warning,omitempty"`
+	ConfirmToken string    `json:"confirmToken,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt,omitempty"`
+}
+
+type GovChangeResult struct {
+	AuditID     uint64 `json:"auditId"`
+	Action      string `json:"action"`
+	Target      string `json:"target,omitempty"`
+	TxHash      string `json:"txHash"`
+	Status      string `json:"status"` // pending/success/failed
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+}
+
+type AirdropGovernorInfo struct {
+	Address     string    `json:"address"`
+	Active      bool      `json:"active"`
+	TxHash      string    `json:"txHash"`
+	BlockNumber uint64    `json:"blockNumber"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type OwnershipTransferInfo struct {
+	PreviousOwner string `json:"previousOwner"`
+	NewOwner      string `json:"newOwner"`
+	TxHash        string `json:"txHash"`
+	BlockNumber   uint64 `json:"blockNumber"`
+}
+
+type AirdropGovernance struct {
+	Contract       string                  `json:"contract"`
+	Owner          string                  `json:"owner"`
+	HotWallet      string                  `json:"hotWallet"`
+	HotWalletIsGov bool                    `json:"hotWalletIsGov"`
+	Governors      []AirdropGovernorInfo   `json:"governors"`
+	OwnerHistory   []OwnershipTransferInfo `json:"ownerHistory"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+type ApiKeyCreateRequest struct {
+	ClientName string   `json:"clientName" binding:"required"`
+	Scopes     []string `json:"scopes" binding:"required,min=1"`
+	AllowedIPs []string `json:"allowedIps"` // IP 或 CIDR，为空不限制
+}
+
+type ApiKeyInfo struct {
+	KeyID             string     `json:"keyId"`
+	ClientName        string     `json:"clientName"`
+	Scopes            []string   `json:"scopes"`
+	AllowedIPs        []string   `json:"allowedIps"`
+	Status            string     `json:"status"`
+	CreatedBy         string     `json:"createdBy"`
+	RotatedAt         *time.Time `json:"rotatedAt,omitempty"`
+	PrevSecretExpires *time.Time `json:"prevSecretExpires,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// ApiKeySecretResponse 创建或轮换后返回的密钥，只在此时返回一次
+type ApiKeySecretResponse struct {
+	KeyID             string     `json:"keyId"`
+	Secret            string     `json:"secret"`
+	PrevSecretExpires *time.Time `json:"prevSecretExpires,omitempty"`
+}
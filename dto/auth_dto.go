@@ -2,9 +2,45 @@ package dto
 
 import (
 	"github.com/golang-jwt/jwt/v4"
+	"time"
 )
 
 type CustomClaims struct {
 	WalletAddress string `json:"wallet_address"`
+	SessionID     string `json:"sid"`
+	Chain         string `json:"chain"`
 	jwt.RegisteredClaims
 }
+
+// Principal 认证中间件写入 gin 上下文的请求身份
+type Principal struct {
+	WalletAddress string   `json:"walletAddress"`
+	Chain         string   `json:"chain"`
+	AccountID     int      `json:"accountId"`
+	SessionID     string   `json:"sessionId"`
+	Roles         []string `json:"roles"`
+	ApiKeyID      string   `json:"apiKeyId,omitempty"` // 使用 API Key 调用时非空，此时按 Scopes 判断权限
+	Scopes        []string `json:"scopes,omitempty"`
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginResponse 登录或刷新后返回的 access token 与轮换后的 refresh token
+type LoginResponse struct {
+	Token            string    `json:"token"`
+	RefreshToken     string    `json:"refreshToken"`
+	SessionID        string    `json:"sessionId"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+// JSONWebKey 公钥的 JWK 表示（RFC 7517），EC 密钥使用 X/Y，Ed25519 密钥只有 X
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+// JWKSResponse /.well-known/jwks.json 响应，包含当前密钥和宽限期内的旧密钥
+type JWKSResponse struct {
+	Keys []JSONWebKey `json:"keys"`
+}
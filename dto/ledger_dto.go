@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// LedgerQuery 账单和充值记录的查询参数，format 为 csv 或 json 时导出全部符合条件的记录
+type LedgerQuery struct {
+	Token     string `form:"token"` // bnb, mtk
+	Type      string `form:"type"`  // recharge, withdrawal，只对账单生效，查询充值记录时传入返回 400
+	From      string `form:"from"`  // RFC3339 或 2006-01-02，包含
+	To        string `form:"to"`    // RFC3339 不包含；2006-01-02 包含当天
+	MinAmount string `form:"minAmount"`
+	MaxAmount string `form:"maxAmount"`
+	Cursor    string `form:"cursor"` // 上一页返回的 nextCursor
+	Limit     int    `form:"limit"`
+	Format    string `form:"format"`
+}
+
+type BillRecord struct {
+	ID          uint64    `json:"id"`
+	Token       string    `json:"token"`
+	Type        string    `json:"type"`
+	Amount      string    `json:"amount"`
+	Fee         string    `json:"fee"`
+	PreBalance  string    `json:"preBalance"`
+	NextBalance string    `json:"nextBalance"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type DepositRecord struct {
+	ID          uint64    `json:"id"`
+	Token       string    `json:"token"`
+	Hash        string    `json:"hash"`
+	Amount      string    `json:"amount"`
+	FromAddress string    `json:"fromAddress"`
+	ToAddress   string    `json:"toAddress"`
+	BlockNumber string    `json:"blockNumber"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// BillPage nextCursor 为空表示没有更多记录
+type BillPage struct {
+	Items      []BillRecord `json:"items"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+type DepositPage struct {
+	Items      []DepositRecord `json:"items"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
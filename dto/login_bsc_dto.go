@@ -1,18 +1,32 @@
 package dto
 
+import "time"
+
 type LoginBSCeResponse struct {
 	Token string `json:"token"`
 }
+
+// LoginBSCRequest EIP-4361 登录消息及其 personal_sign 签名
 type LoginBSCRequest struct {
-	Signature     string `json:"signature"`
-	Nonce         string `json:"nonce"`
-	Timestamp     int64  `json:"timestamp"`
-	WalletAddress string `json:"address"`
+	Message   string `json:"message" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
 }
 
+// LoginSolanaRequest SIWS 登录消息及其 base58 编码的 ed25519 签名
 type LoginSolanaRequest struct {
-	Signature     string `json:"signature"`
-	Nonce         string `json:"nonce"`
-	Timestamp     int64  `json:"timestamp"`
-	WalletAddress string `json:"address"`
+	Message   string `json:"message" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// LoginNonceResponse 登录 nonce 及消息绑定参数，传入 address 时附带完整的待签名消息
+type LoginNonceResponse struct {
+	Nonce     string    `json:"nonce"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Domain    string    `json:"domain"`
+	URI       string    `json:"uri"`
+	ChainID   string    `json:"chainId"`
+	Statement string    `json:"statement,omitempty"`
+	RequestID string    `json:"requestId,omitempty"` // 关联钱包时必须写入消息的 Request ID
+	Message   string    `json:"message,omitempty"`
 }
@@ -0,0 +1,31 @@
+package dto
+
+type MerkleAirdropRequest struct {
+	Name            string             `json:"name"`
+	ContractAddress string             `json:"contractAddress"` // 为空时使用配置的 claim_address
+	Recipients      []AirdropRecipient `json:"recipients"`
+}
+
+type MerkleAirdropResponse struct {
+	ID              uint64           `json:"id"`
+	Name            string           `json:"name"`
+	ContractAddress string           `json:"contractAddress"`
+	Root            string           `json:"root"`
+	TotalRecipients int              `json:"totalRecipients"`
+	TotalAmount     string           `json:"totalAmount"`
+	ClaimedCount    int              `json:"claimedCount"`
+	ClaimedAmount   string           `json:"claimedAmount"`
+	Duplicates      []RecipientIssue `json:"duplicates,omitempty"`
+}
+
+type MerkleProofResponse struct {
+	AirdropID       uint64   `json:"airdropId"`
+	ContractAddress string   `json:"contractAddress"`
+	Root            string   `json:"root"`
+	Index           uint64   `json:"index"`
+	Address         string   `json:"address"`
+	Amount          string   `json:"amount"`
+	Proof           []string `json:"proof"`
+	Claimed         bool     `json:"claimed"`
+	ClaimTxHash     string   `json:"claimTxHash,omitempty"`
+}
@@ -4,10 +4,12 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
+	"staking-interaction/model"
 )
 
 type StakeResponse struct {
 	Hash            string         `json:"hash"`
+	ApproveHash     string         `json:"approveHash,omitempty"`
 	ContractAddress string         `json:"contractAddress"`
 	FromAddress     common.Address `json:"fromAddress"`
 	Method          string         `json:"method"`
@@ -27,3 +29,26 @@ type StakeEventListener struct {
 	ContractAddress  common.Address
 	ContractABI      abi.ABI
 }
+
+type StakePeriodStat struct {
+	Period       uint8  `json:"period"`
+	ActiveStakes int    `json:"activeStakes"`
+	Amount       string `json:"amount"`
+	Apy          string `json:"apy"`
+}
+
+type StakeStatsResponse struct {
+	Tvl           string                  `json:"tvl"`
+	ActiveStakers int                     `json:"activeStakers"`
+	ActiveStakes  int                     `json:"activeStakes"`
+	Periods       []StakePeriodStat       `json:"periods"`
+	OnChain       *model.StakeTvlSnapshot `json:"onChain"`
+}
+
+type StakeDailyStat struct {
+	Date           string `json:"date"`
+	StakeCount     int    `json:"stakeCount"`
+	StakeAmount    string `json:"stakeAmount"`
+	WithdrawCount  int    `json:"withdrawCount"`
+	WithdrawAmount string `json:"withdrawAmount"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type TxStatusResponse struct {
+	Hash          string    `json:"hash"`
+	Purpose       string    `json:"purpose"`
+	FromAddress   string    `json:"fromAddress"`
+	ToAddress     string    `json:"toAddress"`
+	Value         string    `json:"value"`
+	Nonce         uint64    `json:"nonce"`
+	Status        string    `json:"status"` // pending/success/failed/dropped
+	BlockNumber   uint64    `json:"blockNumber"`
+	Confirmations uint64    `json:"confirmations"`
+	GasUsed       uint64    `json:"gasUsed"`
+	GasPrice      string    `json:"gasPrice"`
+	Fee           string    `json:"fee"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
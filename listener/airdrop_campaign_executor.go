@@ -0,0 +1,90 @@
+package listener
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	redisClient "staking-interaction/common/redis"
+	"staking-interaction/service"
+	"sync/atomic"
+	"time"
+)
+
+// AirdropCampaignExecutor 后台推进空投活动，进程重启后继续处理未完成的批次
+type AirdropCampaignExecutor struct {
+	airdropService *service.AirdropService
+	lockManager    *redisClient.LockManager
+	isRunning      int32
+	config         config.AirdropConfig
+	log            *logrus.Logger
+}
+
+func NewAirdropCampaignExecutor(airdropService *service.AirdropService, lockManager *redisClient.LockManager, config config.AirdropConfig, log *logrus.Logger) *AirdropCampaignExecutor {
+	return &AirdropCampaignExecutor{
+		airdropService: airdropService,
+		lockManager:    lockManager,
+		config:         config,
+		log:            log,
+	}
+}
+
+func (e *AirdropCampaignExecutor) Start() {
+	e.log.WithFields(logrus.Fields{
+		"module": "airdrop_campaign_executor",
+		"action": "start",
+	}).Info("AirdropCampaignExecutor started")
+	atomic.StoreInt32(&e.isRunning, 1)
+
+	for atomic.LoadInt32(&e.isRunning) == 1 {
+		e.execute()
+		time.Sleep(e.config.ExecuteInterval)
+	}
+}
+
+func (e *AirdropCampaignExecutor) Stop() {
+	atomic.StoreInt32(&e.isRunning, 0)
+	e.log.WithFields(logrus.Fields{
+		"module": "airdrop_campaign_executor",
+		"action": "stop",
+	}).Info("AirdropCampaignExecutor stopped")
+}
+
+func (e *AirdropCampaignExecutor) execute() {
+	// 执行时间需小于锁过期时间，避免锁过期后其他实例并发签名
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	defer cancel()
+
+	lock := e.lockManager.GetAirdropExecutorLock()
+	locked, err := lock.TryLock(ctx)
+	if err != nil {
+		e.log.WithFields(logrus.Fields{
+			"module":     "airdrop_campaign_executor",
+			"action":     "lock",
+			"error_code": "LOCK_FAIL",
+			"detail":     err.Error(),
+		}).Error("Acquire airdrop executor lock failed")
+		return
+	}
+	if !locked {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			e.log.WithFields(logrus.Fields{
+				"module":     "airdrop_campaign_executor",
+				"action":     "unlock",
+				"error_code": "UNLOCK_FAIL",
+				"detail":     err.Error(),
+			}).Warn("Release airdrop executor lock failed")
+		}
+	}()
+
+	if err := e.airdropService.ExecuteCampaigns(ctx, e.config); err != nil {
+		e.log.WithFields(logrus.Fields{
+			"module":     "airdrop_campaign_executor",
+			"action":     "execute",
+			"error_code": "EXECUTE_CAMPAIGN_FAIL",
+			"detail":     err.Error(),
+		}).Error("Execute airdrop campaigns failed")
+	}
+}
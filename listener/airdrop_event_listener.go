@@ -0,0 +1,165 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/contracts/airdrop"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"staking-interaction/service"
+	"sync/atomic"
+	"time"
+)
+
+// AirdropEventListener 扫描空投合约的 Airdropped 事件，保存投递记录并与活动批次核对，同时记录 OwnershipTransferred 事件
+type AirdropEventListener struct {
+	client       *adapter.InitClient
+	blockManager *repository.BlockSyncManager
+	isRunning    int32
+	config       config.BlockchainConfig
+	log          *logrus.Logger
+}
+
+func NewAirdropEventListener(clientInfo *adapter.InitClient, config config.BlockchainConfig, log *logrus.Logger) *AirdropEventListener {
+	return &AirdropEventListener{
+		client:       clientInfo,
+		blockManager: repository.NewBlockSyncManager("last_airdrop_event_block.txt"),
+		config:       config,
+		log:          log,
+	}
+}
+
+func (l *AirdropEventListener) Start() {
+	l.log.WithFields(logrus.Fields{
+		"module": "airdrop_event_listener",
+		"action": "start",
+	}).Info("AirdropEventListener started")
+	atomic.StoreInt32(&l.isRunning, 1)
+
+	filterer, err := airdrop.NewContractsFilterer(common.HexToAddress(l.config.Contracts.Airdrop), l.client.Client)
+	if err != nil {
+		l.log.WithFields(logrus.Fields{
+			"module":     "airdrop_event_listener",
+			"action":     "new_filterer",
+			"error_code": "NEW_FILTERER_FAIL",
+			"detail":     err.Error(),
+		}).Error("Create airdrop filterer failed")
+		return
+	}
+
+	for atomic.LoadInt32(&l.isRunning) == 1 {
+		if err := l.scan(filterer); err != nil {
+			l.log.WithFields(logrus.Fields{
+				"module":     "airdrop_event_listener",
+				"action":     "scan",
+				"error_code": "SCAN_AIRDROPPED_FAIL",
+				"detail":     err.Error(),
+			}).Error("Scan Airdropped events failed")
+		}
+		time.Sleep(l.config.Sync.SyncInterval)
+	}
+}
+
+func (l *AirdropEventListener) Stop() {
+	atomic.StoreInt32(&l.isRunning, 0)
+	l.log.WithFields(logrus.Fields{
+		"module": "airdrop_event_listener",
+		"action": "stop",
+	}).Info("AirdropEventListener stopped")
+}
+
+// scan 扫描下一段区块区间内的 Airdropped 事件
+func (l *AirdropEventListener) scan(filterer *airdrop.ContractsFilterer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	from, to, ok, err := nextLogRange(ctx, l.client, l.blockManager, l.config.Sync)
+	if err != nil || !ok {
+		return err
+	}
+
+	iter, err := filterer.FilterAirdropped(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil)
+	if err != nil {
+		return fmt.Errorf("filter Airdropped logs %d-%d failed: %w", from, to, err)
+	}
+	defer iter.Close()
+
+	var events []*airdrop.ContractsAirdropped
+	for iter.Next() {
+		if iter.Event.Raw.Removed {
+			continue
+		}
+		events = append(events, iter.Event)
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("iterate Airdropped logs %d-%d failed: %w", from, to, err)
+	}
+
+	if len(events) > 0 {
+		mismatched, err := service.RecordAirdropDeliveries(events)
+		if err != nil {
+			return err
+		}
+		l.log.WithFields(logrus.Fields{
+			"module":     "airdrop_event_listener",
+			"action":     "record_deliveries",
+			"from_block": from,
+			"to_block":   to,
+			"events":     len(events),
+		}).Info("Airdropped events recorded")
+		for _, txHash := range mismatched {
+			l.log.WithFields(logrus.Fields{
+				"module":     "airdrop_event_listener",
+				"action":     "reconcile",
+				"error_code": "AIRDROP_DELIVERY_MISMATCH",
+				"tx_hash":    txHash,
+			}).Warn("Airdrop delivery does not match campaign batch")
+		}
+	}
+	if err := l.scanOwnershipTransfers(ctx, filterer, from, to); err != nil {
+		return err
+	}
+	return l.blockManager.SaveSyncedBlock(to)
+}
+
+// scanOwnershipTransfers 记录空投合约的 owner 变更
+func (l *AirdropEventListener) scanOwnershipTransfers(ctx context.Context, filterer *airdrop.ContractsFilterer, from uint64, to uint64) error {
+	iter, err := filterer.FilterOwnershipTransferred(&bind.FilterOpts{Start: from, End: &to, Context: ctx}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("filter OwnershipTransferred logs %d-%d failed: %w", from, to, err)
+	}
+	defer iter.Close()
+
+	var transfers []model.AirdropOwnershipTransfer
+	for iter.Next() {
+		event := iter.Event
+		if event.Raw.Removed {
+			continue
+		}
+		transfers = append(transfers, model.AirdropOwnershipTransfer{
+			Contract:      event.Raw.Address.Hex(),
+			TxHash:        event.Raw.TxHash.Hex(),
+			LogIndex:      event.Raw.Index,
+			BlockNumber:   event.Raw.BlockNumber,
+			PreviousOwner: event.PreviousOwner.Hex(),
+			NewOwner:      event.NewOwner.Hex(),
+			CreatedAt:     time.Now(),
+		})
+		l.log.WithFields(logrus.Fields{
+			"module":         "airdrop_event_listener",
+			"action":         "ownership_transferred",
+			"previous_owner": event.PreviousOwner.Hex(),
+			"new_owner":      event.NewOwner.Hex(),
+			"tx_hash":        event.Raw.TxHash.Hex(),
+		}).Warn("Airdrop contract ownership transferred")
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("iterate OwnershipTransferred logs %d-%d failed: %w", from, to, err)
+	}
+	return repository.AddAirdropOwnershipTransfers(transfers)
+}
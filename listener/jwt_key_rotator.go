@@ -0,0 +1,98 @@
+package listener
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	redisClient "staking-interaction/common/redis"
+	"staking-interaction/service"
+	"sync/atomic"
+	"time"
+)
+
+// JWTKeyRotator 定时轮换 access token 签名密钥并清理宽限期已过的旧密钥
+type JWTKeyRotator struct {
+	keyManager  *service.JWTKeyManager
+	lockManager *redisClient.LockManager
+	isRunning   int32
+	config      config.SigningKeyConfig
+	log         *logrus.Logger
+}
+
+func NewJWTKeyRotator(keyManager *service.JWTKeyManager, lockManager *redisClient.LockManager, config config.SigningKeyConfig, log *logrus.Logger) *JWTKeyRotator {
+	return &JWTKeyRotator{
+		keyManager:  keyManager,
+		lockManager: lockManager,
+		config:      config,
+		log:         log,
+	}
+}
+
+func (r *JWTKeyRotator) Start() {
+	r.log.WithFields(logrus.Fields{
+		"module": "jwt_key_rotator",
+		"action": "start",
+	}).Info("JWTKeyRotator started")
+	atomic.StoreInt32(&r.isRunning, 1)
+
+	for atomic.LoadInt32(&r.isRunning) == 1 {
+		r.rotate()
+		time.Sleep(r.config.CheckInterval)
+	}
+}
+
+func (r *JWTKeyRotator) Stop() {
+	atomic.StoreInt32(&r.isRunning, 0)
+	r.log.WithFields(logrus.Fields{
+		"module": "jwt_key_rotator",
+		"action": "stop",
+	}).Info("JWTKeyRotator stopped")
+}
+
+func (r *JWTKeyRotator) rotate() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	lock := r.lockManager.GetJWTKeyRotationLock()
+	locked, err := lock.TryLock(ctx)
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"module":     "jwt_key_rotator",
+			"action":     "lock",
+			"error_code": "LOCK_FAIL",
+			"detail":     err.Error(),
+		}).Error("Acquire jwt key rotation lock failed")
+		return
+	}
+	if !locked {
+		return
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			r.log.WithFields(logrus.Fields{
+				"module":     "jwt_key_rotator",
+				"action":     "unlock",
+				"error_code": "UNLOCK_FAIL",
+				"detail":     err.Error(),
+			}).Warn("Release jwt key rotation lock failed")
+		}
+	}()
+
+	rotated, err := r.keyManager.RotateDue(time.Now())
+	if err != nil {
+		r.log.WithFields(logrus.Fields{
+			"module":     "jwt_key_rotator",
+			"action":     "rotate",
+			"error_code": "ROTATE_KEY_FAIL",
+			"detail":     err.Error(),
+		}).Error("Rotate jwt signing keys failed")
+		return
+	}
+	if len(rotated) > 0 {
+		r.log.WithFields(logrus.Fields{
+			"module": "jwt_key_rotator",
+			"action": "rotate",
+			"kids":   rotated,
+		}).Info("Published next jwt signing keys")
+	}
+}
@@ -0,0 +1,39 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/repository"
+)
+
+// nextLogRange 计算下一段待扫描的区块区间 [上次位置+1, 最新块-BlockBuffer]，每次最多 BatchSize 个区块
+// 首次启动从当前安全高度开始，历史事件需手工设置进度文件回扫
+func nextLogRange(ctx context.Context, client *adapter.InitClient, blockManager *repository.BlockSyncManager, conf config.SyncConfig) (uint64, uint64, bool, error) {
+	head, err := client.Client.BlockNumber(ctx)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("get block number failed: %w", err)
+	}
+	if head < conf.BlockBuffer {
+		return 0, 0, false, nil
+	}
+	safeBlock := head - conf.BlockBuffer
+
+	lastBlock, err := blockManager.GetLastSyncedBlock()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if lastBlock == 0 {
+		return 0, 0, false, blockManager.SaveSyncedBlock(safeBlock)
+	}
+	from := lastBlock + 1
+	if from > safeBlock {
+		return 0, 0, false, nil
+	}
+	to := from + uint64(conf.BatchSize) - 1
+	if to > safeBlock {
+		to = safeBlock
+	}
+	return from, to, true, nil
+}
@@ -0,0 +1,170 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"math/big"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/repository"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 领取合约 Claimed(uint256 index, address account, uint256 amount) 事件
+const merkleClaimedEventABIJson = `[
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": false, "name": "index", "type": "uint256"},
+			{"indexed": false, "name": "account", "type": "address"},
+			{"indexed": false, "name": "amount", "type": "uint256"}
+		],
+		"name": "Claimed",
+		"type": "event"
+	}
+]`
+
+// MerkleClaimListener 扫描领取合约的 Claimed 事件，标记叶子已领取
+type MerkleClaimListener struct {
+	client       *adapter.InitClient
+	blockManager *repository.BlockSyncManager
+	isRunning    int32
+	config       config.BlockchainConfig
+	log          *logrus.Logger
+}
+
+func NewMerkleClaimListener(clientInfo *adapter.InitClient, config config.BlockchainConfig, log *logrus.Logger) *MerkleClaimListener {
+	return &MerkleClaimListener{
+		client:       clientInfo,
+		blockManager: repository.NewBlockSyncManager("last_claim_block.txt"),
+		config:       config,
+		log:          log,
+	}
+}
+
+func (l *MerkleClaimListener) Start() {
+	l.log.WithFields(logrus.Fields{
+		"module": "merkle_claim_listener",
+		"action": "start",
+	}).Info("MerkleClaimListener started")
+	atomic.StoreInt32(&l.isRunning, 1)
+
+	claimABI, err := abi.JSON(strings.NewReader(merkleClaimedEventABIJson))
+	if err != nil {
+		l.log.WithFields(logrus.Fields{
+			"module":     "merkle_claim_listener",
+			"action":     "parse_abi",
+			"error_code": "PARSE_ABI_FAIL",
+			"detail":     err.Error(),
+		}).Error("Parse Claimed event abi failed")
+		return
+	}
+
+	for atomic.LoadInt32(&l.isRunning) == 1 {
+		if err := l.scan(claimABI); err != nil {
+			l.log.WithFields(logrus.Fields{
+				"module":     "merkle_claim_listener",
+				"action":     "scan",
+				"error_code": "SCAN_CLAIMED_FAIL",
+				"detail":     err.Error(),
+			}).Error("Scan Claimed events failed")
+		}
+		time.Sleep(l.config.Sync.SyncInterval)
+	}
+}
+
+func (l *MerkleClaimListener) Stop() {
+	atomic.StoreInt32(&l.isRunning, 0)
+	l.log.WithFields(logrus.Fields{
+		"module": "merkle_claim_listener",
+		"action": "stop",
+	}).Info("MerkleClaimListener stopped")
+}
+
+// scan 扫描下一段区块区间内的 Claimed 事件
+func (l *MerkleClaimListener) scan(claimABI abi.ABI) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	contracts, err := l.claimContracts()
+	if err != nil {
+		return err
+	}
+	if len(contracts) == 0 {
+		return nil
+	}
+
+	from, to, ok, err := nextLogRange(ctx, l.client, l.blockManager, l.config.Sync)
+	if err != nil || !ok {
+		return err
+	}
+
+	logs, err := l.client.Client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: contracts,
+		Topics:    [][]common.Hash{{claimABI.Events["Claimed"].ID}},
+	})
+	if err != nil {
+		return fmt.Errorf("filter Claimed logs %d-%d failed: %w", from, to, err)
+	}
+	for _, vLog := range logs {
+		if vLog.Removed {
+			continue
+		}
+		var event struct {
+			Index   *big.Int
+			Account common.Address
+			Amount  *big.Int
+		}
+		if err := claimABI.UnpackIntoInterface(&event, "Claimed", vLog.Data); err != nil {
+			return fmt.Errorf("unpack Claimed log %s failed: %w", vLog.TxHash.Hex(), err)
+		}
+		marked, err := repository.MarkMerkleLeafClaimed(vLog.Address.Hex(), event.Index.Uint64(), event.Account.Hex(), event.Amount, vLog.TxHash.Hex(), vLog.BlockNumber)
+		if err != nil {
+			return err
+		}
+		entry := l.log.WithFields(logrus.Fields{
+			"module":   "merkle_claim_listener",
+			"action":   "mark_claimed",
+			"contract": vLog.Address.Hex(),
+			"index":    event.Index.String(),
+			"account":  event.Account.Hex(),
+			"amount":   event.Amount.String(),
+			"tx_hash":  vLog.TxHash.Hex(),
+		})
+		if marked {
+			entry.Info("Merkle airdrop leaf claimed")
+		} else {
+			entry.Warn("Claimed event does not match any unclaimed leaf")
+		}
+	}
+	return l.blockManager.SaveSyncedBlock(to)
+}
+
+func (l *MerkleClaimListener) claimContracts() ([]common.Address, error) {
+	contracts, err := repository.GetMerkleClaimContracts()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[common.Address]struct{})
+	var addresses []common.Address
+	for _, contract := range append(contracts, l.config.Contracts.Claim) {
+		if !common.IsHexAddress(contract) {
+			continue
+		}
+		addr := common.HexToAddress(contract)
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		addresses = append(addresses, addr)
+	}
+	return addresses, nil
+}
@@ -0,0 +1,119 @@
+package listener
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"staking-interaction/service"
+	"sync/atomic"
+	"time"
+)
+
+// outboundTxPageSize 每次从数据库读取的 pending 交易数
+const outboundTxPageSize = 100
+
+// OutboundTxTracker 轮询接口发起交易的回执，更新状态、区块、gas 和手续费
+type OutboundTxTracker struct {
+	tracker   *service.TxTrackerService
+	isRunning int32
+	config    config.TransactionConfig
+	log       *logrus.Logger
+}
+
+func NewOutboundTxTracker(tracker *service.TxTrackerService, config config.TransactionConfig, log *logrus.Logger) *OutboundTxTracker {
+	return &OutboundTxTracker{
+		tracker: tracker,
+		config:  config,
+		log:     log,
+	}
+}
+
+func (t *OutboundTxTracker) Start() {
+	t.log.WithFields(logrus.Fields{
+		"module": "outbound_tx_tracker",
+		"action": "start",
+	}).Info("OutboundTxTracker started")
+	atomic.StoreInt32(&t.isRunning, 1)
+
+	for atomic.LoadInt32(&t.isRunning) == 1 {
+		t.trackPendingTxs()
+		time.Sleep(t.config.TrackInterval)
+	}
+}
+
+func (t *OutboundTxTracker) Stop() {
+	atomic.StoreInt32(&t.isRunning, 0)
+	t.log.WithFields(logrus.Fields{
+		"module": "outbound_tx_tracker",
+		"action": "stop",
+	}).Info("OutboundTxTracker stopped")
+}
+
+// trackPendingTxs 按 id 游标分页遍历全部 pending 交易，避免表头的交易卡住后面的记录
+func (t *OutboundTxTracker) trackPendingTxs() {
+	var afterID uint64
+	for atomic.LoadInt32(&t.isRunning) == 1 {
+		pendingTxs, err := repository.GetOutboundTxByStatus(config.OutboundTxStatusPending, afterID, outboundTxPageSize)
+		if err != nil {
+			t.log.WithFields(logrus.Fields{
+				"module":     "outbound_tx_tracker",
+				"action":     "get_pending_txs",
+				"error_code": "GET_PENDING_TX_FAIL",
+				"detail":     err.Error(),
+			}).Error("Get pending outbound transactions failed")
+			return
+		}
+		for i := range pendingTxs {
+			if atomic.LoadInt32(&t.isRunning) != 1 {
+				return
+			}
+			t.refreshReceipt(&pendingTxs[i])
+		}
+		if len(pendingTxs) < outboundTxPageSize {
+			return
+		}
+		afterID = pendingTxs[len(pendingTxs)-1].ID
+	}
+}
+
+func (t *OutboundTxTracker) refreshReceipt(pending *model.OutboundTx) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.Timeout)
+	defer cancel()
+	record, err := t.tracker.RefreshReceipt(ctx, pending)
+	if err != nil {
+		t.log.WithFields(logrus.Fields{
+			"module":     "outbound_tx_tracker",
+			"action":     "refresh_receipt",
+			"tx_hash":    pending.Hash,
+			"error_code": "REFRESH_RECEIPT_FAIL",
+			"detail":     err.Error(),
+		}).Error("Refresh outbound transaction receipt failed")
+		return
+	}
+	switch record.Status {
+	case config.OutboundTxStatusPending:
+	case config.OutboundTxStatusDropped:
+		t.log.WithFields(logrus.Fields{
+			"module":     "outbound_tx_tracker",
+			"action":     "refresh_receipt",
+			"tx_hash":    record.Hash,
+			"purpose":    record.Purpose,
+			"nonce":      record.Nonce,
+			"error_code": "OUTBOUND_TX_DROPPED",
+			"detail":     "nonce passed without receipt or max tracking age exceeded",
+		}).Warn("Outbound transaction dropped")
+	default:
+		t.log.WithFields(logrus.Fields{
+			"module":       "outbound_tx_tracker",
+			"action":       "refresh_receipt",
+			"tx_hash":      record.Hash,
+			"purpose":      record.Purpose,
+			"status":       record.Status,
+			"block_number": record.BlockNumber,
+			"gas_used":     record.GasUsed,
+			"fee":          record.Fee,
+		}).Info("Outbound transaction mined")
+	}
+}
@@ -0,0 +1,99 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"math/big"
+	"staking-interaction/common/config"
+)
+
+// refreshPendingDeposits 扫描 [fromBlock, toBlock] 中尚未达到确认区块数的充值，汇总后替换 Redis 中的快照。
+// 扫描失败时保留旧快照，由过期时间兜底
+func (s *SyncBlock) refreshPendingDeposits(chainID *big.Int, fromBlock uint64, toBlock uint64) {
+	if s.pendingDeposits == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Sync.SyncInterval)
+	defer cancel()
+
+	deposits := make(map[int]map[int]*big.Int)
+	for number := fromBlock; number <= toBlock; number++ {
+		if err := s.scanPendingBlock(ctx, chainID, number, deposits); err != nil {
+			s.log.WithFields(logrus.Fields{
+				"module":       "sync_block",
+				"action":       "scan_pending_deposits",
+				"block_number": number,
+				"error_code":   "SCAN_PENDING_FAIL",
+				"detail":       err.Error(),
+			}).Warn("Failed to scan pending deposits")
+			return
+		}
+	}
+	if err := s.pendingDeposits.Replace(ctx, deposits); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"module":     "sync_block",
+			"action":     "save_pending_deposits",
+			"error_code": "SAVE_PENDING_FAIL",
+			"detail":     err.Error(),
+		}).Warn("Failed to save pending deposits")
+	}
+}
+
+// scanPendingBlock 与 processTransaction 使用相同的过滤条件识别充值，只汇总金额不入账
+func (s *SyncBlock) scanPendingBlock(ctx context.Context, chainID *big.Int, number uint64, deposits map[int]map[int]*big.Int) error {
+	block, err := s.client.Client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return fmt.Errorf("get block failed: %w", err)
+	}
+	signer := types.NewLondonSigner(chainID)
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || !s.isToAddrValid(*tx.To()) {
+			continue
+		}
+		receipt, err := s.client.Client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return fmt.Errorf("get transaction receipt: %w", err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		fromAddr, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		isPlatformAccount, accountID := isFromAddrValid(fromAddr)
+		if !isPlatformAccount {
+			continue
+		}
+
+		isContract, err := s.isContractTx(ctx, *tx.To())
+		if err != nil {
+			return fmt.Errorf("check contract tx: %w", err)
+		}
+		var tokenType int
+		var amount *big.Int
+		switch {
+		case isContract:
+			event, err := s.parseERC20TxByReceipt(receipt)
+			if err != nil {
+				continue
+			}
+			tokenType, amount = config.TokenTypeMTK, event.Value
+		case len(tx.Data()) == 0:
+			tokenType, amount = config.TokenTypeBNB, tx.Value()
+		default:
+			continue
+		}
+
+		if deposits[*accountID] == nil {
+			deposits[*accountID] = make(map[int]*big.Int)
+		}
+		if deposits[*accountID][tokenType] == nil {
+			deposits[*accountID][tokenType] = new(big.Int)
+		}
+		deposits[*accountID][tokenType].Add(deposits[*accountID][tokenType], amount)
+	}
+	return nil
+}
@@ -128,12 +128,13 @@ func handleLog(l types.Log, listener dto.StakeEventListener) {
 			IndexNum:        event.StakeIndex.String(),
 			Hash:            l.TxHash.Hex(),
 			ContractAddress: listener.ContractAddress.Hex(),
-			FromAddress:     l.Address.Hex(),
+			FromAddress:     event.User.Hex(),
 			Method:          config.StakedEventName,
 			Amount:          event.Amount.String(),
+			Period:          event.Period,
 			BlockNumber:     int64(l.BlockNumber),
 			Status:          0,
-			Timestamp:       time.Now(),
+			Timestamp:       time.Unix(event.Timestamp.Int64(), 0),
 		}
 
 		storeStakeInfo(stake)
@@ -164,7 +165,7 @@ func handleLog(l types.Log, listener dto.StakeEventListener) {
 			IndexNum:        event.StakeIndex.String(),
 			Hash:            l.TxHash.Hex(),
 			ContractAddress: listener.ContractAddress.Hex(),
-			FromAddress:     l.Address.Hex(),
+			FromAddress:     event.User.Hex(),
 			Method:          config.WithdrawnEventName,
 			Amount:          event.TotalAmount.String(),
 			BlockNumber:     int64(l.BlockNumber),
@@ -0,0 +1,72 @@
+package listener
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	"staking-interaction/service"
+	"sync/atomic"
+	"time"
+)
+
+// StakeMaturityScheduler 定期扫描质押到期情况并发送通知
+type StakeMaturityScheduler struct {
+	maturityService *service.StakeMaturityService
+	isRunning       int32
+	config          config.MaturityConfig
+	log             *logrus.Logger
+}
+
+func NewStakeMaturityScheduler(maturityService *service.StakeMaturityService, config config.MaturityConfig, log *logrus.Logger) *StakeMaturityScheduler {
+	return &StakeMaturityScheduler{
+		maturityService: maturityService,
+		config:          config,
+		log:             log,
+	}
+}
+
+func (m *StakeMaturityScheduler) Start() {
+	m.log.WithFields(logrus.Fields{
+		"module": "stake_maturity_scheduler",
+		"action": "start",
+	}).Info("StakeMaturityScheduler started")
+	atomic.StoreInt32(&m.isRunning, 1)
+
+	for atomic.LoadInt32(&m.isRunning) == 1 {
+		m.scan()
+		time.Sleep(m.config.ScanInterval)
+	}
+}
+
+func (m *StakeMaturityScheduler) Stop() {
+	atomic.StoreInt32(&m.isRunning, 0)
+	m.log.WithFields(logrus.Fields{
+		"module": "stake_maturity_scheduler",
+		"action": "stop",
+	}).Info("StakeMaturityScheduler stopped")
+}
+
+func (m *StakeMaturityScheduler) scan() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.ScanInterval)
+	defer cancel()
+	sent, failed, err := m.maturityService.ScanMaturity(ctx, time.Now())
+	if err != nil {
+		m.log.WithFields(logrus.Fields{
+			"module":     "stake_maturity_scheduler",
+			"action":     "scan",
+			"sent":       sent,
+			"failed":     failed,
+			"error_code": "SCAN_MATURITY_FAIL",
+			"detail":     err.Error(),
+		}).Error("Scan stake maturity failed")
+		return
+	}
+	if sent > 0 || failed > 0 {
+		m.log.WithFields(logrus.Fields{
+			"module": "stake_maturity_scheduler",
+			"action": "scan",
+			"sent":   sent,
+			"failed": failed,
+		}).Info("Stake maturity notifications dispatched")
+	}
+}
@@ -0,0 +1,78 @@
+package listener
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	"staking-interaction/service"
+	"sync/atomic"
+	"time"
+)
+
+// StakeTvlChecker 定期对比索引 TVL 与链上质押合约余额并记录快照
+type StakeTvlChecker struct {
+	statsService *service.StakeStatsService
+	isRunning    int32
+	config       config.StakeConfig
+	log          *logrus.Logger
+}
+
+func NewStakeTvlChecker(statsService *service.StakeStatsService, config config.StakeConfig, log *logrus.Logger) *StakeTvlChecker {
+	return &StakeTvlChecker{
+		statsService: statsService,
+		config:       config,
+		log:          log,
+	}
+}
+
+func (t *StakeTvlChecker) Start() {
+	t.log.WithFields(logrus.Fields{
+		"module": "stake_tvl_checker",
+		"action": "start",
+	}).Info("StakeTvlChecker started")
+	atomic.StoreInt32(&t.isRunning, 1)
+
+	ticker := time.NewTicker(t.config.TvlCheckInterval)
+	defer ticker.Stop()
+	for atomic.LoadInt32(&t.isRunning) == 1 {
+		t.checkTvl()
+		<-ticker.C
+	}
+}
+
+func (t *StakeTvlChecker) Stop() {
+	atomic.StoreInt32(&t.isRunning, 0)
+	t.log.WithFields(logrus.Fields{
+		"module": "stake_tvl_checker",
+		"action": "stop",
+	}).Info("StakeTvlChecker stopped")
+}
+
+func (t *StakeTvlChecker) checkTvl() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	snapshot, err := t.statsService.CheckOnChainTvl(ctx)
+	if err != nil {
+		t.log.WithFields(logrus.Fields{
+			"module":     "stake_tvl_checker",
+			"action":     "check_tvl",
+			"error_code": "CHECK_TVL_FAIL",
+			"detail":     err.Error(),
+		}).Error("Check on-chain TVL failed")
+		return
+	}
+	entry := t.log.WithFields(logrus.Fields{
+		"module":       "stake_tvl_checker",
+		"action":       "check_tvl",
+		"block_number": snapshot.BlockNumber,
+		"indexed_tvl":  snapshot.IndexedTvl,
+		"onchain_tvl":  snapshot.OnchainTvl,
+		"diff":         snapshot.Diff,
+	})
+	// 合约余额包含奖励资金，低于索引 TVL 说明索引重复或漏记提取
+	if len(snapshot.Diff) > 0 && snapshot.Diff[0] == '-' {
+		entry.Warn("On-chain TVL lower than indexed TVL")
+		return
+	}
+	entry.Info("On-chain TVL checked")
+}
@@ -16,6 +16,7 @@ import (
 	"staking-interaction/dto"
 	"staking-interaction/model"
 	"staking-interaction/repository"
+	"staking-interaction/service"
 	"staking-interaction/utils"
 	"strconv"
 	"strings"
@@ -34,19 +35,22 @@ type SyncBlock struct {
 	blockManager  *repository.BlockSyncManager
 	workerWg      sync.WaitGroup // 等待所有交易处理Goroutine退出
 	lockManager   *redis.LockManager
-	config        config.BlockchainConfig
-	log           *logrus.Logger
+	// 等待新区块时扫描未确认区块，记录待确认的充值
+	pendingDeposits *service.PendingDepositStore
+	config          config.BlockchainConfig
+	log             *logrus.Logger
 }
 
 // NewSyncBlockInfo 创建新的区块同步服务
-func NewSyncBlockInfo(clientInfo *adapter.InitClient, config config.BlockchainConfig, lockManager *redis.LockManager, log *logrus.Logger) *SyncBlock {
+func NewSyncBlockInfo(clientInfo *adapter.InitClient, config config.BlockchainConfig, lockManager *redis.LockManager, pendingDeposits *service.PendingDepositStore, log *logrus.Logger) *SyncBlock {
 	return &SyncBlock{
-		client:       clientInfo,
-		blockManager: repository.NewBlockSyncManager("last_synced_block.txt"),
-		workerPool:   make(chan struct{}, config.Sync.Workers), // 限制并发处理数量
-		config:       config,
-		lockManager:  lockManager,
-		log:          log,
+		client:          clientInfo,
+		blockManager:    repository.NewBlockSyncManager("last_synced_block.txt"),
+		workerPool:      make(chan struct{}, config.Sync.Workers), // 限制并发处理数量
+		config:          config,
+		lockManager:     lockManager,
+		pendingDeposits: pendingDeposits,
+		log:             log,
 	}
 }
 
@@ -160,6 +164,7 @@ func (s *SyncBlock) syncLoop() {
 				"current_block": currentBlock,
 				"done_block":    doneBlock,
 			}).Info("Waiting for new blocks")
+			s.refreshPendingDeposits(chainID, doneBlock, currentBlock)
 			cancel()
 			time.Sleep(10 * time.Second)
 			continue
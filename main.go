@@ -11,7 +11,11 @@ import (
 	"staking-interaction/adapter"
 	"staking-interaction/common/config"
 	"staking-interaction/common/logger"
+	redisClient "staking-interaction/common/redis"
+	"staking-interaction/listener"
+	"staking-interaction/repository"
 	srouter "staking-interaction/router"
+	"staking-interaction/service"
 	"syscall"
 	"time"
 )
@@ -37,6 +41,20 @@ func main() {
 		"action": "init_db",
 	}).Info("MySQL database connected.")
 
+	// 为 account_wallet 出现前注册的账户补写钱包关联，GetAccount 只查关联表
+	backfilled, err := repository.BackfillAccountWallets()
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"action":     "backfill_account_wallets",
+			"error_code": "BACKFILL_WALLET_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Backfill account wallets failed")
+	}
+	log.WithFields(logrus.Fields{
+		"action": "backfill_account_wallets",
+		"rows":   backfilled,
+	}).Info("Account wallets backfilled")
+
 	defer func() {
 		err := adapter.CloseConn()
 		if err != nil {
@@ -64,6 +82,16 @@ func main() {
 	}
 	defer redis.Close()
 
+	// 加载 JWT 签名密钥，密钥目录为空时在轮换锁内生成初始密钥
+	keyManager, err := service.InitJWTKeyManager(redisClient.NewLockManager(redis))
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"action":     "init_jwt_keys",
+			"error_code": "JWT_KEY_LOAD_FAIL",
+			"detail":     err.Error(),
+		}).Fatal("Load jwt signing keys failed")
+	}
+
 	router := srouter.InitRouter(redis)
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", conf.AppConfig.Port),
@@ -91,8 +119,58 @@ func main() {
 	defer clientInfo.CloseEthClient()
 	//listener.ListenToEvents()
 
+	// 轮询接口发起交易的回执
+	txTracker := listener.NewOutboundTxTracker(service.NewTxTrackerService(clientInfo), conf.BlockchainConfig.Transaction, logger.GetLogger())
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(logrus.Fields{
+					"action": "outbound_tx_tracker_panic",
+					"detail": r,
+				}).Error("OutboundTxTracker panic")
+			}
+		}()
+		txTracker.Start()
+	}()
+	defer txTracker.Stop()
+
+	// 后台执行空投活动
+	campaignExecutor := listener.NewAirdropCampaignExecutor(
+		service.NewAirdropService(clientInfo, logger.GetLogger()),
+		redisClient.NewLockManager(redis),
+		conf.BlockchainConfig.Airdrop,
+		logger.GetLogger(),
+	)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(logrus.Fields{
+					"action": "airdrop_campaign_executor_panic",
+					"detail": r,
+				}).Error("AirdropCampaignExecutor panic")
+			}
+		}()
+		campaignExecutor.Start()
+	}()
+	defer campaignExecutor.Stop()
+
+	// 定时轮换 JWT 签名密钥
+	keyRotator := listener.NewJWTKeyRotator(keyManager, redisClient.NewLockManager(redis), conf.AuthConfig.SigningKeys, logger.GetLogger())
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(logrus.Fields{
+					"action": "jwt_key_rotator_panic",
+					"detail": r,
+				}).Error("JWTKeyRotator panic")
+			}
+		}()
+		keyRotator.Start()
+	}()
+	defer keyRotator.Stop()
+
 	// 创建系统信号接收器
-	signalChan := make(chan os.Signal)
+	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	<-signalChan
 	log.Println("shutdown server...")
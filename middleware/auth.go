@@ -1,15 +1,18 @@
 package middleware
 
 import (
-	"context"
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v4"
+	"io"
 	"net/http"
 	"staking-interaction/common/config"
 	"staking-interaction/dto"
+	"staking-interaction/service"
+	"staking-interaction/utils"
 	"strings"
 )
 
@@ -26,30 +29,137 @@ func NewAuthMiddleware(client *redis.Client) *Auth {
 	}
 }
 
+// principalKey gin 上下文中保存请求身份的键
+const principalKey = "principal"
+
+// maxSignedBodySize API Key 请求参与签名的请求体上限
+const maxSignedBodySize = 10 << 20
+
+// 签名算法与登录链一一对应：BSC 登录签发 ES256，Solana 登录签发 EdDSA
+var chainByAlg = map[string]string{
+	service.JWTAlgES256: config.LoginChainBSC,
+	service.JWTAlgEdDSA: config.LoginChainSolana,
+}
+
+// AuthMiddleware 校验 BSC/Solana 登录签发的 access token 及其会话，通过后在上下文中写入 principal
 func (a *Auth) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, err := a.extractToken(c.Request)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "extract token failed", "error": err.Error()})
+		if _, ok := a.authenticate(c); !ok {
 			return
 		}
-		//校验 JWT
-		address, err := a.VerifyJWTToken(token)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "invalid or expired token", "error": err.Error()})
+		c.Next()
+	}
+}
+
+// RequirePermission 要求请求身份的角色拥有指定权限，前面未经过 AuthMiddleware 时先完成认证
+func (a *Auth) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			if principal, ok = a.authenticate(c); !ok {
+				return
+			}
+		}
+		if !service.PrincipalHasPermission(principal, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"msg": "permission denied", "permission": permission})
 			return
 		}
+		c.Next()
+	}
+}
 
-		//从 Redis 查找 Token 是否存在
-		key := fmt.Sprintf("token_bsc:%s", address)
-		exists, err := a.redis.Exists(context.Background(), key).Result()
-		if err != nil || exists == 0 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "token is not existed", "error": err.Error()})
-			return
+// authenticateApiKey 读取请求体参与验签后放回，供后续 handler 绑定；签名错误计入客户端 IP 的失败次数
+func (a *Auth) authenticateApiKey(c *gin.Context) (*dto.Principal, bool) {
+	limiter := service.NewRateLimiter(a.redis)
+	if locked, err := limiter.LockedFor(c.Request.Context(), c.ClientIP()); err == nil && locked > 0 {
+		abortTooManyRequests(c, "too many failed signature verifications", locked)
+		return nil, false
+	}
+
+	// 多读一个字节判断是否超限，截断后的请求体不能参与验签
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodySize+1))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "read request body failed", "error": err.Error()})
+		return nil, false
+	}
+	if len(body) > maxSignedBodySize {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"msg": "request body is too large"})
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	principal, err := service.NewApiKeyService(a.redis).Authenticate(c.Request.Context(), &service.SignedApiRequest{
+		KeyID:     c.GetHeader(utils.ApiKeyHeader),
+		Timestamp: c.GetHeader(utils.ApiTimestampHeader),
+		Nonce:     c.GetHeader(utils.ApiNonceHeader),
+		Signature: c.GetHeader(utils.ApiSignatureHeader),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Body:      body,
+		ClientIP:  c.ClientIP(),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrApiKeySignature) {
+			if locked, _ := limiter.RecordFailure(c.Request.Context(), c.ClientIP()); locked > 0 {
+				abortTooManyRequests(c, "too many failed signature verifications", locked)
+				return nil, false
+			}
+		}
+		switch {
+		case errors.Is(err, service.ErrApiKeyIPDenied):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"msg": "api key authentication failed", "error": err.Error()})
+		case errors.Is(err, service.ErrApiKeyInvalid),
+			errors.Is(err, service.ErrApiKeySignature),
+			errors.Is(err, service.ErrApiKeyTimestamp),
+			errors.Is(err, service.ErrApiKeyNonce):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "api key authentication failed", "error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "api key authentication failed", "error": err.Error()})
 		}
+		return nil, false
+	}
+	c.Set(principalKey, principal)
+	return principal, true
+}
 
-		c.JSON(http.StatusOK, gin.H{"token": token})
+// GetPrincipal 返回认证中间件写入的请求身份，未经过认证中间件时 ok 为 false
+func GetPrincipal(c *gin.Context) (*dto.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	if !exists {
+		return nil, false
 	}
+	principal, ok := value.(*dto.Principal)
+	return principal, ok && principal != nil
+}
+
+// authenticate 带 X-Api-Key 的请求按 API Key 签名校验，否则校验 token 和会话，成功后写入 principal，失败时已中止请求
+func (a *Auth) authenticate(c *gin.Context) (*dto.Principal, bool) {
+	if c.GetHeader(utils.ApiKeyHeader) != "" {
+		return a.authenticateApiKey(c)
+	}
+	token, err := a.extractToken(c.Request)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "extract token failed", "error": err.Error()})
+		return nil, false
+	}
+	claims, err := a.VerifyJWTTokens(token)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "invalid or expired token", "error": err.Error()})
+		return nil, false
+	}
+
+	//从 Redis 校验会话是否有效
+	principal, err := service.NewSessionService(a.redis).Principal(c.Request.Context(), claims)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionInactive) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "session is not active"})
+			return nil, false
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "check session failed", "error": err.Error()})
+		return nil, false
+	}
+	c.Set(principalKey, principal)
+	return principal, true
 }
 
 func (a *Auth) extractToken(req *http.Request) (string, error) {
@@ -64,57 +174,39 @@ func (a *Auth) extractToken(req *http.Request) (string, error) {
 	return strings.TrimPrefix(authHeader, a.config.AuthConfig.Prefix), nil
 }
 
-func (a *Auth) VerifyJWTToken(tokenStr string) (string, error) {
-	claims := &dto.CustomClaims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return a.config.AuthConfig.EcdsaPublicKey, nil
-	})
+// VerifyJWTTokens 校验 ES256/EdDSA 签名的 access token，并按签名算法确定登录链
+func (a *Auth) VerifyJWTTokens(tokenStr string) (*dto.CustomClaims, error) {
+	keyManager, err := service.GetJWTKeyManager()
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return "", fmt.Errorf("token is expired, please login, ExpiresAt:%v", claims.ExpiresAt)
-		}
-		return "", fmt.Errorf("token parse failed: %v", err)
+		return nil, err
 	}
-	if !token.Valid {
-		return "", fmt.Errorf("token is not valid")
-	}
-
-	return claims.WalletAddress, nil
-}
-
-func (a *Auth) VerifyJWTTokens(tokenStr string) (string, error) {
 	claims := &dto.CustomClaims{}
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
 		switch token.Method.(type) {
-		case *jwt.SigningMethodECDSA:
-			if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return a.config.AuthConfig.EcdsaPublicKey, nil
-		case *jwt.SigningMethodEd25519:
-			if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return *a.config.AuthConfig.Ed25519PublicKey, nil
+		case *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+			// 按 kid 查找公钥，Keyfunc 同时校验算法与密钥类型一致
+			return keyManager.Keyfunc(token)
 		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-
 	})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			return "", fmt.Errorf("token is expired, please login, ExpiresAt:%v", claims.ExpiresAt)
+			return nil, fmt.Errorf("token is expired, please login, ExpiresAt:%v", claims.ExpiresAt)
 		}
-		return "", fmt.Errorf("token parse failed: %v", err)
+		return nil, fmt.Errorf("token parse failed: %v", err)
 	}
 	if !token.Valid {
-		return "", fmt.Errorf("token is not valid")
+		return nil, fmt.Errorf("token is not valid")
 	}
 
-	return claims.WalletAddress, nil
+	chain := chainByAlg[token.Method.Alg()]
+	if claims.Chain == "" {
+		claims.Chain = chain
+	} else if claims.Chain != chain {
+		return nil, fmt.Errorf("token chain %s does not match signing method %s", claims.Chain, token.Method.Alg())
+	}
+	return claims, nil
 }
 
 //func (a *AuthBSCService) Verify(token string, accountId int) bool {
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"staking-interaction/common/logger"
+	"staking-interaction/service"
+	"strconv"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	maxIdempotentBodySize    = 10 << 20
+	idempotencySaveTimeout   = 5 * time.Second
+)
+
+type Idempotency struct {
+	store *service.IdempotencyStore
+	log   *logrus.Logger
+}
+
+func NewIdempotencyMiddleware(client *redis.Client) *Idempotency {
+	return &Idempotency{
+		store: service.NewIdempotencyStore(client),
+		log:   logger.GetLogger(),
+	}
+}
+
+// responseRecorder 写出响应的同时保留一份响应体
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Handle 写请求带 Idempotency-Key 时只执行一次，重试返回首次响应；同一 key 用于不同请求时返回 409。
+// key 按请求身份隔离，需放在认证中间件之后；不带该请求头或非写请求直接放行
+func (i *Idempotency) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isStateChanging(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "idempotency key is too long"})
+			return
+		}
+
+		// 多读一个字节判断是否超限，避免截断后的请求体生成相同指纹
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "read request body failed", "error": err.Error()})
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"msg": "request body is too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(c)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+		stored, err := i.store.Begin(c.Request.Context(), scope, key, fingerprint)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"msg": "idempotency key reused", "error": err.Error()})
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyInFlight):
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"msg": "idempotency key in progress", "error": err.Error()})
+			return
+		case err != nil:
+			// 无法保证只执行一次时拒绝请求，避免重复转账
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"msg": "idempotency store unavailable", "error": err.Error()})
+			return
+		case stored != nil:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		finished := false
+		defer func() {
+			// handler panic 时释放 key，允许重试
+			if !finished {
+				_ = i.store.Release(context.Background(), scope, key)
+			}
+		}()
+
+		c.Next()
+		finished = true
+
+		// 请求被取消时仍保存响应，使用独立的 context；保存失败时占用记录到期前重试仍返回 409
+		ctx, cancel := context.WithTimeout(context.Background(), idempotencySaveTimeout)
+		defer cancel()
+		response := &service.IdempotentResponse{
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := i.store.Complete(ctx, scope, key, fingerprint, response); err != nil {
+			i.log.WithFields(logrus.Fields{
+				"module":     "idempotency",
+				"action":     "complete",
+				"error_code": "IDEMPOTENCY_SAVE_FAILED",
+				"scope":      scope,
+				"key":        key,
+				"detail":     err.Error(),
+			}).Error("Save idempotent response failed")
+		}
+	}
+}
+
+func isStateChanging(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyScope 不同请求身份的 key 互不影响，未认证请求按客户端 IP 隔离
+func idempotencyScope(c *gin.Context) string {
+	principal, ok := GetPrincipal(c)
+	switch {
+	case !ok:
+		return "ip:" + c.ClientIP()
+	case principal.ApiKeyID != "":
+		return "api_key:" + principal.ApiKeyID
+	case principal.AccountID > 0:
+		return "account:" + strconv.Itoa(principal.AccountID)
+	}
+	return "wallet:" + principal.Chain + ":" + principal.WalletAddress
+}
+
+// requestFingerprint 请求方法、路径和请求体的摘要
+func requestFingerprint(method string, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + "\n" + uri + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"staking-interaction/common/logger"
+	"staking-interaction/service"
+	"strconv"
+	"time"
+)
+
+type RateLimit struct {
+	limiter *service.RateLimiter
+	log     *logrus.Logger
+}
+
+func NewRateLimitMiddleware(client *redis.Client) *RateLimit {
+	return &RateLimit{
+		limiter: service.NewRateLimiter(client),
+		log:     logger.GetLogger(),
+	}
+}
+
+// Limit 按策略限流：客户端 IP 始终计数，已认证请求再按钱包或 API Key 计数；封禁中的 IP 直接拒绝。
+// 需要按身份限流的路由应放在认证中间件之后。Redis 不可用时放行，避免限流拖垮接口
+func (r *RateLimit) Limit(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		ip := c.ClientIP()
+
+		locked, err := r.limiter.LockedFor(ctx, ip)
+		if err != nil {
+			r.logError(policy, ip, err)
+		} else if locked > 0 {
+			abortTooManyRequests(c, "too many failed signature verifications", locked)
+			return
+		}
+
+		subjects := []string{"ip:" + ip}
+		if principal, ok := GetPrincipal(c); ok {
+			if principal.ApiKeyID != "" {
+				subjects = append(subjects, "api_key:"+principal.ApiKeyID)
+			} else {
+				subjects = append(subjects, "wallet:"+principal.Chain+":"+principal.WalletAddress)
+			}
+		}
+		for _, subject := range subjects {
+			wait, err := r.limiter.Allow(ctx, policy, subject)
+			if err != nil {
+				r.logError(policy, subject, err)
+				continue
+			}
+			if wait > 0 {
+				abortTooManyRequests(c, "rate limit exceeded", wait)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func (r *RateLimit) logError(policy string, subject string, err error) {
+	r.log.WithFields(logrus.Fields{
+		"module":     "rate_limit",
+		"action":     policy,
+		"error_code": "RATE_LIMIT_UNAVAILABLE",
+		"subject":    subject,
+		"detail":     err.Error(),
+	}).Warn("Rate limiter unavailable, request allowed")
+}
+
+// abortTooManyRequests 返回 429 和 Retry-After 响应头
+func abortTooManyRequests(c *gin.Context, msg string, wait time.Duration) {
+	seconds := service.RetryAfterSeconds(wait)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"msg": msg, "retryAfter": seconds})
+}
@@ -7,7 +7,16 @@ type Account struct {
 	AccountID     int    `gorm:"column:account_id;type:int;primary_key;AUTO_INCREMENT" json:"account_id"`
 	AccountName   string `gorm:"column:account_name;type:varchar(128);not null" json:"account_name"`
 	Email         string `gorm:"column:email;type:varchar(64);unique_index:email_index" json:"email"`
-	WalletAddress string `gorm:"column:wallet_address;type:varchar(64)" json:"wallet_address"`
+	WalletAddress string `gorm:"column:wallet_address;type:varchar(64)" json:"wallet_address"` // 注册时的钱包，账户关联的全部钱包见 account_wallet
+}
+
+// AccountWallet 对应 account_wallet 表，一个账户可关联多条链的多个钱包
+type AccountWallet struct {
+	ID        uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	AccountID int       `gorm:"column:account_id;type:int;not null;index:idx_account_id" json:"account_id"`
+	Chain     string    `gorm:"column:chain;type:varchar(16);not null;uniqueIndex:uk_chain_address" json:"chain"` // bsc/solana
+	Address   string    `gorm:"column:address;type:varchar(64);not null;uniqueIndex:uk_chain_address" json:"address"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
 }
 
 // AccountAsset 对应 account_asset 表
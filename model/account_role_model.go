@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// AccountRole 对应 account_role 表，账户额外拥有的角色
+type AccountRole struct {
+	ID        uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	AccountID int       `gorm:"column:account_id;type:int;not null;uniqueIndex:uk_account_role" json:"account_id"`
+	Role      string    `gorm:"column:role;type:varchar(16);not null;uniqueIndex:uk_account_role" json:"role"`
+	GrantedBy string    `gorm:"column:granted_by;type:varchar(64)" json:"granted_by"` // 授权人钱包地址
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// AdminAuditLog 管理操作审计日志
+type AdminAuditLog struct {
+	ID        uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Actor     string    `gorm:"column:actor;type:varchar(64);not null;index:idx_actor" json:"actor"` // 操作人钱包地址，CLI 操作为 cli:<系统用户>
+	Action    string    `gorm:"column:action;type:varchar(32);not null;index:idx_action" json:"action"`
+	Target    string    `gorm:"column:target;type:varchar(64)" json:"target"`
+	Params    string    `gorm:"column:params;type:text" json:"params"` // JSON，操作前的链上状态等
+	TxHash    string    `gorm:"column:tx_hash;type:varchar(66)" json:"tx_hash"`
+	Status    int8      `gorm:"column:status;type:tinyint;index:idx_status" json:"status"` // 1.PENDING 2.SUCCESS 3.FAILED
+	Error     string    `gorm:"column:error;type:varchar(512)" json:"error"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// AirdropCampaign 空投活动
+type AirdropCampaign struct {
+	ID              uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Kind            string    `gorm:"column:kind;type:varchar(10);not null" json:"kind"` // erc20/bnb
+	ContractAddress string    `gorm:"column:contract_address;type:varchar(64);not null" json:"contract_address"`
+	FromAddress     string    `gorm:"column:from_address;type:varchar(64);not null" json:"from_address"`
+	TotalRecipients int       `gorm:"column:total_recipients;type:int" json:"total_recipients"`
+	TotalAmount     string    `gorm:"column:total_amount;type:varchar(100);default:'0'" json:"total_amount"`
+	BatchSize       int       `gorm:"column:batch_size;type:int" json:"batch_size"`
+	TotalBatches    int       `gorm:"column:total_batches;type:int" json:"total_batches"`
+	Status          int8      `gorm:"column:status;type:tinyint;index:idx_status" json:"status"` // 1.PENDING 2.RUNNING 3.COMPLETED 4.FAILED
+	CreatedAt       time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
+
+// AirdropBatch 空投批次，签名后先落库再广播，重启后按 raw_tx 原样重播，保证同一批次只占用一个 nonce
+type AirdropBatch struct {
+	ID          uint64 `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	CampaignID  uint64 `gorm:"column:campaign_id;type:bigint unsigned;not null;uniqueIndex:uk_campaign_batch" json:"campaign_id"`
+	BatchNum    int    `gorm:"column:batch_num;type:int;not null;uniqueIndex:uk_campaign_batch" json:"batch_num"`
+	Recipients  string `gorm:"column:recipients;type:mediumtext" json:"recipients"` // JSON 地址数组
+	Amounts     string `gorm:"column:amounts;type:mediumtext" json:"amounts"`       // JSON 金额数组，与 recipients 一一对应
+	TotalAmount string `gorm:"column:total_amount;type:varchar(100);default:'0'" json:"total_amount"`
+	Nonce       uint64 `gorm:"column:nonce;type:bigint unsigned;default:0" json:"nonce"` // 仅 SIGNED 之后有效
+	TxHash      string `gorm:"column:tx_hash;type:varchar(100);index:idx_tx_hash" json:"tx_hash"`
+	RawTx       string `gorm:"column:raw_tx;type:text" json:"-"`
+	Status      int8   `gorm:"column:status;type:tinyint;index:idx_status" json:"status"` // 1.PENDING 2.SIGNED 3.SENT 4.SUCCESS 5.FAILED 6.REVIEW
+	Attempts    int    `gorm:"column:attempts;type:int;default:0" json:"attempts"`
+	MissedPolls int    `gorm:"column:missed_polls;type:int;default:0" json:"missed_polls"` // nonce 已被占用但查不到交易的连续轮询次数
+	BlockNumber uint64 `gorm:"column:block_number;type:bigint unsigned;default:0" json:"block_number"`
+	Error       string `gorm:"column:error;type:varchar(512)" json:"error"`
+	ErrorCode   string `gorm:"column:error_code;type:varchar(32)" json:"error_code"`
+	// 由 Airdropped 事件索引回填，与执行器分开更新
+	DeliveryStatus int8      `gorm:"column:delivery_status;type:tinyint;default:0" json:"delivery_status"` // 0.UNCHECKED 1.MATCHED 2.MISMATCH
+	DeliveryError  string    `gorm:"column:delivery_error;type:varchar(512)" json:"delivery_error"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
+
+// AirdropDelivery 空投合约 Airdropped 事件，每个收款人一条
+type AirdropDelivery struct {
+	ID           uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	TxHash       string    `gorm:"column:tx_hash;type:varchar(100);not null;uniqueIndex:uk_tx_log" json:"tx_hash"`
+	LogIndex     uint      `gorm:"column:log_index;type:int unsigned;not null;uniqueIndex:uk_tx_log" json:"log_index"`
+	BlockNumber  uint64    `gorm:"column:block_number;type:bigint unsigned" json:"block_number"`
+	CampaignID   uint64    `gorm:"column:campaign_id;type:bigint unsigned;default:0;index:idx_campaign" json:"campaign_id"` // 0 表示不属于任何活动
+	BatchID      uint64    `gorm:"column:batch_id;type:bigint unsigned;default:0" json:"batch_id"`
+	Address      string    `gorm:"column:address;type:varchar(64);not null;index:idx_address" json:"address"`
+	Amount       string    `gorm:"column:amount;type:varchar(100);not null" json:"amount"`
+	ProcessIndex string    `gorm:"column:process_index;type:varchar(100)" json:"process_index"`
+	Symbol       string    `gorm:"column:symbol;type:varchar(32)" json:"symbol"`
+	Timestamp    time.Time `gorm:"column:timestamp;type:datetime" json:"timestamp"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+}
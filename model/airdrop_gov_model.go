@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// AirdropGovernor 空投合约治理地址，合约没有 gov 变更事件，由治理操作的回执和 isGov 查询维护
+type AirdropGovernor struct {
+	ID          uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Contract    string    `gorm:"column:contract;type:varchar(64);not null;uniqueIndex:uk_contract_address" json:"contract"`
+	Address     string    `gorm:"column:address;type:varchar(64);not null;uniqueIndex:uk_contract_address" json:"address"`
+	Active      bool      `gorm:"column:active;type:tinyint(1);default:0" json:"active"`
+	TxHash      string    `gorm:"column:tx_hash;type:varchar(66)" json:"tx_hash"` // 最近一次变更交易
+	BlockNumber uint64    `gorm:"column:block_number;type:bigint unsigned" json:"block_number"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
+
+// AirdropOwnershipTransfer 空投合约 OwnershipTransferred 事件
+type AirdropOwnershipTransfer struct {
+	ID            uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Contract      string    `gorm:"column:contract;type:varchar(64);not null" json:"contract"`
+	TxHash        string    `gorm:"column:tx_hash;type:varchar(66);not null;uniqueIndex:uk_tx_log" json:"tx_hash"`
+	LogIndex      uint      `gorm:"column:log_index;type:int unsigned;not null;uniqueIndex:uk_tx_log" json:"log_index"`
+	BlockNumber   uint64    `gorm:"column:block_number;type:bigint unsigned;index:idx_block_number" json:"block_number"`
+	PreviousOwner string    `gorm:"column:previous_owner;type:varchar(64)" json:"previous_owner"`
+	NewOwner      string    `gorm:"column:new_owner;type:varchar(64)" json:"new_owner"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ApiKey 服务间调用的 API Key，密钥使用 auth.api_key.master_key 加密保存
+type ApiKey struct {
+	ID                uint64     `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	KeyID             string     `gorm:"column:key_id;type:varchar(32);not null;uniqueIndex:uk_key_id" json:"key_id"`
+	ClientName        string     `gorm:"column:client_name;type:varchar(64);not null" json:"client_name"`
+	SecretEnc         string     `gorm:"column:secret_enc;type:varchar(256);not null" json:"-"`
+	PrevSecretEnc     string     `gorm:"column:prev_secret_enc;type:varchar(256)" json:"-"`                  // 轮换前的密钥，宽限期内仍可签名
+	PrevSecretExpires *time.Time `gorm:"column:prev_secret_expires" json:"prev_secret_expires"`              // 旧密钥失效时间
+	Scopes            string     `gorm:"column:scopes;type:varchar(255);not null" json:"scopes"`             // 逗号分隔的权限
+	AllowedIPs        string     `gorm:"column:allowed_ips;type:varchar(1024)" json:"allowed_ips"`           // 逗号分隔的 IP 或 CIDR，为空不限制
+	Status            int8       `gorm:"column:status;type:tinyint;not null;index:idx_status" json:"status"` // 1.ACTIVE 2.REVOKED
+	CreatedBy         string     `gorm:"column:created_by;type:varchar(64)" json:"created_by"`
+	RotatedAt         *time.Time `gorm:"column:rotated_at" json:"rotated_at"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// GeneratedWallet 接口生成的随机钱包，私钥以 go-ethereum keystore JSON 加密保存
+type GeneratedWallet struct {
+	ID           uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Address      string    `gorm:"column:address;type:varchar(64);not null;uniqueIndex:uk_address" json:"address"`
+	Keystore     string    `gorm:"column:keystore;type:text;not null" json:"-"`
+	Source       string    `gorm:"column:source;type:varchar(32);not null" json:"source"` // generate_wallet/airdrop_erc20/airdrop_bnb
+	Campaign     string    `gorm:"column:campaign;type:varchar(128);index:idx_campaign" json:"campaign"`
+	GenerationID string    `gorm:"column:generation_id;type:varchar(32);not null;index:idx_generation_id" json:"generation_id"` // 同一次生成的钱包共用
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+}
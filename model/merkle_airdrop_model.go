@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// MerkleAirdrop 领取式空投，root 需写入领取合约后用户才能领取
+type MerkleAirdrop struct {
+	ID              uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Name            string    `gorm:"column:name;type:varchar(128)" json:"name"`
+	ContractAddress string    `gorm:"column:contract_address;type:varchar(64);not null;index:idx_contract" json:"contract_address"` // 领取合约地址
+	Root            string    `gorm:"column:root;type:varchar(66);not null" json:"root"`
+	TotalRecipients int       `gorm:"column:total_recipients;type:int" json:"total_recipients"`
+	TotalAmount     string    `gorm:"column:total_amount;type:varchar(100);default:'0'" json:"total_amount"`
+	ClaimedCount    int       `gorm:"column:claimed_count;type:int;default:0" json:"claimed_count"`
+	ClaimedAmount   string    `gorm:"column:claimed_amount;type:varchar(100);default:'0'" json:"claimed_amount"`
+	CreatedAt       time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
+
+// MerkleAirdropLeaf 领取式空投的叶子节点及证明
+type MerkleAirdropLeaf struct {
+	ID          uint64     `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	AirdropID   uint64     `gorm:"column:airdrop_id;type:bigint unsigned;not null;uniqueIndex:uk_airdrop_index;uniqueIndex:uk_airdrop_address" json:"airdrop_id"`
+	LeafIndex   uint64     `gorm:"column:leaf_index;type:bigint unsigned;not null;uniqueIndex:uk_airdrop_index" json:"leaf_index"`
+	Address     string     `gorm:"column:address;type:varchar(64);not null;uniqueIndex:uk_airdrop_address" json:"address"`
+	Amount      string     `gorm:"column:amount;type:varchar(100);not null" json:"amount"`
+	Leaf        string     `gorm:"column:leaf;type:varchar(66);not null" json:"leaf"`
+	Proof       string     `gorm:"column:proof;type:text" json:"proof"` // JSON 哈希数组
+	Claimed     bool       `gorm:"column:claimed;type:tinyint(1);default:0" json:"claimed"`
+	ClaimTxHash string     `gorm:"column:claim_tx_hash;type:varchar(100)" json:"claim_tx_hash"`
+	ClaimBlock  uint64     `gorm:"column:claim_block;type:bigint unsigned;default:0" json:"claim_block"`
+	ClaimedAt   *time.Time `gorm:"column:claimed_at;type:datetime" json:"claimed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OutboundTx 通过接口发起的链上交易，对应 outbound_tx 表
+type OutboundTx struct {
+	ID          uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Hash        string    `gorm:"column:hash;type:varchar(100);not null;uniqueIndex:uk_hash" json:"hash"`
+	Purpose     string    `gorm:"column:purpose;type:varchar(32);not null" json:"purpose"` // approve/stake/withdraw/transfer_erc20/transfer_bnb/airdrop_erc20/airdrop_bnb
+	FromAddress string    `gorm:"column:from_address;type:varchar(64)" json:"from_address"`
+	ToAddress   string    `gorm:"column:to_address;type:varchar(64)" json:"to_address"`
+	Value       string    `gorm:"column:value;type:varchar(64);default:'0'" json:"value"`
+	Nonce       uint64    `gorm:"column:nonce;type:bigint unsigned" json:"nonce"`
+	Status      int8      `gorm:"column:status;type:tinyint;index:idx_status" json:"status"` // 1.PENDING 2.SUCCESS 3.FAILED 4.DROPPED
+	BlockNumber uint64    `gorm:"column:block_number;type:bigint unsigned;default:0" json:"block_number"`
+	GasUsed     uint64    `gorm:"column:gas_used;type:bigint unsigned;default:0" json:"gas_used"`
+	GasPrice    string    `gorm:"column:gas_price;type:varchar(64);default:'0'" json:"gas_price"` // 实际生效的 gas price
+	Fee         string    `gorm:"column:fee;type:varchar(64);default:'0'" json:"fee"`             // gasUsed × gasPrice
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
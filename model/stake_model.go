@@ -13,9 +13,20 @@ type Stake struct {
 	FromAddress     string    `json:"from_address" gorm:"column:from_address;type:varchar(100);not null" comment:"发起地址"`
 	Method          string    `json:"method" gorm:"column:method;type:varchar(20);not null" comment:"操作方法：stake-质押，withdraw-提取"`
 	Amount          string    `json:"amount" gorm:"column:amount;type:varchar(255)" comment:"交易金额"`
+	Period          uint8     `json:"period" gorm:"column:period;type:tinyint;default:0" comment:"质押周期枚举值，仅 Staked 记录有效"`
 	BlockNumber     int64     `json:"block_number" gorm:"column:block_number;type:bigint" comment:"区块编号"`
 	Status          int8      `json:"status" gorm:"column:status;type:tinyint;default:0" comment:"状态：0-质押中，1-已提取"`
 	Timestamp       time.Time `json:"timestamp" gorm:"column:timestamp;type:datetime" comment:"交易时间戳"`
 	CreatedDate     time.Time `json:"created_date" gorm:"column:created_date;default:current_timestamp" comment:"记录创建时间"`
 	UpdatedDate     time.Time `json:"updated_date" gorm:"column:updated_date;default:current_timestamp on update current_timestamp" comment:"记录更新时间"`
 }
+
+// StakeTvlSnapshot 索引 TVL 与链上质押合约余额的对账快照
+type StakeTvlSnapshot struct {
+	ID          int64     `json:"id" gorm:"column:id;primaryKey;autoIncrement" comment:"自增主键ID"`
+	IndexedTvl  string    `json:"indexed_tvl" gorm:"column:indexed_tvl;type:varchar(100);default:'0'" comment:"根据 stake 表计算的 TVL"`
+	OnchainTvl  string    `json:"onchain_tvl" gorm:"column:onchain_tvl;type:varchar(100);default:'0'" comment:"质押合约持有的 stakingToken 余额"`
+	Diff        string    `json:"diff" gorm:"column:diff;type:varchar(100);default:'0'" comment:"链上余额 - 索引 TVL"`
+	BlockNumber int64     `json:"block_number" gorm:"column:block_number;type:bigint" comment:"对账区块编号"`
+	CreatedDate time.Time `json:"created_date" gorm:"column:created_date;default:current_timestamp" comment:"记录创建时间"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// StakeNotification 质押到期通知记录，每笔质押每个节点每个渠道只发送一次
+type StakeNotification struct {
+	ID          uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	StakeIndex  string    `gorm:"column:stake_index;type:varchar(100);not null;uniqueIndex:uk_stake_milestone_channel" json:"stake_index"`
+	Milestone   string    `gorm:"column:milestone;type:varchar(20);not null;uniqueIndex:uk_stake_milestone_channel" json:"milestone"` // upcoming/matured
+	Channel     string    `gorm:"column:channel;type:varchar(20);not null;uniqueIndex:uk_stake_milestone_channel" json:"channel"`     // webhook/email/inbox
+	UserAddress string    `gorm:"column:user_address;type:varchar(64);not null" json:"user_address"`
+	Amount      string    `gorm:"column:amount;type:varchar(64);default:'0'" json:"amount"`
+	EndTime     time.Time `gorm:"column:end_time;type:datetime" json:"end_time"`
+	Status      int8      `gorm:"column:status;type:tinyint;index:idx_status" json:"status"` // 1.SENT 2.FAILED
+	Attempts    int       `gorm:"column:attempts;type:int;default:0" json:"attempts"`
+	Error       string    `gorm:"column:error;type:varchar(512)" json:"error"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;" comment:"记录更新时间"`
+}
+
+// InboxMessage 站内信
+type InboxMessage struct {
+	ID        uint64    `gorm:"column:id;type:bigint unsigned;primary_key;AUTO_INCREMENT" json:"id"`
+	Address   string    `gorm:"column:address;type:varchar(64);not null;index:idx_address" json:"address"`
+	Category  string    `gorm:"column:category;type:varchar(32);not null" json:"category"`
+	Title     string    `gorm:"column:title;type:varchar(128);not null" json:"title"`
+	Content   string    `gorm:"column:content;type:varchar(1024)" json:"content"`
+	IsRead    bool      `gorm:"column:is_read;type:tinyint(1);default:0" json:"is_read"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;" comment:"记录创建时间"`
+}
@@ -10,10 +10,15 @@ import (
 	"time"
 )
 
+// GetAccount 按钱包地址查找账户，钱包以 account_wallet 关联表为准，历史账户由启动时的 BackfillAccountWallets 补写
 func GetAccount(fromAddress string) (*model.Account, error) {
 	account := model.Account{}
-	if err := adapter.DB.Model(&model.Account{}).Where("wallet_address = ?", fromAddress).First(&account).Error; err != nil {
-		return nil, fmt.Errorf("repo: get account asset failed: %w", err)
+	err := adapter.DB.Model(&model.Account{}).
+		Joins("JOIN account_wallet ON account_wallet.account_id = account.account_id").
+		Where("account_wallet.address = ?", fromAddress).
+		First(&account).Error
+	if err != nil {
+		return nil, fmt.Errorf("repo: get account failed: %w", err)
 	}
 
 	return &account, nil
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm/clause"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+func GetAccountByID(accountID int) (*model.Account, error) {
+	account := model.Account{}
+	if err := adapter.DB.Where("account_id = ?", accountID).First(&account).Error; err != nil {
+		return nil, fmt.Errorf("repo: get account failed: %w", err)
+	}
+	return &account, nil
+}
+
+func GetAccountRoles(accountID int) ([]model.AccountRole, error) {
+	var roles []model.AccountRole
+	if err := adapter.DB.Where("account_id = ?", accountID).Order("id").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("repo: get account roles failed: %w", err)
+	}
+	return roles, nil
+}
+
+// AddAccountRole 已存在同名角色时返回 false，依赖 uk_account_role 唯一索引，并发授权不会报重复键错误
+func AddAccountRole(role *model.AccountRole) (bool, error) {
+	res := adapter.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(role)
+	if res.Error != nil {
+		return false, fmt.Errorf("repo: add account role failed: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteAccountRole 返回是否删除了记录
+func DeleteAccountRole(accountID int, role string) (bool, error) {
+	res := adapter.DB.Where("account_id = ? AND role = ?", accountID, role).Delete(&model.AccountRole{})
+	if res.Error != nil {
+		return false, fmt.Errorf("repo: delete account role failed: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/model"
+)
+
+// BackfillAccountWallets 为 account_wallet 出现前注册的账户补写钱包关联，按地址格式区分 bsc/solana。
+// 进程启动连上数据库后执行一次；依赖 uk_chain_address 唯一索引，可重复执行，多个进程同时执行也不会重复写入；返回补写的记录数
+func BackfillAccountWallets() (int64, error) {
+	res := adapter.DB.Exec(`INSERT IGNORE INTO account_wallet (account_id, chain, address, created_at)
+SELECT a.account_id, IF(a.wallet_address LIKE '0x%', ?, ?), a.wallet_address, NOW()
+FROM account a
+WHERE a.wallet_address IS NOT NULL AND a.wallet_address <> ''
+AND NOT EXISTS (SELECT 1 FROM account_wallet w WHERE w.account_id = a.account_id AND w.address = a.wallet_address)`,
+		config.LoginChainBSC, config.LoginChainSolana)
+	if res.Error != nil {
+		return 0, fmt.Errorf("repo: backfill account wallets failed: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// CreateAccountWithWallet 在同一事务中创建账户、资产和钱包关联
+func CreateAccountWithWallet(account *model.Account, chain string) error {
+	err := adapter.DB.Transaction(func(tx *gorm.DB) error {
+		// email 有唯一索引，未填写时写入 NULL
+		if err := tx.Omit("Email").Create(account).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Account").Create(&model.AccountAsset{
+			AccountID:  account.AccountID,
+			BnbBalance: "0",
+			MtkBalance: "0",
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.AccountWallet{
+			AccountID: account.AccountID,
+			Chain:     chain,
+			Address:   account.WalletAddress,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("repo: create account failed: %w", err)
+	}
+	return nil
+}
+
+func AddAccountWallet(wallet *model.AccountWallet) error {
+	if err := adapter.DB.Create(wallet).Error; err != nil {
+		return fmt.Errorf("repo: add account wallet failed: %w", err)
+	}
+	return nil
+}
+
+func GetAccountWallet(chain string, address string) (*model.AccountWallet, error) {
+	wallet := model.AccountWallet{}
+	if err := adapter.DB.Where("chain = ? AND address = ?", chain, address).First(&wallet).Error; err != nil {
+		return nil, fmt.Errorf("repo: get account wallet failed: %w", err)
+	}
+	return &wallet, nil
+}
+
+func GetAccountWallets(accountID int) ([]model.AccountWallet, error) {
+	var wallets []model.AccountWallet
+	if err := adapter.DB.Where("account_id = ?", accountID).Order("id").Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("repo: get account wallets failed: %w", err)
+	}
+	return wallets, nil
+}
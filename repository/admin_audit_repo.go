@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"fmt"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+func AddAdminAuditLog(log *model.AdminAuditLog) error {
+	if err := adapter.DB.Create(log).Error; err != nil {
+		return fmt.Errorf("repo: add admin audit log failed: %w", err)
+	}
+	return nil
+}
+
+func UpdateAdminAuditLog(log *model.AdminAuditLog) error {
+	if err := adapter.DB.Save(log).Error; err != nil {
+		return fmt.Errorf("repo: update admin audit log failed: %w", err)
+	}
+	return nil
+}
+
+// GetAdminAuditLogs 按时间倒序查询审计日志，action 为空时不过滤
+func GetAdminAuditLogs(action string, limit int) ([]model.AdminAuditLog, error) {
+	var logs []model.AdminAuditLog
+	query := adapter.DB.Order("id DESC").Limit(limit)
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("repo: get admin audit logs failed: %w", err)
+	}
+	return logs, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/model"
+	"time"
+)
+
+// CreateAirdropCampaign 在同一事务中创建活动及全部批次
+func CreateAirdropCampaign(campaign *model.AirdropCampaign, batches []model.AirdropBatch) error {
+	return adapter.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(campaign).Error; err != nil {
+			return fmt.Errorf("repo: create airdrop campaign failed: %w", err)
+		}
+		for i := range batches {
+			batches[i].CampaignID = campaign.ID
+		}
+		if err := tx.CreateInBatches(batches, 100).Error; err != nil {
+			return fmt.Errorf("repo: create airdrop batches failed: %w", err)
+		}
+		return nil
+	})
+}
+
+func GetAirdropCampaign(id uint64) (*model.AirdropCampaign, error) {
+	var campaign model.AirdropCampaign
+	if err := adapter.DB.First(&campaign, id).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop campaign failed: %w", err)
+	}
+	return &campaign, nil
+}
+
+func GetAirdropCampaignsByStatus(statuses ...int) ([]model.AirdropCampaign, error) {
+	var campaigns []model.AirdropCampaign
+	if err := adapter.DB.Where("status IN ?", statuses).Order("id").Find(&campaigns).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop campaigns by status failed: %w", err)
+	}
+	return campaigns, nil
+}
+
+func UpdateAirdropCampaignStatus(id uint64, status int) error {
+	err := adapter.DB.Model(&model.AirdropCampaign{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("repo: update airdrop campaign status failed: %w", err)
+	}
+	return nil
+}
+
+func GetAirdropBatches(campaignID uint64) ([]model.AirdropBatch, error) {
+	var batches []model.AirdropBatch
+	if err := adapter.DB.Where("campaign_id = ?", campaignID).Order("batch_num").Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop batches failed: %w", err)
+	}
+	return batches, nil
+}
+
+func SaveAirdropBatch(batch *model.AirdropBatch) error {
+	batch.UpdatedAt = time.Now()
+	// 投递核对字段由事件索引单独维护，避免执行器用旧值覆盖
+	if err := adapter.DB.Omit("delivery_status", "delivery_error").Save(batch).Error; err != nil {
+		return fmt.Errorf("repo: save airdrop batch failed: %w", err)
+	}
+	return nil
+}
+
+func GetAirdropBatch(campaignID uint64, batchNum int) (*model.AirdropBatch, error) {
+	var batch model.AirdropBatch
+	if err := adapter.DB.Where("campaign_id = ? AND batch_num = ?", campaignID, batchNum).First(&batch).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop batch failed: %w", err)
+	}
+	return &batch, nil
+}
+
+// ResetFailedAirdropBatches 将失败批次重置为待签名并重新激活活动，返回重置的批次数；待核对批次不受影响
+func ResetFailedAirdropBatches(campaignID uint64) (int64, error) {
+	var affected int64
+	err := adapter.DB.Transaction(func(tx *gorm.DB) error {
+		res := resetAirdropBatches(tx.Where("campaign_id = ? AND status = ?", campaignID, config.AirdropBatchStatusFailed))
+		if res.Error != nil {
+			return fmt.Errorf("repo: reset failed airdrop batches failed: %w", res.Error)
+		}
+		affected = res.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return reactivateAirdropCampaign(tx, campaignID)
+	})
+	return affected, err
+}
+
+// ReleaseReviewAirdropBatch 将待核对批次重置为待签名，并在同一事务中写入审计日志；批次已不是待核对状态时返回 false
+func ReleaseReviewAirdropBatch(campaignID uint64, batchNum int, audit *model.AdminAuditLog) (bool, error) {
+	released := false
+	err := adapter.DB.Transaction(func(tx *gorm.DB) error {
+		res := resetAirdropBatches(tx.Where("campaign_id = ? AND batch_num = ? AND status = ?", campaignID, batchNum, config.AirdropBatchStatusReview))
+		if res.Error != nil {
+			return fmt.Errorf("repo: release review airdrop batch failed: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		released = true
+		if err := tx.Create(audit).Error; err != nil {
+			return fmt.Errorf("repo: add admin audit log failed: %w", err)
+		}
+		return reactivateAirdropCampaign(tx, campaignID)
+	})
+	return released, err
+}
+
+// resetAirdropBatches 清空签名结果，批次回到待签名状态
+func resetAirdropBatches(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&model.AirdropBatch{}).
+		Updates(map[string]interface{}{
+			"status":       config.AirdropBatchStatusPending,
+			"nonce":        0,
+			"tx_hash":      "",
+			"raw_tx":       "",
+			"attempts":     0,
+			"missed_polls": 0,
+			"updated_at":   time.Now(),
+		})
+}
+
+func reactivateAirdropCampaign(tx *gorm.DB, campaignID uint64) error {
+	err := tx.Model(&model.AirdropCampaign{}).Where("id = ?", campaignID).
+		Updates(map[string]interface{}{"status": config.AirdropCampaignStatusRunning, "updated_at": time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("repo: reactivate airdrop campaign failed: %w", err)
+	}
+	return nil
+}
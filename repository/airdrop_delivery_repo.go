@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+	"time"
+)
+
+// AddAirdropDeliveries 保存投递记录，同一日志重复索引时忽略
+func AddAirdropDeliveries(deliveries []model.AirdropDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := adapter.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("repo: add airdrop deliveries failed: %w", err)
+	}
+	return nil
+}
+
+func GetAirdropDeliveriesByTxHash(txHash string) ([]model.AirdropDelivery, error) {
+	var deliveries []model.AirdropDelivery
+	if err := adapter.DB.Where("tx_hash = ?", txHash).Order("log_index").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop deliveries by tx hash failed: %w", err)
+	}
+	return deliveries, nil
+}
+
+func GetAirdropDeliveriesByCampaign(campaignID uint64) ([]model.AirdropDelivery, error) {
+	var deliveries []model.AirdropDelivery
+	if err := adapter.DB.Where("campaign_id = ?", campaignID).Order("id").Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop deliveries by campaign failed: %w", err)
+	}
+	return deliveries, nil
+}
+
+// GetAirdropBatchByTxHash 根据交易哈希查询批次，不存在时返回 nil
+func GetAirdropBatchByTxHash(txHash string) (*model.AirdropBatch, error) {
+	var batch model.AirdropBatch
+	err := adapter.DB.Where("tx_hash = ?", txHash).First(&batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repo: get airdrop batch by tx hash failed: %w", err)
+	}
+	return &batch, nil
+}
+
+// UpdateAirdropBatchDelivery 回填批次的投递核对结果，并将投递记录关联到批次
+func UpdateAirdropBatchDelivery(batch *model.AirdropBatch, status int, detail string) error {
+	return adapter.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.AirdropBatch{}).Where("id = ?", batch.ID).Updates(map[string]interface{}{
+			"delivery_status": status,
+			"delivery_error":  detail,
+			"updated_at":      time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("repo: update airdrop batch delivery failed: %w", err)
+		}
+		err = tx.Model(&model.AirdropDelivery{}).Where("tx_hash = ?", batch.TxHash).Updates(map[string]interface{}{
+			"campaign_id": batch.CampaignID,
+			"batch_id":    batch.ID,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("repo: link airdrop deliveries failed: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm/clause"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+func GetAirdropGovernors(contract string) ([]model.AirdropGovernor, error) {
+	var governors []model.AirdropGovernor
+	if err := adapter.DB.Where("contract = ?", contract).Order("id").Find(&governors).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop governors failed: %w", err)
+	}
+	return governors, nil
+}
+
+// UpsertAirdropGovernor 按 (contract, address) 写入治理地址状态
+func UpsertAirdropGovernor(governor *model.AirdropGovernor) error {
+	err := adapter.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "contract"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"active", "tx_hash", "block_number", "updated_at"}),
+	}).Create(governor).Error
+	if err != nil {
+		return fmt.Errorf("repo: upsert airdrop governor failed: %w", err)
+	}
+	return nil
+}
+
+func UpdateAirdropGovernorActive(id uint64, active bool) error {
+	if err := adapter.DB.Model(&model.AirdropGovernor{}).Where("id = ?", id).Update("active", active).Error; err != nil {
+		return fmt.Errorf("repo: update airdrop governor failed: %w", err)
+	}
+	return nil
+}
+
+// AddAirdropOwnershipTransfers 保存 OwnershipTransferred 事件，同一日志重复索引时忽略
+func AddAirdropOwnershipTransfers(transfers []model.AirdropOwnershipTransfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+	if err := adapter.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&transfers).Error; err != nil {
+		return fmt.Errorf("repo: add airdrop ownership transfers failed: %w", err)
+	}
+	return nil
+}
+
+func GetAirdropOwnershipTransfers(contract string, limit int) ([]model.AirdropOwnershipTransfer, error) {
+	var transfers []model.AirdropOwnershipTransfer
+	if err := adapter.DB.Where("contract = ?", contract).Order("block_number DESC, log_index DESC").Limit(limit).
+		Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("repo: get airdrop ownership transfers failed: %w", err)
+	}
+	return transfers, nil
+}
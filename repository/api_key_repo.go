@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"fmt"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+func AddApiKey(key *model.ApiKey) error {
+	if err := adapter.DB.Create(key).Error; err != nil {
+		return fmt.Errorf("repo: add api key failed: %w", err)
+	}
+	return nil
+}
+
+func UpdateApiKey(key *model.ApiKey) error {
+	if err := adapter.DB.Save(key).Error; err != nil {
+		return fmt.Errorf("repo: update api key failed: %w", err)
+	}
+	return nil
+}
+
+func GetApiKey(keyID string) (*model.ApiKey, error) {
+	key := model.ApiKey{}
+	if err := adapter.DB.Where("key_id = ?", keyID).First(&key).Error; err != nil {
+		return nil, fmt.Errorf("repo: get api key failed: %w", err)
+	}
+	return &key, nil
+}
+
+func GetApiKeys() ([]model.ApiKey, error) {
+	var keys []model.ApiKey
+	if err := adapter.DB.Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("repo: get api keys failed: %w", err)
+	}
+	return keys, nil
+}
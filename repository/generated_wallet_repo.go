@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"fmt"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+func AddGeneratedWallets(wallets []model.GeneratedWallet) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+	if err := adapter.DB.CreateInBatches(&wallets, 500).Error; err != nil {
+		return fmt.Errorf("repo: add generated wallets failed: %w", err)
+	}
+	return nil
+}
+
+// GetGeneratedWallets 按生成批次或活动标记查询钱包，条件为空时不过滤
+func GetGeneratedWallets(generationID string, campaign string, limit int) ([]model.GeneratedWallet, error) {
+	var wallets []model.GeneratedWallet
+	query := adapter.DB.Order("id").Limit(limit)
+	if generationID != "" {
+		query = query.Where("generation_id = ?", generationID)
+	}
+	if campaign != "" {
+		query = query.Where("campaign = ?", campaign)
+	}
+	if err := query.Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("repo: get generated wallets failed: %w", err)
+	}
+	return wallets, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+	"time"
+)
+
+// LedgerFilter 账单和充值记录的查询条件，零值字段不过滤；按主键倒序，BeforeID 为上一页最后一条的主键
+type LedgerFilter struct {
+	AccountID int
+	TokenType int
+	BillType  int // 只对账单生效
+	From      time.Time
+	To        time.Time
+	MinAmount string // 最小单位的整数金额
+	MaxAmount string
+	BeforeID  uint64
+	Limit     int
+}
+
+// GetBills 按条件查询账户账单
+func GetBills(filter LedgerFilter) ([]model.Bill, error) {
+	var bills []model.Bill
+	query := applyLedgerFilter(adapter.DB.Model(&model.Bill{}), filter, "id")
+	if filter.BillType > 0 {
+		query = query.Where("bill_type = ?", filter.BillType)
+	}
+	if err := query.Find(&bills).Error; err != nil {
+		return nil, fmt.Errorf("repo: get bills failed: %w", err)
+	}
+	return bills, nil
+}
+
+// GetTransactionLogs 按条件查询账户充值交易记录
+func GetTransactionLogs(filter LedgerFilter) ([]model.TransactionLog, error) {
+	var logs []model.TransactionLog
+	query := applyLedgerFilter(adapter.DB.Model(&model.TransactionLog{}), filter, "log_id")
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("repo: get transaction logs failed: %w", err)
+	}
+	return logs, nil
+}
+
+func applyLedgerFilter(query *gorm.DB, filter LedgerFilter, idColumn string) *gorm.DB {
+	query = query.Where("account_id = ?", filter.AccountID).Order(idColumn + " DESC").Limit(filter.Limit)
+	if filter.TokenType > 0 {
+		query = query.Where("token_type = ?", filter.TokenType)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	// 金额以字符串保存，按数值比较
+	if filter.MinAmount != "" {
+		query = query.Where("CAST(amount AS DECIMAL(65,0)) >= CAST(? AS DECIMAL(65,0))", filter.MinAmount)
+	}
+	if filter.MaxAmount != "" {
+		query = query.Where("CAST(amount AS DECIMAL(65,0)) <= CAST(? AS DECIMAL(65,0))", filter.MaxAmount)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where(idColumn+" < ?", filter.BeforeID)
+	}
+	return query
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"math/big"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+	"time"
+)
+
+// CreateMerkleAirdrop 在同一事务中保存空投及全部叶子
+func CreateMerkleAirdrop(airdrop *model.MerkleAirdrop, leaves []model.MerkleAirdropLeaf) error {
+	return adapter.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(airdrop).Error; err != nil {
+			return fmt.Errorf("repo: create merkle airdrop failed: %w", err)
+		}
+		for i := range leaves {
+			leaves[i].AirdropID = airdrop.ID
+		}
+		if err := tx.CreateInBatches(leaves, 500).Error; err != nil {
+			return fmt.Errorf("repo: create merkle airdrop leaves failed: %w", err)
+		}
+		return nil
+	})
+}
+
+func GetMerkleAirdrop(id uint64) (*model.MerkleAirdrop, error) {
+	var airdrop model.MerkleAirdrop
+	if err := adapter.DB.First(&airdrop, id).Error; err != nil {
+		return nil, fmt.Errorf("repo: get merkle airdrop failed: %w", err)
+	}
+	return &airdrop, nil
+}
+
+func GetMerkleAirdropLeaf(airdropID uint64, address string) (*model.MerkleAirdropLeaf, error) {
+	var leaf model.MerkleAirdropLeaf
+	if err := adapter.DB.Where("airdrop_id = ? AND address = ?", airdropID, address).First(&leaf).Error; err != nil {
+		return nil, fmt.Errorf("repo: get merkle airdrop leaf failed: %w", err)
+	}
+	return &leaf, nil
+}
+
+// GetMerkleClaimContracts 返回所有领取式空投使用的合约地址
+func GetMerkleClaimContracts() ([]string, error) {
+	var contracts []string
+	if err := adapter.DB.Model(&model.MerkleAirdrop{}).Distinct().Pluck("contract_address", &contracts).Error; err != nil {
+		return nil, fmt.Errorf("repo: get merkle claim contracts failed: %w", err)
+	}
+	return contracts, nil
+}
+
+// MarkMerkleLeafClaimed 根据领取合约、叶子序号、领取地址和金额标记已领取并累加空投的领取统计，重复事件不会重复累加。
+// 同一合约存在多个空投时优先匹配最新创建的空投
+func MarkMerkleLeafClaimed(contract string, index uint64, account string, amount *big.Int, txHash string, blockNumber uint64) (bool, error) {
+	marked := false
+	err := adapter.DB.Transaction(func(tx *gorm.DB) error {
+		var airdrops []model.MerkleAirdrop
+		if err := tx.Where("contract_address = ?", contract).Order("id DESC").Find(&airdrops).Error; err != nil {
+			return fmt.Errorf("repo: get merkle airdrops by contract failed: %w", err)
+		}
+		now := time.Now()
+		for _, airdrop := range airdrops {
+			res := tx.Model(&model.MerkleAirdropLeaf{}).
+				Where("airdrop_id = ? AND leaf_index = ? AND address = ? AND amount = ? AND claimed = ?", airdrop.ID, index, account, amount.String(), false).
+				Updates(map[string]interface{}{
+					"claimed":       true,
+					"claim_tx_hash": txHash,
+					"claim_block":   blockNumber,
+					"claimed_at":    now,
+				})
+			if res.Error != nil {
+				return fmt.Errorf("repo: mark merkle leaf claimed failed: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+			claimed, ok := new(big.Int).SetString(airdrop.ClaimedAmount, 10)
+			if !ok {
+				claimed = new(big.Int)
+			}
+			err := tx.Model(&model.MerkleAirdrop{}).Where("id = ?", airdrop.ID).Updates(map[string]interface{}{
+				"claimed_count":  gorm.Expr("claimed_count + 1"),
+				"claimed_amount": claimed.Add(claimed, amount).String(),
+				"updated_at":     now,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("repo: update merkle airdrop claimed stats failed: %w", err)
+			}
+			marked = true
+			return nil
+		}
+		return nil
+	})
+	return marked, err
+}
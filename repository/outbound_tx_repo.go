@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+func AddOutboundTx(tx *model.OutboundTx) error {
+	if tx == nil {
+		return fmt.Errorf("outbound tx 不能为 nil")
+	}
+	if err := adapter.DB.Create(tx).Error; err != nil {
+		return fmt.Errorf("repo: add outbound tx failed: %w", err)
+	}
+	return nil
+}
+
+func GetOutboundTxByHash(hash string) (*model.OutboundTx, error) {
+	var tx model.OutboundTx
+	if err := adapter.DB.Where("hash = ?", hash).First(&tx).Error; err != nil {
+		return nil, fmt.Errorf("repo: get outbound tx failed: %w", err)
+	}
+	return &tx, nil
+}
+
+// GetOutboundTxByStatus 按 id 游标分页，返回 id 大于 afterID 的记录
+func GetOutboundTxByStatus(status int, afterID uint64, limit int) ([]model.OutboundTx, error) {
+	var txs []model.OutboundTx
+	if err := adapter.DB.Where("status = ? AND id > ?", status, afterID).Order("id").Limit(limit).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("repo: get outbound tx by status failed: %w", err)
+	}
+	return txs, nil
+}
+
+func UpdateOutboundTx(tx *model.OutboundTx) error {
+	if err := adapter.DB.Save(tx).Error; err != nil {
+		return fmt.Errorf("repo: update outbound tx failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"staking-interaction/adapter"
+	"staking-interaction/model"
+)
+
+// GetStakeNotification 查询通知记录，不存在时返回 nil
+func GetStakeNotification(stakeIndex string, milestone string, channel string) (*model.StakeNotification, error) {
+	var notification model.StakeNotification
+	err := adapter.DB.Where("stake_index = ? AND milestone = ? AND channel = ?", stakeIndex, milestone, channel).
+		First(&notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repo: get stake notification failed: %w", err)
+	}
+	return &notification, nil
+}
+
+func SaveStakeNotification(notification *model.StakeNotification) error {
+	if err := adapter.DB.Save(notification).Error; err != nil {
+		return fmt.Errorf("repo: save stake notification failed: %w", err)
+	}
+	return nil
+}
+
+func AddInboxMessage(message *model.InboxMessage) error {
+	if err := adapter.DB.Create(message).Error; err != nil {
+		return fmt.Errorf("repo: add inbox message failed: %w", err)
+	}
+	return nil
+}
+
+// GetInboxMessages 查询多个地址的站内信，按时间倒序
+func GetInboxMessages(addresses []string, limit int) ([]model.InboxMessage, error) {
+	var messages []model.InboxMessage
+	err := adapter.DB.Where("address IN ?", addresses).Order("id desc").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, fmt.Errorf("repo: get inbox messages failed: %w", err)
+	}
+	return messages, nil
+}
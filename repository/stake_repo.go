@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"fmt"
 	"log"
 	"staking-interaction/adapter"
+	"staking-interaction/common/config"
 	"staking-interaction/model"
+	"time"
 )
 
 func AddStakeInfo(stake model.Stake) {
 	adapter.DB.Create(&stake)
 
-	log.Printf("Add " + stake.Method + " info to database success, indexId: " + stake.IndexNum)
+	log.Printf("Add %s info to database success, indexId: %s", stake.Method, stake.IndexNum)
 }
 
 func GetAllStakesByFromAddress(fromAddress string) model.Stake {
@@ -17,3 +20,39 @@ func GetAllStakesByFromAddress(fromAddress string) model.Stake {
 	adapter.DB.Where("from_address = ?", fromAddress).First(&stake)
 	return stake
 }
+
+// GetActiveStakes 查询尚未提取的质押记录
+func GetActiveStakes() ([]model.Stake, error) {
+	var stakes []model.Stake
+	withdrawn := adapter.DB.Model(&model.Stake{}).Select("index_num").Where("method = ?", config.WithdrawnEventName)
+	err := adapter.DB.Where("method = ? AND index_num NOT IN (?)", config.StakedEventName, withdrawn).Find(&stakes).Error
+	if err != nil {
+		return nil, fmt.Errorf("repo: get active stakes failed: %w", err)
+	}
+	return stakes, nil
+}
+
+// GetStakesBetween 查询时间区间内的质押和提取记录
+func GetStakesBetween(from time.Time, to time.Time) ([]model.Stake, error) {
+	var stakes []model.Stake
+	err := adapter.DB.Where("timestamp >= ? AND timestamp < ?", from, to).Order("timestamp").Find(&stakes).Error
+	if err != nil {
+		return nil, fmt.Errorf("repo: get stakes between failed: %w", err)
+	}
+	return stakes, nil
+}
+
+func AddTvlSnapshot(snapshot *model.StakeTvlSnapshot) error {
+	if err := adapter.DB.Create(snapshot).Error; err != nil {
+		return fmt.Errorf("repo: add tvl snapshot failed: %w", err)
+	}
+	return nil
+}
+
+func GetLatestTvlSnapshot() (*model.StakeTvlSnapshot, error) {
+	var snapshot model.StakeTvlSnapshot
+	if err := adapter.DB.Order("id desc").First(&snapshot).Error; err != nil {
+		return nil, fmt.Errorf("repo: get latest tvl snapshot failed: %w", err)
+	}
+	return &snapshot, nil
+}
@@ -27,3 +27,15 @@ func GetWithdrawalInfoByStatus(status int) ([]model.Withdrawal, error) {
 	}
 	return withdrawalInfo, nil
 }
+
+// GetWithdrawalsByAddresses 查询钱包地址指定状态的提现记录
+func GetWithdrawalsByAddresses(addresses []string, statuses []int) ([]model.Withdrawal, error) {
+	var withdrawals []model.Withdrawal
+	if len(addresses) == 0 {
+		return withdrawals, nil
+	}
+	if err := adapter.DB.Where("wallet_address IN ? AND status IN ?", addresses, statuses).Find(&withdrawals).Error; err != nil {
+		return nil, fmt.Errorf("repo: get withdrawals by addresses failed: %w", err)
+	}
+	return withdrawals, nil
+}
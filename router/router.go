@@ -1,8 +1,10 @@
 package router
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"staking-interaction/common/config"
 	"staking-interaction/controller"
 	"staking-interaction/middleware"
 )
@@ -11,39 +13,147 @@ func InitRouter(redis *redis.Client) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	router.Use(gin.Recovery())
+	// 未配置可信代理时不读取 X-Forwarded-For，API Key 的 IP 白名单依赖真实的客户端 IP
+	if err := router.SetTrustedProxies(config.Get().AppConfig.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("invalid app.trusted_proxies: %v", err))
+	}
+
+	router.GET("/.well-known/jwks.json", controller.GetJWKS)
 
 	group := router.Group("/v1")
+	authMid := middleware.NewAuthMiddleware(redis)
+	rateMid := middleware.NewRateLimitMiddleware(redis)
+	// 空投接口的限流放在认证之后，已认证请求同时按钱包或 API Key 计数
+	airdropLimit := rateMid.Limit(config.RateLimitPolicyAirdrop)
+	// 写接口支持 Idempotency-Key，放在认证之后按请求身份隔离；返回私钥或 API Key 密钥的接口不缓存响应
+	idempotent := middleware.NewIdempotencyMiddleware(redis).Handle()
 
 	staking := group.Group("/staking")
 	{
-		staking.POST("/stake", controller.Stake)
-		staking.POST("/withdraw", controller.Withdraw)
+		// 质押和提取使用平台热钱包签名
+		staking.POST("/stake", authMid.RequirePermission(config.PermStake), idempotent, controller.Stake)
+		staking.POST("/withdraw", authMid.RequirePermission(config.PermStake), idempotent, controller.Withdraw)
 		staking.GET("stake/:address", controller.GetAllStakesByFromAddress)
+		staking.GET("/stats", controller.GetStakeStats)
+		staking.GET("/stats/daily", controller.GetStakeDailyStats)
 	}
 
 	airdrop := group.Group("/airdropping")
 	{
-		airdrop.POST("/airdroperc20", controller.AirdropERC20)
-		airdrop.POST("/airdropbnb", controller.AirdropBNB)
-		airdrop.POST("/generateWallet", controller.GenerateMultiWallets)
+		airdrop.POST("/airdroperc20", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, func(c *gin.Context) {
+			controller.AirdropERC20(c, redis)
+		})
+		airdrop.POST("/airdropbnb", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, func(c *gin.Context) {
+			controller.AirdropBNB(c, redis)
+		})
+		airdrop.POST("/generateWallet", authMid.RequirePermission(config.PermAirdrop), airdropLimit, controller.GenerateMultiWallets)
+		airdrop.POST("/campaign/erc20", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, controller.AirdropCampaignERC20)
+		airdrop.POST("/campaign/bnb", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, controller.AirdropCampaignBNB)
+		airdrop.GET("/campaign/:id", authMid.RequirePermission(config.PermAirdrop), airdropLimit, controller.GetAirdropCampaign)
+		airdrop.GET("/campaign/:id/deliveries", authMid.RequirePermission(config.PermAirdrop), airdropLimit, controller.GetAirdropCampaignDeliveries)
+		airdrop.POST("/campaign/:id/retry", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, controller.RetryAirdropCampaign)
+		airdrop.POST("/campaign/:id/batches/:batchNum/release", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, func(c *gin.Context) {
+			controller.ReleaseAirdropBatch(c, redis)
+		})
+	}
+
+	merkleAirdrops := group.Group("/airdrops")
+	{
+		merkleAirdrops.POST("", authMid.RequirePermission(config.PermAirdrop), airdropLimit, idempotent, controller.CreateMerkleAirdrop)
+		merkleAirdrops.GET("/:id", airdropLimit, controller.GetMerkleAirdrop)
+		merkleAirdrops.GET("/:id/proof/:address", airdropLimit, controller.GetMerkleProof)
 	}
 
-	authMid := middleware.NewAuthMiddleware(redis)
 	transfer := group.Group("/transfer")
-	transfer.Use(authMid.AuthMiddleware())
+	transfer.Use(authMid.RequirePermission(config.PermTransfer), rateMid.Limit(config.RateLimitPolicyTransfer), idempotent)
 	{
 		transfer.POST("/transferERC20", controller.SendErc20)
 		transfer.POST("/transferBNB", controller.SendBNB)
 	}
 
+	admin := group.Group("/admin")
+	admin.Use(authMid.AuthMiddleware())
+	{
+		admin.GET("/airdrop/governance", authMid.RequirePermission(config.PermGovernance), controller.GetAirdropGovernance)
+		admin.POST("/airdrop/governance", authMid.RequirePermission(config.PermGovernance), idempotent, func(c *gin.Context) {
+			controller.PrepareAirdropGovChange(c, redis)
+		})
+		admin.POST("/airdrop/governance/confirm", authMid.RequirePermission(config.PermGovernance), idempotent, func(c *gin.Context) {
+			controller.ConfirmAirdropGovChange(c, redis)
+		})
+		admin.GET("/audit-logs", authMid.RequirePermission(config.PermAuditRead), controller.GetAdminAuditLogs)
+		admin.GET("/wallets", authMid.RequirePermission(config.PermWalletRead), controller.ListGeneratedWallets)
+		admin.POST("/wallets/export", authMid.RequirePermission(config.PermWalletExport), controller.ExportGeneratedWallets)
+		admin.GET("/accounts/:id/balances", authMid.RequirePermission(config.PermLedgerRead), func(c *gin.Context) {
+			controller.GetAdminAccountBalances(c, redis)
+		})
+		admin.GET("/accounts/:id/bills", authMid.RequirePermission(config.PermLedgerRead), controller.GetAdminAccountBills)
+		admin.GET("/accounts/:id/deposits", authMid.RequirePermission(config.PermLedgerRead), controller.GetAdminAccountDeposits)
+		admin.GET("/accounts/:id/roles", authMid.RequirePermission(config.PermRoleManage), controller.GetAccountRoles)
+		admin.POST("/accounts/:id/roles", authMid.RequirePermission(config.PermRoleManage), idempotent, controller.GrantAccountRole)
+		admin.DELETE("/accounts/:id/roles/:role", authMid.RequirePermission(config.PermRoleManage), idempotent, controller.RevokeAccountRole)
+		admin.GET("/api-keys", authMid.RequirePermission(config.PermAPIKeyManage), func(c *gin.Context) {
+			controller.ListApiKeys(c, redis)
+		})
+		admin.POST("/api-keys", authMid.RequirePermission(config.PermAPIKeyManage), func(c *gin.Context) {
+			controller.CreateApiKey(c, redis)
+		})
+		admin.POST("/api-keys/:keyId/rotate", authMid.RequirePermission(config.PermAPIKeyManage), func(c *gin.Context) {
+			controller.RotateApiKey(c, redis)
+		})
+		admin.DELETE("/api-keys/:keyId", authMid.RequirePermission(config.PermAPIKeyManage), idempotent, func(c *gin.Context) {
+			controller.RevokeApiKey(c, redis)
+		})
+	}
+
+	tx := group.Group("/tx")
+	{
+		tx.GET("/:hash", controller.GetTxStatus)
+	}
+
 	auth := group.Group("/login")
+	auth.Use(rateMid.Limit(config.RateLimitPolicyLogin))
 	{
+		auth.GET("/nonce", func(c *gin.Context) {
+			controller.GetLoginNonce(c, redis)
+		})
 		auth.POST("/bsc", func(c *gin.Context) {
 			controller.LoginBSC(c, redis)
 		})
 		auth.POST("/solana", func(c *gin.Context) {
 			controller.LoginSolana(c, redis)
 		})
+		auth.POST("/refresh", func(c *gin.Context) {
+			controller.RefreshLogin(c, redis)
+		})
+	}
+
+	account := group.Group("/account")
+	account.Use(authMid.AuthMiddleware(), idempotent)
+	{
+		account.GET("/balances", func(c *gin.Context) {
+			controller.GetAccountBalances(c, redis)
+		})
+		account.GET("/bills", controller.GetAccountBills)
+		account.GET("/deposits", controller.GetAccountDeposits)
+		account.GET("/inbox", controller.GetInboxMessages)
+		account.GET("/wallets", controller.GetAccountWallets)
+		account.GET("/wallets/link-nonce", func(c *gin.Context) {
+			controller.GetLinkWalletNonce(c, redis)
+		})
+		account.POST("/wallets", func(c *gin.Context) {
+			controller.LinkAccountWallet(c, redis)
+		})
+	}
+
+	logout := group.Group("/logout")
+	{
+		logout.POST("", func(c *gin.Context) {
+			controller.Logout(c, redis)
+		})
+		logout.POST("/all", func(c *gin.Context) {
+			controller.LogoutAll(c, redis)
+		})
 	}
 
 	return router
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrRoleAlreadyGranted = errors.New("role is already granted")
+	ErrRoleNotGranted     = errors.New("role is not granted")
+	ErrSelfAdminRevoke    = errors.New("cannot revoke own admin role")
+)
+
+// rolePermissions 角色拥有的接口权限，user 角色不含任何管理权限
+var rolePermissions = map[string][]string{
+	config.RoleOperator: {config.PermTransfer, config.PermAirdrop, config.PermStake},
+	config.RoleAuditor:  {config.PermWalletRead, config.PermAuditRead, config.PermLedgerRead},
+	config.RoleAdmin: {
+		config.PermTransfer, config.PermAirdrop, config.PermStake, config.PermGovernance,
+		config.PermWalletRead, config.PermWalletExport, config.PermAuditRead, config.PermRoleManage,
+		config.PermAPIKeyManage, config.PermLedgerRead,
+	},
+}
+
+// HasPermission 判断角色集合是否拥有权限
+func HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PrincipalHasPermission API Key 按授予的 scope 判断，钱包登录按角色判断
+func PrincipalHasPermission(principal *dto.Principal, permission string) bool {
+	if principal.ApiKeyID != "" {
+		return containsString(principal.Scopes, permission)
+	}
+	return HasPermission(principal.Roles, permission)
+}
+
+// GetAccountRoles 返回账户的全部角色：默认 user、account_role 表中的角色，auth.admins 中的钱包始终拥有 admin
+func GetAccountRoles(accountID int, address string) ([]string, error) {
+	roles := []string{config.RoleUser}
+	if accountID > 0 {
+		records, err := repository.GetAccountRoles(accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			roles = append(roles, record.Role)
+		}
+	}
+	if isBootstrapAdmin(address) && !containsString(roles, config.RoleAdmin) {
+		roles = append(roles, config.RoleAdmin)
+	}
+	return roles, nil
+}
+
+func GetAccountRoleList(accountID int) (*dto.AccountRolesResponse, error) {
+	account, err := getAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := repository.GetAccountRoles(accountID)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.AccountRolesResponse{
+		AccountID:     account.AccountID,
+		WalletAddress: account.WalletAddress,
+		Roles:         make([]dto.AccountRoleInfo, 0, len(records)),
+	}
+	for _, record := range records {
+		res.Roles = append(res.Roles, dto.AccountRoleInfo{
+			Role:      record.Role,
+			GrantedBy: record.GrantedBy,
+			CreatedAt: record.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// GrantAccountRole 为账户授予角色并写入审计日志
+func GrantAccountRole(actor string, accountID int, role string) error {
+	if !isAssignableRole(role) {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	account, err := getAccountByID(accountID)
+	if err != nil {
+		return err
+	}
+	added, err := repository.AddAccountRole(&model.AccountRole{
+		AccountID: accountID,
+		Role:      role,
+		GrantedBy: actor,
+	})
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrRoleAlreadyGranted
+	}
+	return addRoleAuditLog(actor, config.AdminActionGrantRole, account, role)
+}
+
+// RevokeAccountRole 撤销账户角色并写入审计日志，不允许管理员撤销自己的 admin 角色
+func RevokeAccountRole(actor *dto.Principal, accountID int, role string) error {
+	if !isAssignableRole(role) {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	if role == config.RoleAdmin && actor.AccountID == accountID {
+		return ErrSelfAdminRevoke
+	}
+	account, err := getAccountByID(accountID)
+	if err != nil {
+		return err
+	}
+	deleted, err := repository.DeleteAccountRole(accountID, role)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrRoleNotGranted
+	}
+	return addRoleAuditLog(actor.WalletAddress, config.AdminActionRevokeRole, account, role)
+}
+
+func addRoleAuditLog(actor string, action string, account *model.Account, role string) error {
+	params, _ := json.Marshal(map[string]interface{}{
+		"role":          role,
+		"walletAddress": account.WalletAddress,
+	})
+	return repository.AddAdminAuditLog(&model.AdminAuditLog{
+		Actor:  actor,
+		Action: action,
+		Target: strconv.Itoa(account.AccountID),
+		Params: string(params),
+		Status: config.AdminAuditStatusSuccess,
+	})
+}
+
+func getAccountByID(accountID int) (*model.Account, error) {
+	account, err := repository.GetAccountByID(accountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+	}
+	return account, err
+}
+
+// isAssignableRole user 为默认角色，不需要分配
+func isAssignableRole(role string) bool {
+	return role == config.RoleOperator || role == config.RoleAdmin || role == config.RoleAuditor
+}
+
+// isBootstrapAdmin auth.admins 配置的引导管理员，用于首次部署时分配角色
+func isBootstrapAdmin(address string) bool {
+	for _, admin := range conf.AuthConfig.Admins {
+		if admin != "" && strings.EqualFold(admin, address) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"staking-interaction/utils"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrWalletAlreadyLinked = errors.New("wallet is already linked to another account")
+	ErrLinkMessageInvalid  = errors.New("link message is not bound to this account")
+	ErrUnsupportedChain    = errors.New("unsupported chain")
+)
+
+// GetOrRegisterAccount 按钱包查找账户，首次登录的钱包自动注册账户、资产和钱包关联
+func GetOrRegisterAccount(chain string, address string) (*model.Account, error) {
+	account, err := repository.GetAccount(address)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	account = &model.Account{
+		AccountName:   address,
+		WalletAddress: address,
+	}
+	if err := repository.CreateAccountWithWallet(account, chain); err != nil {
+		// 同一钱包并发首次登录时唯一索引冲突，以先注册成功的为准
+		if existing, getErr := repository.GetAccount(address); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func GetAccountWallets(accountID int) ([]dto.AccountWalletInfo, error) {
+	wallets, err := repository.GetAccountWallets(accountID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.AccountWalletInfo, 0, len(wallets))
+	for _, wallet := range wallets {
+		res = append(res, dto.AccountWalletInfo{
+			Chain:    wallet.Chain,
+			Address:  wallet.Address,
+			LinkedAt: wallet.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// AccountService 为已登录账户关联其他链的钱包，新钱包需要签名证明所有权
+type AccountService struct {
+	redis  *redis.Client
+	config *config.Config
+}
+
+func NewAccountService(redis *redis.Client) *AccountService {
+	return &AccountService{
+		redis:  redis,
+		config: config.Get(),
+	}
+}
+
+// IssueLinkNonce 下发关联钱包用的 nonce，消息 Request ID 绑定当前账户，不能用于登录
+func (s *AccountService) IssueLinkNonce(ctx context.Context, accountID int, chain string, address string) (*dto.LoginNonceResponse, error) {
+	requestID := linkRequestID(accountID)
+	switch chain {
+	case config.LoginChainBSC:
+		if address != "" {
+			if !common.IsHexAddress(address) {
+				return nil, fmt.Errorf("invalid address: %s", address)
+			}
+			address = common.HexToAddress(address).Hex()
+		}
+		siwe := s.config.AuthConfig.Siwe
+		return issueSignInNonce(ctx, s.redis, utils.SignInChainEthereum, strconv.FormatInt(siwe.ChainID, 10), siwe, address, requestID)
+	case config.LoginChainSolana:
+		if address != "" {
+			if _, err := decodeSolanaPublicKey(address); err != nil {
+				return nil, err
+			}
+		}
+		siws := s.config.AuthConfig.Siws
+		return issueSignInNonce(ctx, s.redis, utils.SignInChainSolana, siws.Cluster, siws, address, requestID)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedChain, chain)
+}
+
+// LinkWallet 校验签名消息后把钱包关联到账户，钱包已属于其他账户时拒绝
+func (s *AccountService) LinkWallet(ctx context.Context, accountID int, req dto.LinkWalletRequest) (*dto.AccountWalletInfo, error) {
+	message, err := utils.ParseSignInMessage(req.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign-in message: %w", err)
+	}
+	if message.RequestID != linkRequestID(accountID) {
+		return nil, ErrLinkMessageInvalid
+	}
+
+	var signInChain string
+	var nonceTTL time.Duration
+	switch req.Chain {
+	case config.LoginChainBSC:
+		signInChain = utils.SignInChainEthereum
+		siwe := s.config.AuthConfig.Siwe
+		nonceTTL = siwe.NonceTTL
+		if err := validateSignInMessage(message, signInChain, strconv.FormatInt(siwe.ChainID, 10), siwe, time.Now()); err != nil {
+			return nil, err
+		}
+		if !common.IsHexAddress(message.Address) || common.HexToAddress(message.Address).Hex() != message.Address {
+			return nil, fmt.Errorf("address is not a valid EIP-55 checksum address: %s", message.Address)
+		}
+		isValid, err := NewAuthBSCService(s.redis).verifyBSCToken(req.Signature, req.Message, message.Address)
+		if err != nil || !isValid {
+			return nil, fmt.Errorf("%w, error: %v", ErrInvalidSignature, err)
+		}
+	case config.LoginChainSolana:
+		signInChain = utils.SignInChainSolana
+		siws := s.config.AuthConfig.Siws
+		nonceTTL = siws.NonceTTL
+		if err := validateSignInMessage(message, signInChain, siws.Cluster, siws, time.Now()); err != nil {
+			return nil, err
+		}
+		isValid, err := NewAuthSolanaService(s.redis).verifySolanaToken(req.Message, req.Signature, message.Address)
+		if err != nil || !isValid {
+			return nil, fmt.Errorf("%w, error: %v", ErrInvalidSignature, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedChain, req.Chain)
+	}
+
+	// 签名通过后再消费 nonce
+	if err := NewLoginNonceStore(s.redis, nonceTTL).Consume(ctx, signInChain, message.Nonce); err != nil {
+		return nil, err
+	}
+
+	existing, err := repository.GetAccount(message.Address)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && existing.AccountID != accountID {
+		return nil, ErrWalletAlreadyLinked
+	}
+	if wallet, err := repository.GetAccountWallet(req.Chain, message.Address); err == nil {
+		if wallet.AccountID != accountID {
+			return nil, ErrWalletAlreadyLinked
+		}
+		return &dto.AccountWalletInfo{Chain: wallet.Chain, Address: wallet.Address, LinkedAt: wallet.CreatedAt}, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	wallet := &model.AccountWallet{
+		AccountID: accountID,
+		Chain:     req.Chain,
+		Address:   message.Address,
+	}
+	if err := repository.AddAccountWallet(wallet); err != nil {
+		// 唯一索引冲突说明钱包刚被其他账户关联
+		if _, getErr := repository.GetAccountWallet(req.Chain, message.Address); getErr == nil {
+			return nil, ErrWalletAlreadyLinked
+		}
+		return nil, err
+	}
+	return &dto.AccountWalletInfo{Chain: wallet.Chain, Address: wallet.Address, LinkedAt: wallet.CreatedAt}, nil
+}
+
+// linkRequestID 关联钱包消息的 Request ID，登录时拒绝带 Request ID 的消息，两类消息不能互用
+func linkRequestID(accountID int) string {
+	return fmt.Sprintf("link-account:%d", accountID)
+}
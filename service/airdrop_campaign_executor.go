@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"staking-interaction/common/config"
+	"staking-interaction/contracts/airdrop"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"strings"
+)
+
+// ExecuteCampaigns 推进所有未完成的空投活动，调用方需持有空投执行锁
+func (s *AirdropService) ExecuteCampaigns(ctx context.Context, conf config.AirdropConfig) error {
+	campaigns, err := repository.GetAirdropCampaignsByStatus(config.AirdropCampaignStatusPending, config.AirdropCampaignStatusRunning)
+	if err != nil {
+		return err
+	}
+	for i := range campaigns {
+		if err := s.executeCampaign(ctx, &campaigns[i], conf); err != nil {
+			return fmt.Errorf("execute campaign %d failed: %w", campaigns[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// executeCampaign 先确认在途批次的回执，再在 MaxInFlight 范围内签名并广播待发送批次，最后汇总活动状态
+func (s *AirdropService) executeCampaign(ctx context.Context, campaign *model.AirdropCampaign, conf config.AirdropConfig) error {
+	kind := airdropKindERC20
+	if campaign.Kind == config.AirdropKindBNB {
+		kind = airdropKindBNB
+	}
+	batches, err := repository.GetAirdropBatches(campaign.ID)
+	if err != nil {
+		return err
+	}
+
+	inFlight := 0
+	for i := range batches {
+		batch := &batches[i]
+		if batch.Status != config.AirdropBatchStatusSigned && batch.Status != config.AirdropBatchStatusSent && batch.Status != config.AirdropBatchStatusReview {
+			continue
+		}
+		if err := s.refreshBatch(ctx, kind, batch, conf); err != nil {
+			return err
+		}
+		if batch.Status == config.AirdropBatchStatusSigned || batch.Status == config.AirdropBatchStatusSent {
+			inFlight++
+		}
+	}
+
+	var nonce uint64
+	nonceLoaded := false
+	for i := range batches {
+		batch := &batches[i]
+		if batch.Status != config.AirdropBatchStatusPending || inFlight >= conf.MaxInFlight {
+			continue
+		}
+		if !nonceLoaded {
+			// 在途交易已在上一步重播，pending nonce 不会与其冲突
+			nonce, err = s.clientInfo.Client.PendingNonceAt(ctx, s.clientInfo.FromAddress)
+			if err != nil {
+				return fmt.Errorf("retrieve nonce failed: %w", err)
+			}
+			nonceLoaded = true
+		}
+		used, err := s.signAndSendBatch(ctx, kind, batch, nonce, conf)
+		if err != nil {
+			return err
+		}
+		if used {
+			nonce++
+			inFlight++
+		}
+	}
+
+	status := config.AirdropCampaignStatusCompleted
+	for _, batch := range batches {
+		switch batch.Status {
+		case config.AirdropBatchStatusPending, config.AirdropBatchStatusSigned, config.AirdropBatchStatusSent:
+			status = config.AirdropCampaignStatusRunning
+		case config.AirdropBatchStatusFailed, config.AirdropBatchStatusReview:
+			if status == config.AirdropCampaignStatusCompleted {
+				status = config.AirdropCampaignStatusFailed
+			}
+		}
+	}
+	if int8(status) != campaign.Status {
+		if err := repository.UpdateAirdropCampaignStatus(campaign.ID, status); err != nil {
+			return err
+		}
+		campaign.Status = int8(status)
+	}
+	return nil
+}
+
+// signAndSendBatch 预执行并签名批次交易，签名结果落库后再广播；返回 nonce 是否被占用
+func (s *AirdropService) signAndSendBatch(ctx context.Context, kind airdropKind, batch *model.AirdropBatch, nonce uint64, conf config.AirdropConfig) (bool, error) {
+	addresses, amounts, err := decodeBatch(batch)
+	if err != nil {
+		return false, err
+	}
+	var value *big.Int
+	if kind.payable {
+		value, _ = new(big.Int).SetString(batch.TotalAmount, 10)
+	}
+
+	airdropABI, err := airdrop.ContractsMetaData.GetAbi()
+	if err != nil {
+		return false, fmt.Errorf("parse airdrop abi failed: %w", err)
+	}
+	// 预执行失败不占用 nonce，批次直接标记失败等待人工重试
+	if err := s.preflightBatch(airdropABI, kind.method, addresses, amounts, value); err != nil {
+		batch.Status = config.AirdropBatchStatusFailed
+		batch.Error = truncate(err.Error(), 512)
+		batch.ErrorCode = contractErrorCode(err)
+		return false, repository.SaveAirdropBatch(batch)
+	}
+
+	contract, err := s.NewAirdropContract()
+	if err != nil {
+		return false, err
+	}
+	auth := *s.clientInfo.Auth
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+	auth.Value = value
+	auth.Context = ctx
+	auth.NoSend = true
+
+	var tx *types.Transaction
+	if kind.payable {
+		tx, err = contract.AirdropBNB(&auth, addresses, amounts)
+	} else {
+		tx, err = contract.AirdropERC20(&auth, addresses, amounts)
+	}
+	if err != nil {
+		batch.Attempts++
+		batch.Error = truncate(fmt.Sprintf("sign %s failed: %v", kind.method, err), 512)
+		if batch.Attempts >= conf.MaxAttempts {
+			batch.Status = config.AirdropBatchStatusFailed
+		}
+		return false, repository.SaveAirdropBatch(batch)
+	}
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return false, fmt.Errorf("encode signed transaction failed: %w", err)
+	}
+
+	batch.Nonce = nonce
+	batch.TxHash = tx.Hash().Hex()
+	batch.RawTx = hexutil.Encode(rawTx)
+	batch.Status = config.AirdropBatchStatusSigned
+	batch.Attempts++
+	batch.Error = ""
+	batch.ErrorCode = ""
+	if err := repository.SaveAirdropBatch(batch); err != nil {
+		// 未落库的签名交易不广播，nonce 未被占用
+		return false, err
+	}
+
+	if err := s.clientInfo.Client.SendTransaction(ctx, tx); err != nil && !isKnownTxError(err) {
+		// 保持 SIGNED 状态，下一轮按 raw_tx 重播
+		batch.Error = truncate(fmt.Sprintf("broadcast failed: %v", err), 512)
+		return true, repository.SaveAirdropBatch(batch)
+	}
+	batch.Status = config.AirdropBatchStatusSent
+	RecordOutboundTx(tx, s.clientInfo.FromAddress, kind.purpose)
+	return true, repository.SaveAirdropBatch(batch)
+}
+
+// refreshBatch 确认在途批次：已出块则记录结果；nonce 已被其他交易占用且连续多轮查不到交易时转人工核对；否则重播原交易
+func (s *AirdropService) refreshBatch(ctx context.Context, kind airdropKind, batch *model.AirdropBatch, conf config.AirdropConfig) error {
+	// 先取 nonce 再查回执，避免回执查询之后恰好出块导致误判为被替换
+	confirmedNonce, err := s.clientInfo.Client.NonceAt(ctx, s.clientInfo.FromAddress, nil)
+	if err != nil {
+		return fmt.Errorf("get confirmed nonce failed: %w", err)
+	}
+	receipt, err := s.clientInfo.Client.TransactionReceipt(ctx, common.HexToHash(batch.TxHash))
+	if err == nil {
+		applyBatchReceipt(batch, receipt)
+		return repository.SaveAirdropBatch(batch)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("get receipt of %s failed: %w", batch.TxHash, err)
+	}
+
+	// 待核对批次只等待回执，不再重播
+	if batch.Status == config.AirdropBatchStatusReview {
+		return nil
+	}
+
+	if confirmedNonce > batch.Nonce {
+		// 负载均衡后的节点可能落后，nonce 已推进但查不到回执；节点仍能查到交易时继续等待
+		_, _, err := s.clientInfo.Client.TransactionByHash(ctx, common.HexToHash(batch.TxHash))
+		if err == nil {
+			return s.resetMissedPolls(batch)
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("get transaction %s failed: %w", batch.TxHash, err)
+		}
+		// 连续多轮都查不到才认为交易被替换，且不自动重新签名，避免交易实际已上链时重复空投
+		batch.MissedPolls++
+		batch.Error = fmt.Sprintf("nonce %d used by another transaction, %s not found (%d/%d)", batch.Nonce, batch.TxHash, batch.MissedPolls, conf.DroppedPolls)
+		if batch.MissedPolls >= conf.DroppedPolls {
+			batch.Status = config.AirdropBatchStatusReview
+		}
+		return repository.SaveAirdropBatch(batch)
+	}
+	if err := s.resetMissedPolls(batch); err != nil {
+		return err
+	}
+
+	tx := new(types.Transaction)
+	rawTx, err := hexutil.Decode(batch.RawTx)
+	if err != nil {
+		return fmt.Errorf("decode raw tx of batch %d failed: %w", batch.BatchNum, err)
+	}
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return fmt.Errorf("unmarshal raw tx of batch %d failed: %w", batch.BatchNum, err)
+	}
+	if err := s.clientInfo.Client.SendTransaction(ctx, tx); err != nil && !isKnownTxError(err) {
+		batch.Error = truncate(fmt.Sprintf("rebroadcast failed: %v", err), 512)
+		return repository.SaveAirdropBatch(batch)
+	}
+	if batch.Status == config.AirdropBatchStatusSigned {
+		batch.Status = config.AirdropBatchStatusSent
+		batch.Error = ""
+		RecordOutboundTx(tx, s.clientInfo.FromAddress, kind.purpose)
+		return repository.SaveAirdropBatch(batch)
+	}
+	return nil
+}
+
+// applyBatchReceipt 按回执记录批次结果
+func applyBatchReceipt(batch *model.AirdropBatch, receipt *types.Receipt) {
+	batch.BlockNumber = receipt.BlockNumber.Uint64()
+	batch.Status = config.AirdropBatchStatusSuccess
+	batch.MissedPolls = 0
+	batch.Error = ""
+	batch.ErrorCode = ""
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		batch.Status = config.AirdropBatchStatusFailed
+		batch.Error = "transaction reverted"
+		batch.ErrorCode = ErrCodeContractRevert
+	}
+}
+
+// resetMissedPolls 查到交易后清零连续未找到次数
+func (s *AirdropService) resetMissedPolls(batch *model.AirdropBatch) error {
+	if batch.MissedPolls == 0 {
+		return nil
+	}
+	batch.MissedPolls = 0
+	batch.Error = ""
+	return repository.SaveAirdropBatch(batch)
+}
+
+func decodeBatch(batch *model.AirdropBatch) ([]common.Address, []*big.Int, error) {
+	var recipients, amounts []string
+	if err := json.Unmarshal([]byte(batch.Recipients), &recipients); err != nil {
+		return nil, nil, fmt.Errorf("decode recipients of batch %d failed: %w", batch.BatchNum, err)
+	}
+	if err := json.Unmarshal([]byte(batch.Amounts), &amounts); err != nil {
+		return nil, nil, fmt.Errorf("decode amounts of batch %d failed: %w", batch.BatchNum, err)
+	}
+	if len(recipients) != len(amounts) {
+		return nil, nil, fmt.Errorf("batch %d recipients and amounts length mismatch", batch.BatchNum)
+	}
+	addresses := make([]common.Address, 0, len(recipients))
+	values := make([]*big.Int, 0, len(amounts))
+	for i := range recipients {
+		amount, ok := new(big.Int).SetString(amounts[i], 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("batch %d has invalid amount %q", batch.BatchNum, amounts[i])
+		}
+		addresses = append(addresses, common.HexToAddress(recipients[i]))
+		values = append(values, amount)
+	}
+	return addresses, values, nil
+}
+
+// isKnownTxError 节点已收到过同一笔交易
+func isKnownTxError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"io"
+	"math/big"
+	"staking-interaction/common/config"
+	"staking-interaction/contracts/mtk"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"staking-interaction/utils"
+	"strings"
+	"time"
+)
+
+// 单次活动最多收款人数
+const maxCampaignRecipients = 10000
+
+var (
+	// ErrInsufficientBalance 热钱包余额不足以覆盖空投总额
+	ErrInsufficientBalance = errors.New("insufficient hot wallet balance")
+	// ErrBatchNotInReview 只有待核对批次可以释放
+	ErrBatchNotInReview = errors.New("batch is not waiting for review")
+	// ErrBatchTxUnresolved 原交易仍可能上链，不能释放重发
+	ErrBatchTxUnresolved = errors.New("original transaction of the batch may still be mined")
+)
+
+// RecipientError 收款人列表校验失败，整个活动不会发送
+type RecipientError struct {
+	Issues []dto.RecipientIssue
+}
+
+func (e *RecipientError) Error() string {
+	return fmt.Sprintf("recipient list has %d invalid entries", len(e.Issues))
+}
+
+// ParseRecipientsCSV 解析 address,amount 两列的 CSV，首行不是地址时视为表头跳过
+func ParseRecipientsCSV(r io.Reader) ([]dto.AirdropRecipient, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var recipients []dto.AirdropRecipient
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv failed: %w", err)
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if line == 1 && !common.IsHexAddress(strings.TrimSpace(record[0])) && !strings.HasPrefix(strings.TrimSpace(record[0]), "0x") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("csv line %d: expected address,amount", line)
+		}
+		recipients = append(recipients, dto.AirdropRecipient{
+			Address: strings.TrimSpace(record[0]),
+			Amount:  strings.TrimSpace(record[1]),
+		})
+	}
+	return recipients, nil
+}
+
+// ValidateRecipients 校验地址和金额，重复地址保留第一次出现的记录并在 duplicates 中返回
+func ValidateRecipients(recipients []dto.AirdropRecipient) (addresses []common.Address, amounts []*big.Int, duplicates []dto.RecipientIssue, err error) {
+	if len(recipients) == 0 {
+		return nil, nil, nil, &RecipientError{Issues: []dto.RecipientIssue{{Reason: "recipient list is empty"}}}
+	}
+	if len(recipients) > maxCampaignRecipients {
+		return nil, nil, nil, &RecipientError{Issues: []dto.RecipientIssue{{
+			Reason: fmt.Sprintf("too many recipients: %d > %d", len(recipients), maxCampaignRecipients),
+		}}}
+	}
+
+	var issues []dto.RecipientIssue
+	seen := make(map[common.Address]int, len(recipients))
+	for i, recipient := range recipients {
+		issue := dto.RecipientIssue{Line: i + 1, Address: recipient.Address, Amount: recipient.Amount}
+		if !common.IsHexAddress(recipient.Address) {
+			issue.Reason = "invalid address"
+			issues = append(issues, issue)
+			continue
+		}
+		addr := common.HexToAddress(recipient.Address)
+		if addr == (common.Address{}) {
+			issue.Reason = "zero address"
+			issues = append(issues, issue)
+			continue
+		}
+		amount, ok := new(big.Int).SetString(recipient.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			issue.Reason = "amount must be a positive integer in smallest unit"
+			issues = append(issues, issue)
+			continue
+		}
+		if first, exists := seen[addr]; exists {
+			issue.Reason = fmt.Sprintf("duplicate of line %d", first)
+			duplicates = append(duplicates, issue)
+			continue
+		}
+		seen[addr] = i + 1
+		addresses = append(addresses, addr)
+		amounts = append(amounts, amount)
+	}
+	if len(issues) > 0 {
+		return nil, nil, nil, &RecipientError{Issues: issues}
+	}
+	return addresses, amounts, duplicates, nil
+}
+
+// CampaignERC20 创建按收款人列表空投 airdropToken 的活动，由后台执行器发送
+func (s *AirdropService) CampaignERC20(recipients []dto.AirdropRecipient, batchSize int) (*dto.AirdropCampaignResponse, error) {
+	return s.createCampaign(airdropKindERC20, recipients, batchSize)
+}
+
+// CampaignBNB 创建按收款人列表空投 BNB 的活动，由后台执行器发送
+func (s *AirdropService) CampaignBNB(recipients []dto.AirdropRecipient, batchSize int) (*dto.AirdropCampaignResponse, error) {
+	return s.createCampaign(airdropKindBNB, recipients, batchSize)
+}
+
+func (s *AirdropService) createCampaign(kind airdropKind, recipients []dto.AirdropRecipient, batchSize int) (*dto.AirdropCampaignResponse, error) {
+	addresses, amounts, duplicates, err := ValidateRecipients(recipients)
+	if err != nil {
+		return nil, err
+	}
+	total := utils.CalculateSumOfAmounts(amounts)
+	balance, err := s.hotWalletBalance(kind)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(total) < 0 {
+		return nil, fmt.Errorf("%w: balance %s < total %s", ErrInsufficientBalance, balance, total)
+	}
+
+	now := time.Now()
+	var batches []model.AirdropBatch
+	for start := 0; start < len(addresses); start += batchSize {
+		end := start + batchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		batchRecipients := make([]string, 0, end-start)
+		batchAmounts := make([]string, 0, end-start)
+		for i := start; i < end; i++ {
+			batchRecipients = append(batchRecipients, addresses[i].Hex())
+			batchAmounts = append(batchAmounts, amounts[i].String())
+		}
+		recipientsJSON, err := json.Marshal(batchRecipients)
+		if err != nil {
+			return nil, fmt.Errorf("marshal batch recipients failed: %w", err)
+		}
+		amountsJSON, err := json.Marshal(batchAmounts)
+		if err != nil {
+			return nil, fmt.Errorf("marshal batch amounts failed: %w", err)
+		}
+		batches = append(batches, model.AirdropBatch{
+			BatchNum:    len(batches),
+			Recipients:  string(recipientsJSON),
+			Amounts:     string(amountsJSON),
+			TotalAmount: utils.CalculateSumOfAmounts(amounts[start:end]).String(),
+			Status:      config.AirdropBatchStatusPending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	campaign := &model.AirdropCampaign{
+		Kind:            kind.kind,
+		ContractAddress: airdropContractAddr.Hex(),
+		FromAddress:     s.clientInfo.FromAddress.Hex(),
+		TotalRecipients: len(addresses),
+		TotalAmount:     total.String(),
+		BatchSize:       batchSize,
+		TotalBatches:    len(batches),
+		Status:          config.AirdropCampaignStatusPending,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := repository.CreateAirdropCampaign(campaign, batches); err != nil {
+		return nil, err
+	}
+	return &dto.AirdropCampaignResponse{
+		CampaignID:      campaign.ID,
+		TotalRecipients: len(addresses),
+		TotalBatches:    len(batches),
+		TotalAmount:     total.String(),
+		Balance:         balance.String(),
+		Duplicates:      duplicates,
+	}, nil
+}
+
+// GetCampaign 查询活动及各批次的收款人、交易和状态
+func GetCampaign(id uint64) (*dto.AirdropCampaignDetail, error) {
+	campaign, err := repository.GetAirdropCampaign(id)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := repository.GetAirdropBatches(id)
+	if err != nil {
+		return nil, err
+	}
+
+	paid := new(big.Int)
+	detail := &dto.AirdropCampaignDetail{
+		ID:              campaign.ID,
+		Kind:            campaign.Kind,
+		ContractAddress: campaign.ContractAddress,
+		FromAddress:     campaign.FromAddress,
+		TotalRecipients: campaign.TotalRecipients,
+		TotalAmount:     campaign.TotalAmount,
+		Status:          campaignStatusText(campaign.Status),
+		CreatedAt:       campaign.CreatedAt,
+		UpdatedAt:       campaign.UpdatedAt,
+		Batches:         make([]dto.AirdropBatchInfo, 0, len(batches)),
+	}
+	for _, batch := range batches {
+		info := dto.AirdropBatchInfo{
+			BatchNum:    batch.BatchNum,
+			TotalAmount: batch.TotalAmount,
+			Nonce:       batch.Nonce,
+			TxHash:      batch.TxHash,
+			Status:      batchStatusText(batch.Status),
+			Attempts:    batch.Attempts,
+			BlockNumber: batch.BlockNumber,
+			Error:       batch.Error,
+			Code:        batch.ErrorCode,
+			Delivery:    deliveryStatusText(batch.DeliveryStatus),
+		}
+		if err := json.Unmarshal([]byte(batch.Recipients), &info.Recipients); err != nil {
+			return nil, fmt.Errorf("decode recipients of batch %d failed: %w", batch.BatchNum, err)
+		}
+		if err := json.Unmarshal([]byte(batch.Amounts), &info.Amounts); err != nil {
+			return nil, fmt.Errorf("decode amounts of batch %d failed: %w", batch.BatchNum, err)
+		}
+		if batch.Status == config.AirdropBatchStatusSuccess {
+			if amount, ok := new(big.Int).SetString(batch.TotalAmount, 10); ok {
+				paid.Add(paid, amount)
+			}
+		}
+		detail.Batches = append(detail.Batches, info)
+	}
+	detail.PaidAmount = paid.String()
+	return detail, nil
+}
+
+// RetryCampaign 将失败批次重置为待签名，失败批次均未上链成功，重发不会重复转账；待核对批次需逐个调用 ReleaseReviewBatch
+func RetryCampaign(id uint64) (int64, error) {
+	if _, err := repository.GetAirdropCampaign(id); err != nil {
+		return 0, err
+	}
+	return repository.ResetFailedAirdropBatches(id)
+}
+
+// ReleaseReviewBatch 核对待核对批次的原交易：已出块则按回执记录结果；确认 nonce 已被占用且节点查不到原交易时才重置为待签名，
+// 并写入审计日志。调用方需持有空投执行锁，避免与执行器同时更新批次
+func (s *AirdropService) ReleaseReviewBatch(ctx context.Context, actor string, campaignID uint64, batchNum int) (*dto.AirdropBatchReleaseResult, error) {
+	batch, err := repository.GetAirdropBatch(campaignID, batchNum)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status != config.AirdropBatchStatusReview {
+		return nil, ErrBatchNotInReview
+	}
+	result := &dto.AirdropBatchReleaseResult{BatchNum: batch.BatchNum, TxHash: batch.TxHash}
+	txHash := common.HexToHash(batch.TxHash)
+
+	confirmedNonce, err := s.clientInfo.Client.NonceAt(ctx, s.clientInfo.FromAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get confirmed nonce failed: %w", err)
+	}
+	receipt, err := s.clientInfo.Client.TransactionReceipt(ctx, txHash)
+	if err == nil {
+		applyBatchReceipt(batch, receipt)
+		if err := repository.SaveAirdropBatch(batch); err != nil {
+			return nil, err
+		}
+		result.Status = batchStatusText(batch.Status)
+		result.BlockNumber = batch.BlockNumber
+		return result, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("get receipt of %s failed: %w", batch.TxHash, err)
+	}
+	if confirmedNonce <= batch.Nonce {
+		return nil, fmt.Errorf("%w: nonce %d not used yet", ErrBatchTxUnresolved, batch.Nonce)
+	}
+	if _, _, err := s.clientInfo.Client.TransactionByHash(ctx, txHash); err == nil {
+		return nil, fmt.Errorf("%w: %s is still known to the node", ErrBatchTxUnresolved, batch.TxHash)
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("get transaction %s failed: %w", batch.TxHash, err)
+	}
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"campaignId":     campaignID,
+		"batchNum":       batchNum,
+		"txHash":         batch.TxHash,
+		"nonce":          batch.Nonce,
+		"confirmedNonce": confirmedNonce,
+	})
+	released, err := repository.ReleaseReviewAirdropBatch(campaignID, batchNum, &model.AdminAuditLog{
+		Actor:  actor,
+		Action: config.AdminActionReleaseAirdropBatch,
+		Target: fmt.Sprintf("campaign:%d/batch:%d", campaignID, batchNum),
+		Params: string(params),
+		TxHash: batch.TxHash,
+		Status: config.AdminAuditStatusSuccess,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !released {
+		return nil, ErrBatchNotInReview
+	}
+	result.Released = true
+	result.Status = batchStatusText(config.AirdropBatchStatusPending)
+	return result, nil
+}
+
+func campaignStatusText(status int8) string {
+	switch status {
+	case config.AirdropCampaignStatusPending:
+		return "pending"
+	case config.AirdropCampaignStatusRunning:
+		return "running"
+	case config.AirdropCampaignStatusCompleted:
+		return "completed"
+	case config.AirdropCampaignStatusFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+func batchStatusText(status int8) string {
+	switch status {
+	case config.AirdropBatchStatusPending:
+		return "pending"
+	case config.AirdropBatchStatusSigned:
+		return "signed"
+	case config.AirdropBatchStatusSent:
+		return "sent"
+	case config.AirdropBatchStatusSuccess:
+		return "success"
+	case config.AirdropBatchStatusFailed:
+		return "failed"
+	case config.AirdropBatchStatusReview:
+		return "review"
+	}
+	return "unknown"
+}
+
+// hotWalletBalance 查询热钱包可用于空投的余额，ERC20 为 airdropToken 余额，BNB 为原生币余额(不含 gas 预留)
+func (s *AirdropService) hotWalletBalance(kind airdropKind) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	fromAddr := s.clientInfo.FromAddress
+
+	if kind.payable {
+		balance, err := s.clientInfo.Client.BalanceAt(ctx, fromAddr, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get bnb balance failed: %w", err)
+		}
+		return balance, nil
+	}
+
+	contract, err := s.NewAirdropContract()
+	if err != nil {
+		return nil, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	tokenAddr, err := contract.AirdropToken(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("get airdrop token failed: %w", err)
+	}
+	token, err := mtk.NewContracts(tokenAddr, s.clientInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create airdrop token contract: %w", err)
+	}
+	balance, err := token.BalanceOf(callOpts, fromAddr)
+	if err != nil {
+		return nil, fmt.Errorf("get token balance failed: %w", err)
+	}
+	return balance, nil
+}
@@ -2,11 +2,11 @@ package service
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/sirupsen/logrus"
 	"math/big"
 	"staking-interaction/adapter"
@@ -19,8 +19,10 @@ import (
 )
 
 type AirdropService struct {
-	clientInfo *adapter.InitClient
-	log        *logrus.Logger
+	clientInfo     *adapter.InitClient
+	log            *logrus.Logger
+	persistWallets bool   // 随机空投时加密保存生成的钱包
+	walletCampaign string // 保存钱包时记录的活动标记
 }
 
 func NewAirdropService(
@@ -36,6 +38,13 @@ func NewAirdropService(
 var conf = config.Get()
 var airdropContractAddr = common.HexToAddress(conf.BlockchainConfig.Contracts.Airdrop)
 
+// PersistWallets 随机空投生成的钱包以 keystore 加密保存，campaign 用于之后按活动导出
+func (s *AirdropService) PersistWallets(campaign string) *AirdropService {
+	s.persistWallets = true
+	s.walletCampaign = campaign
+	return s
+}
+
 func (s *AirdropService) NewAirdropContract() (*airdrop.Contracts, error) {
 	airdropContract, err := airdrop.NewContracts(airdropContractAddr, s.clientInfo.Client)
 	if err != nil {
@@ -48,154 +57,69 @@ func (s *AirdropService) NewAirdropContract() (*airdrop.Contracts, error) {
 	return airdropContract, nil
 }
 
-func (s *AirdropService) AirdropERC20(reqCount int, reqBatchSize int, reqAmount []*big.Int) (data *dto.AirdropResponse, err error) {
-	var (
-		wg        sync.WaitGroup
-		mu        sync.Mutex // 保护response切片的并发写入
-		responses []dto.AirdropInfo
-	)
-	s.log.WithFields(map[string]interface{}{
-		"service":      "airdrop",
-		"from address": s.clientInfo.FromAddress,
-	}).Info("airdropERC20 init success")
+// airdropKind 区分 ERC20 与 BNB 空投的合约方法和交易用途
+type airdropKind struct {
+	kind    string
+	name    string
+	method  string
+	purpose string
+	payable bool
+}
 
-	contract, err := s.NewAirdropContract()
-	if err != nil {
-		return nil, fmt.Errorf("new contract failed: %v", err)
-	}
+var (
+	airdropKindERC20 = airdropKind{kind: config.AirdropKindERC20, name: "airdrop erc", method: "airdropERC20", purpose: config.TxPurposeAirdropERC20}
+	airdropKindBNB   = airdropKind{kind: config.AirdropKindBNB, name: "airdrop bnb", method: "airdropBNB", purpose: config.TxPurposeAirdropBNB, payable: true}
+)
 
+func (s *AirdropService) AirdropERC20(reqCount int, reqBatchSize int, reqAmount []*big.Int) (data *dto.AirdropResponse, err error) {
 	// generate multiple wallets
-	walletAddresses, err := GetMultiWallets(reqCount)
+	walletAddresses, generationID, err := GenerateWallets(reqCount, s.persistWallets, airdropKindERC20.purpose, s.walletCampaign)
 	if err != nil || walletAddresses == nil || len(walletAddresses) == 0 {
-		return nil, fmt.Errorf("generate wallet failed: %v", err)
+		return nil, fmt.Errorf("generate wallet failed: %w", err)
 	}
-
-	addressLen := len(walletAddresses)
-	fromAddr := s.clientInfo.FromAddress
-	ethClient := s.clientInfo.Client
-	auth := s.clientInfo.Auth
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // 设置整体超时
-	defer cancel()
-
-	initialNonce, err := ethClient.PendingNonceAt(ctx, fromAddr)
-	if err != nil {
-		return nil, fmt.Errorf("retrieve nonce failed: %v", err)
-	}
-
-	batchNum := (addressLen + reqBatchSize - 1) / reqBatchSize
-	startIndex := 0
-	currentNonce := initialNonce
-	for i := 0; i < batchNum && startIndex < addressLen; i++ {
-		endIndex := startIndex + reqBatchSize
-		if endIndex > addressLen {
-			endIndex = addressLen
-		}
-
-		batchAddress := walletAddresses[startIndex:endIndex]
-		batchAmounts := reqAmount[startIndex:endIndex]
-		batchNonce := currentNonce
-		currentNonce++
-
-		wg.Add(1)
-		go func(idx int, addresses []common.Address, amounts []*big.Int, nonce uint64) {
-			defer wg.Done()
-			batchAuth := *auth
-			batchAuth.Nonce = big.NewInt(int64(nonce))
-			batchAuth.Context = ctx
-
-			res := s.processAirdropERC20(idx, addresses, amounts, &batchAuth, contract)
-			// 线程安全地收集结果
-			mu.Lock()
-			responses = append(responses, res)
-			mu.Unlock()
-		}(i, batchAddress, batchAmounts, batchNonce)
-
-		startIndex = endIndex
-	}
-
-	done := make(chan struct{})
-	// 等待所有批次完成并返回结果
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	// 等待结果或超时
-	select {
-	case <-done:
-		successCount := 0
-		failedCount := 0
-		for _, res := range responses {
-			if res.Error == "" {
-				successCount++
-			} else {
-				failedCount++
-			}
-		}
-		resInfo := dto.AirdropResponse{
-			Msg:              "airdrop erc success!",
-			CompletedBatches: len(responses),
-			SuccessBatches:   successCount,
-			FailBatches:      failedCount,
-			Data:             responses}
-		return &resInfo, nil
-	case <-ctx.Done():
-		resInfo := dto.AirdropResponse{
-			Msg:              "airdrop erc timeout",
-			CompletedBatches: len(responses),
-			Data:             responses,
-			Error:            ctx.Err().Error(),
-		}
-		return &resInfo, nil
+	data, err = s.executeAirdrop(airdropKindERC20, walletAddresses, reqAmount, reqBatchSize)
+	if data != nil {
+		data.GenerationID = generationID
 	}
+	return data, err
 }
 
-func (s *AirdropService) processAirdropERC20(idx int, batchAddress []common.Address, batchAmounts []*big.Int, auth *bind.TransactOpts, contract *airdrop.Contracts) (response dto.AirdropInfo) {
-	fromAddr := s.clientInfo.FromAddress
-	trans, err := contract.AirdropERC20(auth, batchAddress, batchAmounts)
-	if trans == nil || err != nil {
-		return dto.AirdropInfo{
-			BatchNum:        idx,
-			Error:           fmt.Sprintf("airdroperc failed: %v", err),
-			ContractAddress: airdropContractAddr.String(),
-			FromAddress:     fromAddr,
-			WalletAddress:   batchAddress,
-		}
-	} else {
-		return dto.AirdropInfo{
-			BatchNum:        idx,
-			Hash:            trans.Hash().Hex(),
-			ContractAddress: airdropContractAddr.String(),
-			FromAddress:     fromAddr,
-			WalletAddress:   batchAddress,
-			Error:           "",
-		}
+func (s *AirdropService) AirdropBNB(reqCount int, reqBatchSize int, reqAmount []*big.Int) (data *dto.AirdropResponse, err error) {
+	// generate multiple wallets
+	walletAddresses, generationID, err := GenerateWallets(reqCount, s.persistWallets, airdropKindBNB.purpose, s.walletCampaign)
+	if err != nil || walletAddresses == nil || len(walletAddresses) == 0 {
+		return nil, fmt.Errorf("generate wallet failed: %w", err)
 	}
-
+	data, err = s.executeAirdrop(airdropKindBNB, walletAddresses, reqAmount, reqBatchSize)
+	if data != nil {
+		data.GenerationID = generationID
+	}
+	return data, err
 }
 
-func (s *AirdropService) AirdropBNB(reqCount int, reqBatchSize int, reqAmount []*big.Int) (data *dto.AirdropResponse, err error) {
+// executeAirdrop 按 batchSize 切分地址，逐批预执行、分配 nonce 并并发发送
+func (s *AirdropService) executeAirdrop(kind airdropKind, walletAddresses []common.Address, reqAmount []*big.Int, reqBatchSize int) (data *dto.AirdropResponse, err error) {
 	var (
-		responses []dto.AirdropInfo
 		wg        sync.WaitGroup
 		mu        sync.Mutex // 保护response切片的并发写入
+		responses []dto.AirdropInfo
 	)
-
 	s.log.WithFields(map[string]interface{}{
 		"service":      "airdrop",
+		"method":       kind.method,
 		"from address": s.clientInfo.FromAddress,
-	}).Info("AirdropBNB init success")
+	}).Info("airdrop init success")
 
+	if len(walletAddresses) != len(reqAmount) {
+		return nil, fmt.Errorf("addresses and amounts length mismatch: %d != %d", len(walletAddresses), len(reqAmount))
+	}
 	contract, err := s.NewAirdropContract()
 	if err != nil {
 		return nil, fmt.Errorf("new contract failed: %v", err)
 	}
-
-	// generate multiple wallets
-	walletAddresses, err := GetMultiWallets(reqCount)
-	if err != nil || walletAddresses == nil || len(walletAddresses) == 0 {
-		return nil, fmt.Errorf("generate wallet failed: %v", err)
+	airdropABI, err := airdrop.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse airdrop abi failed: %v", err)
 	}
 
 	addressLen := len(walletAddresses)
@@ -222,25 +146,36 @@ func (s *AirdropService) AirdropBNB(reqCount int, reqBatchSize int, reqAmount []
 
 		batchAddress := walletAddresses[startIndex:endIndex]
 		batchAmounts := reqAmount[startIndex:endIndex]
+
+		var batchValue *big.Int
+		if kind.payable {
+			batchValue = utils.CalculateSumOfAmounts(batchAmounts)
+		}
+		// 预执行失败的批次不分配nonce，避免nonce空洞阻塞后续批次
+		if err := s.preflightBatch(airdropABI, kind.method, batchAddress, batchAmounts, batchValue); err != nil {
+			mu.Lock()
+			responses = append(responses, s.preflightFailedInfo(i, batchAddress, err))
+			mu.Unlock()
+			startIndex = endIndex
+			continue
+		}
 		batchNonce := currentNonce
 		currentNonce++
 
 		wg.Add(1)
-		go func(idx int, addresses []common.Address, amounts []*big.Int, nonce uint64) {
+		go func(idx int, addresses []common.Address, amounts []*big.Int, nonce uint64, value *big.Int) {
 			defer wg.Done()
-			val := utils.CalculateSumOfAmounts(amounts)
-
 			batchAuth := *auth
 			batchAuth.Nonce = big.NewInt(int64(nonce))
 			batchAuth.Context = ctx
-			batchAuth.Value = val
+			batchAuth.Value = value
 
-			res := s.processAirdropBNB(idx, addresses, amounts, &batchAuth, contract)
+			res := s.processAirdropBatch(kind, idx, addresses, amounts, &batchAuth, contract)
 			// 线程安全地收集结果
 			mu.Lock()
 			responses = append(responses, res)
 			mu.Unlock()
-		}(i, batchAddress, batchAmounts, batchNonce)
+		}(i, batchAddress, batchAmounts, batchNonce, batchValue)
 
 		startIndex = endIndex
 	}
@@ -265,66 +200,80 @@ func (s *AirdropService) AirdropBNB(reqCount int, reqBatchSize int, reqAmount []
 			}
 		}
 		resInfo := dto.AirdropResponse{
-			Msg:              "airdrop bnb success!",
+			Msg:              kind.name + " success!",
 			CompletedBatches: len(responses),
 			SuccessBatches:   successCount,
 			FailBatches:      failedCount,
 			Data:             responses}
 		return &resInfo, nil
 	case <-ctx.Done():
+		mu.Lock()
+		defer mu.Unlock()
 		resInfo := dto.AirdropResponse{
-			Msg:              "airdrop bnb timeout",
+			Msg:              kind.name + " timeout",
 			CompletedBatches: len(responses),
 			Data:             responses,
 			Error:            ctx.Err().Error(),
 		}
 		return &resInfo, nil
 	}
-
 }
 
-func (s *AirdropService) processAirdropBNB(idx int, batchAddress []common.Address, batchAmounts []*big.Int, auth *bind.TransactOpts, contract *airdrop.Contracts) (response dto.AirdropInfo) {
+func (s *AirdropService) processAirdropBatch(kind airdropKind, idx int, batchAddress []common.Address, batchAmounts []*big.Int, auth *bind.TransactOpts, contract *airdrop.Contracts) (response dto.AirdropInfo) {
 	fromAddr := s.clientInfo.FromAddress
-	trans, err := contract.AirdropBNB(auth, batchAddress, batchAmounts)
-	if trans == nil || err != nil {
-		return dto.AirdropInfo{
-			BatchNum:        idx,
-			Error:           fmt.Sprintf("airdrop bnb failed: %v", err),
-			ContractAddress: airdropContractAddr.String(),
-			FromAddress:     fromAddr,
-			WalletAddress:   batchAddress,
-		}
+	var (
+		trans *types.Transaction
+		err   error
+	)
+	if kind.payable {
+		trans, err = contract.AirdropBNB(auth, batchAddress, batchAmounts)
 	} else {
+		trans, err = contract.AirdropERC20(auth, batchAddress, batchAmounts)
+	}
+	if trans == nil || err != nil {
 		return dto.AirdropInfo{
 			BatchNum:        idx,
-			Hash:            trans.Hash().Hex(),
+			Error:           fmt.Sprintf("%s failed: %v", kind.name, err),
 			ContractAddress: airdropContractAddr.String(),
 			FromAddress:     fromAddr,
 			WalletAddress:   batchAddress,
-			Error:           "",
 		}
 	}
+	RecordOutboundTx(trans, fromAddr, kind.purpose)
+	return dto.AirdropInfo{
+		BatchNum:        idx,
+		Hash:            trans.Hash().Hex(),
+		ContractAddress: airdropContractAddr.String(),
+		FromAddress:     fromAddr,
+		WalletAddress:   batchAddress,
+		Error:           "",
+	}
 }
 
-func GetMultiWallets(count int) (walletAddresses []common.Address, err error) {
-	for i := 0; i < count; i++ {
-		// 1. 生成随机私钥（secp256k1曲线）
-		privateKey, err := crypto.GenerateKey()
-		if err != nil {
-			return nil, err
-		}
-
-		// 2. 从私钥推导公钥（未压缩格式）
-		publicKey := privateKey.Public()
-		publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-		if !ok {
-			return nil, err
-		}
-
-		// 3. 从公钥推导以太坊地址（0x前缀）
-		address := crypto.PubkeyToAddress(*publicKeyECDSA)
+// preflightBatch 预执行单个空投批次
+func (s *AirdropService) preflightBatch(airdropABI *abi.ABI, method string, addresses []common.Address, amounts []*big.Int, value *big.Int) error {
+	_, err := preflight(context.Background(), s.clientInfo, contractCall{
+		To:     airdropContractAddr,
+		Value:  value,
+		ABI:    airdropABI,
+		Method: method,
+		Args:   []interface{}{addresses, amounts},
+	})
+	return err
+}
 
-		walletAddresses = append(walletAddresses, address)
+func (s *AirdropService) preflightFailedInfo(idx int, batchAddress []common.Address, err error) dto.AirdropInfo {
+	return dto.AirdropInfo{
+		BatchNum:        idx,
+		Error:           fmt.Sprintf("airdrop preflight failed: %v", err),
+		Code:            contractErrorCode(err),
+		ContractAddress: airdropContractAddr.String(),
+		FromAddress:     s.clientInfo.FromAddress,
+		WalletAddress:   batchAddress,
 	}
-	return walletAddresses, nil
+}
+
+func GetMultiWallets(count int) (walletAddresses []common.Address, err error) {
+	walletAddresses, _, err = GenerateWallets(count, false, "", "")
+	return walletAddresses, err
 }
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"sort"
+	"staking-interaction/common/config"
+	"staking-interaction/contracts/airdrop"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"strings"
+	"time"
+)
+
+// RecordAirdropDeliveries 保存 Airdropped 事件，并按交易哈希与活动批次核对
+func RecordAirdropDeliveries(events []*airdrop.ContractsAirdropped) (mismatched []string, err error) {
+	now := time.Now()
+	deliveries := make([]model.AirdropDelivery, 0, len(events))
+	var txHashes []string
+	seen := make(map[string]struct{})
+	for _, event := range events {
+		hash := event.Raw.TxHash.Hex()
+		deliveries = append(deliveries, model.AirdropDelivery{
+			TxHash:       hash,
+			LogIndex:     event.Raw.Index,
+			BlockNumber:  event.Raw.BlockNumber,
+			Address:      event.Addr.Hex(),
+			Amount:       event.Amount.String(),
+			ProcessIndex: event.ProcessIndex.String(),
+			Symbol:       event.Symbol,
+			Timestamp:    time.Unix(event.Timestamp.Int64(), 0),
+			CreatedAt:    now,
+		})
+		if _, ok := seen[hash]; !ok {
+			seen[hash] = struct{}{}
+			txHashes = append(txHashes, hash)
+		}
+	}
+	if err := repository.AddAirdropDeliveries(deliveries); err != nil {
+		return nil, err
+	}
+
+	for _, hash := range txHashes {
+		status, err := ReconcileBatchDelivery(hash)
+		if err != nil {
+			return mismatched, err
+		}
+		if status == config.AirdropDeliveryStatusMismatch {
+			mismatched = append(mismatched, hash)
+		}
+	}
+	return mismatched, nil
+}
+
+// ReconcileBatchDelivery 对比批次请求的收款人金额与链上实际投递，交易不属于任何批次时返回 UNCHECKED
+func ReconcileBatchDelivery(txHash string) (int, error) {
+	batch, err := repository.GetAirdropBatchByTxHash(txHash)
+	if err != nil || batch == nil {
+		return config.AirdropDeliveryStatusUnchecked, err
+	}
+	deliveries, err := repository.GetAirdropDeliveriesByTxHash(txHash)
+	if err != nil {
+		return config.AirdropDeliveryStatusUnchecked, err
+	}
+	addresses, amounts, err := decodeBatch(batch)
+	if err != nil {
+		return config.AirdropDeliveryStatusUnchecked, err
+	}
+
+	requested := make(map[common.Address]*big.Int, len(addresses))
+	for i, addr := range addresses {
+		if requested[addr] == nil {
+			requested[addr] = new(big.Int)
+		}
+		requested[addr].Add(requested[addr], amounts[i])
+	}
+	delivered := make(map[common.Address]*big.Int, len(deliveries))
+	for _, delivery := range deliveries {
+		addr := common.HexToAddress(delivery.Address)
+		amount, ok := new(big.Int).SetString(delivery.Amount, 10)
+		if !ok {
+			return config.AirdropDeliveryStatusUnchecked, fmt.Errorf("invalid delivery amount %q", delivery.Amount)
+		}
+		if delivered[addr] == nil {
+			delivered[addr] = new(big.Int)
+		}
+		delivered[addr].Add(delivered[addr], amount)
+	}
+
+	var problems []string
+	for addr, want := range requested {
+		got := delivered[addr]
+		switch {
+		case got == nil:
+			problems = append(problems, fmt.Sprintf("%s missing", addr.Hex()))
+		case got.Cmp(want) != 0:
+			problems = append(problems, fmt.Sprintf("%s requested %s delivered %s", addr.Hex(), want, got))
+		}
+	}
+	for addr, got := range delivered {
+		if requested[addr] == nil {
+			problems = append(problems, fmt.Sprintf("%s unexpected %s", addr.Hex(), got))
+		}
+	}
+	sort.Strings(problems)
+
+	status := config.AirdropDeliveryStatusMatched
+	if len(problems) > 0 {
+		status = config.AirdropDeliveryStatusMismatch
+	}
+	if err := repository.UpdateAirdropBatchDelivery(batch, status, truncate(strings.Join(problems, "; "), 512)); err != nil {
+		return config.AirdropDeliveryStatusUnchecked, err
+	}
+	return status, nil
+}
+
+// GetCampaignDeliveries 查询活动在链上的逐个收款人投递记录
+func GetCampaignDeliveries(id uint64) (*dto.AirdropDeliveryResponse, error) {
+	if _, err := repository.GetAirdropCampaign(id); err != nil {
+		return nil, err
+	}
+	batches, err := repository.GetAirdropBatches(id)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := repository.GetAirdropDeliveriesByCampaign(id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.AirdropDeliveryResponse{
+		CampaignID: id,
+		Batches:    make([]dto.AirdropBatchDelivery, 0, len(batches)),
+		Deliveries: make([]dto.AirdropDeliveryInfo, 0, len(deliveries)),
+	}
+	delivered := new(big.Int)
+	for _, delivery := range deliveries {
+		if amount, ok := new(big.Int).SetString(delivery.Amount, 10); ok {
+			delivered.Add(delivered, amount)
+		}
+		res.Deliveries = append(res.Deliveries, dto.AirdropDeliveryInfo{
+			TxHash:      delivery.TxHash,
+			BlockNumber: delivery.BlockNumber,
+			Address:     delivery.Address,
+			Amount:      delivery.Amount,
+			Symbol:      delivery.Symbol,
+			Timestamp:   delivery.Timestamp,
+		})
+	}
+	for _, batch := range batches {
+		res.Batches = append(res.Batches, dto.AirdropBatchDelivery{
+			BatchNum:       batch.BatchNum,
+			TxHash:         batch.TxHash,
+			DeliveryStatus: deliveryStatusText(batch.DeliveryStatus),
+			DeliveryError:  batch.DeliveryError,
+		})
+		if batch.DeliveryStatus == config.AirdropDeliveryStatusMismatch {
+			res.MismatchedBatches++
+		}
+	}
+	res.DeliveredRecipients = len(deliveries)
+	res.DeliveredAmount = delivered.String()
+	return res, nil
+}
+
+func deliveryStatusText(status int8) string {
+	switch status {
+	case config.AirdropDeliveryStatusMatched:
+		return "matched"
+	case config.AirdropDeliveryStatusMismatch:
+		return "mismatch"
+	}
+	return "unchecked"
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/common/logger"
+	"staking-interaction/contracts/airdrop"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"time"
+)
+
+var (
+	ErrInvalidGovAction    = errors.New("invalid gov action")
+	ErrInvalidGovTarget    = errors.New("invalid gov target")
+	ErrGovStateUnchanged   = errors.New("contract state already matches the requested change")
+	ErrConfirmTokenInvalid = errors.New("confirm token is invalid or expired")
+)
+
+// consumeConfirmTokenScript 令牌内容未变时删除，返回删除的 key 数，保证令牌只被消费一次
+// KEYS[1] 令牌 key，ARGV[1] 读取到的令牌内容
+var consumeConfirmTokenScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// govChange 待确认的治理操作，保存在 Redis 中
+type govChange struct {
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	Target string `json:"target"`
+}
+
+// govState 空投合约当前的治理状态
+type govState struct {
+	Owner       common.Address `json:"owner"`
+	HotWallet   common.Address `json:"hotWallet"`
+	TargetIsGov bool           `json:"targetIsGov"`
+}
+
+// AirdropGovService 管理空投合约的 gov 地址与 owner，所有变更先预览再确认，并写入审计日志
+type AirdropGovService struct {
+	clientInfo *adapter.InitClient
+	redis      *redis.Client // 为 nil 时不支持确认令牌，CLI 直接调用 ExecuteGovChange
+	log        *logrus.Logger
+}
+
+func NewAirdropGovService(clientInfo *adapter.InitClient, redis *redis.Client) *AirdropGovService {
+	return &AirdropGovService{
+		clientInfo: clientInfo,
+		redis:      redis,
+		log:        logger.GetLogger(),
+	}
+}
+
+// ListGovernors 查询 owner、已知的 gov 地址及 owner 变更历史，gov 状态以链上 isGov 为准
+func (s *AirdropGovService) ListGovernors(ctx context.Context) (*dto.AirdropGovernance, error) {
+	contract, err := airdrop.NewContracts(airdropContractAddr, s.clientInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("new airdrop contract failed: %w", err)
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	owner, err := contract.Owner(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("get airdrop owner failed: %w", err)
+	}
+	hotWallet := s.clientInfo.FromAddress
+	hotWalletIsGov, err := contract.IsGov(callOpts, hotWallet)
+	if err != nil {
+		return nil, fmt.Errorf("get hot wallet gov status failed: %w", err)
+	}
+
+	governors, err := repository.GetAirdropGovernors(airdropContractAddr.Hex())
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.AirdropGovernance{
+		Contract:       airdropContractAddr.Hex(),
+		Owner:          owner.Hex(),
+		HotWallet:      hotWallet.Hex(),
+		HotWalletIsGov: hotWalletIsGov,
+		Governors:      make([]dto.AirdropGovernorInfo, 0, len(governors)),
+	}
+	for _, governor := range governors {
+		active, err := contract.IsGov(callOpts, common.HexToAddress(governor.Address))
+		if err != nil {
+			return nil, fmt.Errorf("get gov status of %s failed: %w", governor.Address, err)
+		}
+		// 确认超时或在服务外修改的地址，以链上状态修正
+		if active != governor.Active {
+			if err := repository.UpdateAirdropGovernorActive(governor.ID, active); err != nil {
+				return nil, err
+			}
+		}
+		res.Governors = append(res.Governors, dto.AirdropGovernorInfo{
+			Address:     governor.Address,
+			Active:      active,
+			TxHash:      governor.TxHash,
+			BlockNumber: governor.BlockNumber,
+			UpdatedAt:   governor.UpdatedAt,
+		})
+	}
+
+	transfers, err := repository.GetAirdropOwnershipTransfers(airdropContractAddr.Hex(), 20)
+	if err != nil {
+		return nil, err
+	}
+	res.OwnerHistory = make([]dto.OwnershipTransferInfo, 0, len(transfers))
+	for _, transfer := range transfers {
+		res.OwnerHistory = append(res.OwnerHistory, dto.OwnershipTransferInfo{
+			PreviousOwner: transfer.PreviousOwner,
+			NewOwner:      transfer.NewOwner,
+			TxHash:        transfer.TxHash,
+			BlockNumber:   transfer.BlockNumber,
+		})
+	}
+	return res, nil
+}
+
+// PrepareGovChange 校验并预执行治理操作，返回预览和一次性确认令牌
+func (s *AirdropGovService) PrepareGovChange(ctx context.Context, actor string, action string, target string) (*dto.GovChangePreview, error) {
+	targetAddr, err := parseGovTarget(action, target)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.checkGovChange(ctx, action, targetAddr)
+	if err != nil {
+		return nil, err
+	}
+	preview := s.govPreview(action, targetAddr, state)
+	if s.redis == nil {
+		return preview, nil
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("generate confirm token failed: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	payload, err := json.Marshal(govChange{Actor: actor, Action: action, Target: preview.Target})
+	if err != nil {
+		return nil, err
+	}
+	ttl := conf.AuthConfig.ConfirmTTL
+	if err := s.redis.Set(ctx, confirmTokenKey(token), payload, ttl).Err(); err != nil {
+		return nil, fmt.Errorf("save confirm token failed: %w", err)
+	}
+	preview.ConfirmToken = token
+	preview.ExpiresAt = time.Now().Add(ttl)
+	return preview, nil
+}
+
+// ConfirmGovChange 使用确认令牌执行治理操作，令牌只能由生成它的管理员使用一次
+func (s *AirdropGovService) ConfirmGovChange(ctx context.Context, actor string, token string) (*dto.GovChangeResult, error) {
+	if s.redis == nil {
+		return nil, ErrConfirmTokenInvalid
+	}
+	key := confirmTokenKey(token)
+	payload, err := s.redis.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrConfirmTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load confirm token failed: %w", err)
+	}
+	var change govChange
+	if err := json.Unmarshal(payload, &change); err != nil {
+		return nil, fmt.Errorf("decode confirm token failed: %w", err)
+	}
+	// 先校验操作人再消费，其他管理员拿到令牌也无法使其失效
+	if change.Actor != actor {
+		return nil, ErrConfirmTokenInvalid
+	}
+	deleted, err := consumeConfirmTokenScript.Run(ctx, s.redis, []string{key}, payload).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("consume confirm token failed: %w", err)
+	}
+	// 并发确认时只有一个请求能删除成功
+	if deleted == 0 {
+		return nil, ErrConfirmTokenInvalid
+	}
+	targetAddr, err := parseGovTarget(change.Action, change.Target)
+	if err != nil {
+		return nil, err
+	}
+	return s.ExecuteGovChange(ctx, actor, change.Action, targetAddr)
+}
+
+// ExecuteGovChange 重新校验后发送治理交易并等待回执，全过程写入审计日志
+func (s *AirdropGovService) ExecuteGovChange(ctx context.Context, actor string, action string, target common.Address) (*dto.GovChangeResult, error) {
+	// 预览到确认之间链上状态可能已变化，发送前重新校验
+	state, err := s.checkGovChange(ctx, action, target)
+	if err != nil {
+		return nil, err
+	}
+	params, _ := json.Marshal(state)
+	audit := &model.AdminAuditLog{
+		Actor:  actor,
+		Action: action,
+		Target: govTargetHex(action, target),
+		Params: string(params),
+		Status: config.AdminAuditStatusPending,
+	}
+	if err := repository.AddAdminAuditLog(audit); err != nil {
+		return nil, err
+	}
+	result := &dto.GovChangeResult{AuditID: audit.ID, Action: action, Target: audit.Target, Status: "pending"}
+
+	tx, err := s.sendGovTx(ctx, action, target)
+	if err != nil {
+		s.finishAudit(audit, config.AdminAuditStatusFailed, err.Error())
+		return nil, err
+	}
+	RecordOutboundTx(tx, s.clientInfo.FromAddress, config.TxPurposeAirdropGov)
+	audit.TxHash = tx.Hash().Hex()
+	result.TxHash = audit.TxHash
+	s.finishAudit(audit, config.AdminAuditStatusPending, "")
+
+	waitCtx, cancel := context.WithTimeout(ctx, conf.BlockchainConfig.Transaction.Timeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(waitCtx, s.clientInfo.Client, tx)
+	if err != nil {
+		// 未等到回执时保持 PENDING，回执由交易跟踪器继续轮询，gov 列表查询时按链上状态修正
+		s.log.WithFields(logrus.Fields{
+			"module":   "airdrop_gov",
+			"action":   action,
+			"audit_id": audit.ID,
+			"tx_hash":  audit.TxHash,
+			"detail":   err.Error(),
+		}).Warn("Gov transaction not mined before timeout")
+		if err := s.saveGovernor(action, target, state.TargetIsGov, audit.TxHash, 0); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	result.BlockNumber = receipt.BlockNumber.Uint64()
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		result.Status = "failed"
+		s.finishAudit(audit, config.AdminAuditStatusFailed, "transaction reverted")
+		return result, nil
+	}
+	result.Status = "success"
+	s.finishAudit(audit, config.AdminAuditStatusSuccess, "")
+	if err := s.saveGovernor(action, target, action == config.GovActionSetGov, audit.TxHash, result.BlockNumber); err != nil {
+		return nil, err
+	}
+	s.log.WithFields(logrus.Fields{
+		"module":   "airdrop_gov",
+		"action":   action,
+		"actor":    actor,
+		"target":   audit.Target,
+		"audit_id": audit.ID,
+		"tx_hash":  audit.TxHash,
+	}).Info("Gov change executed")
+	return result, nil
+}
+
+// checkGovChange 校验操作是否会改变链上状态，并预执行交易
+func (s *AirdropGovService) checkGovChange(ctx context.Context, action string, target common.Address) (*govState, error) {
+	contract, err := airdrop.NewContracts(airdropContractAddr, s.clientInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("new airdrop contract failed: %w", err)
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	owner, err := contract.Owner(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("get airdrop owner failed: %w", err)
+	}
+	state := &govState{Owner: owner, HotWallet: s.clientInfo.FromAddress}
+
+	var args []interface{}
+	switch action {
+	case config.GovActionSetGov, config.GovActionRemoveGov:
+		isGov, err := contract.IsGov(callOpts, target)
+		if err != nil {
+			return nil, fmt.Errorf("get gov status of %s failed: %w", target.Hex(), err)
+		}
+		state.TargetIsGov = isGov
+		if isGov == (action == config.GovActionSetGov) {
+			return nil, ErrGovStateUnchanged
+		}
+		args = []interface{}{target}
+	case config.GovActionTransferOwnership:
+		if target == owner {
+			return nil, ErrGovStateUnchanged
+		}
+		args = []interface{}{target}
+	case config.GovActionRenounceOwnership:
+	default:
+		return nil, ErrInvalidGovAction
+	}
+
+	airdropABI, err := airdrop.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse airdrop abi failed: %w", err)
+	}
+	if _, err := preflight(ctx, s.clientInfo, contractCall{
+		To:     airdropContractAddr,
+		ABI:    airdropABI,
+		Method: govMethod(action),
+		Args:   args,
+	}); err != nil {
+		return nil, fmt.Errorf("%s preflight failed: %w", action, err)
+	}
+	return state, nil
+}
+
+func (s *AirdropGovService) sendGovTx(ctx context.Context, action string, target common.Address) (*types.Transaction, error) {
+	contract, err := airdrop.NewContracts(airdropContractAddr, s.clientInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("new airdrop contract failed: %w", err)
+	}
+	// 复制签名参数，避免与并发交易共享 nonce 和 context
+	opts := *s.clientInfo.Auth
+	opts.Context = ctx
+	opts.Nonce = nil
+
+	var tx *types.Transaction
+	switch action {
+	case config.GovActionSetGov:
+		tx, err = contract.SetGov(&opts, target)
+	case config.GovActionRemoveGov:
+		tx, err = contract.RemoveGov(&opts, target)
+	case config.GovActionTransferOwnership:
+		tx, err = contract.TransferOwnership(&opts, target)
+	case config.GovActionRenounceOwnership:
+		tx, err = contract.RenounceOwnership(&opts)
+	default:
+		return nil, ErrInvalidGovAction
+	}
+	if err != nil {
+		return nil, fmt.Errorf("send %s transaction failed: %w", action, err)
+	}
+	return tx, nil
+}
+
+func (s *AirdropGovService) govPreview(action string, target common.Address, state *govState) *dto.GovChangePreview {
+	preview := &dto.GovChangePreview{
+		Action:      action,
+		Target:      govTargetHex(action, target),
+		Contract:    airdropContractAddr.Hex(),
+		Owner:       state.Owner.Hex(),
+		HotWallet:   state.HotWallet.Hex(),
+		TargetIsGov: state.TargetIsGov,
+	}
+	switch {
+	case action == config.GovActionRenounceOwnership:
+		preview.Warning = "renouncing ownership is irreversible, gov addresses can no longer be changed"
+	case action == config.GovActionTransferOwnership && state.Owner == state.HotWallet:
+		preview.Warning = "the hot wallet will no longer be able to manage gov addresses"
+	case action == config.GovActionRemoveGov && target == state.HotWallet:
+		preview.Warning = "the hot wallet will no longer be able to send airdrops"
+	}
+	return preview
+}
+
+func (s *AirdropGovService) finishAudit(audit *model.AdminAuditLog, status int8, detail string) {
+	audit.Status = status
+	audit.Error = truncate(detail, 512)
+	if err := repository.UpdateAdminAuditLog(audit); err != nil {
+		s.log.WithFields(logrus.Fields{
+			"module":     "airdrop_gov",
+			"action":     "update_audit",
+			"error_code": "AUDIT_UPDATE_FAIL",
+			"audit_id":   audit.ID,
+			"detail":     err.Error(),
+		}).Error("Update admin audit log failed")
+	}
+}
+
+// saveGovernor 记录 set_gov/remove_gov 的目标地址，owner 变更由事件监听记录
+func (s *AirdropGovService) saveGovernor(action string, target common.Address, active bool, txHash string, blockNumber uint64) error {
+	if action != config.GovActionSetGov && action != config.GovActionRemoveGov {
+		return nil
+	}
+	return repository.UpsertAirdropGovernor(&model.AirdropGovernor{
+		Contract:    airdropContractAddr.Hex(),
+		Address:     target.Hex(),
+		Active:      active,
+		TxHash:      txHash,
+		BlockNumber: blockNumber,
+	})
+}
+
+// GetAdminAuditLogs 查询管理操作审计日志
+func GetAdminAuditLogs(action string, limit int) ([]model.AdminAuditLog, error) {
+	return repository.GetAdminAuditLogs(action, limit)
+}
+
+// parseGovTarget 校验操作类型和目标地址，renounce_ownership 不需要目标地址
+func parseGovTarget(action string, target string) (common.Address, error) {
+	switch action {
+	case config.GovActionRenounceOwnership:
+		return common.Address{}, nil
+	case config.GovActionSetGov, config.GovActionRemoveGov, config.GovActionTransferOwnership:
+		if !common.IsHexAddress(target) {
+			return common.Address{}, fmt.Errorf("%w: invalid target address %q", ErrInvalidGovTarget, target)
+		}
+		addr := common.HexToAddress(target)
+		if addr == (common.Address{}) {
+			return common.Address{}, fmt.Errorf("%w: target address must not be zero", ErrInvalidGovTarget)
+		}
+		return addr, nil
+	}
+	return common.Address{}, ErrInvalidGovAction
+}
+
+func govMethod(action string) string {
+	switch action {
+	case config.GovActionSetGov:
+		return "setGov"
+	case config.GovActionRemoveGov:
+		return "removeGov"
+	case config.GovActionTransferOwnership:
+		return "transferOwnership"
+	}
+	return "renounceOwnership"
+}
+
+func govTargetHex(action string, target common.Address) string {
+	if action == config.GovActionRenounceOwnership {
+		return ""
+	}
+	return target.Hex()
+}
+
+func confirmTokenKey(token string) string {
+	return fmt.Sprintf("admin_confirm:%s", token)
+}
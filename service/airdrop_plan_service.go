@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"staking-interaction/contracts/airdrop"
+	"staking-interaction/contracts/mtk"
+	"staking-interaction/dto"
+	"staking-interaction/utils"
+	"time"
+)
+
+// PlanAirdropERC20 随机钱包 ERC20 空投试运行
+func (s *AirdropService) PlanAirdropERC20(reqCount int, reqBatchSize int, reqAmount []*big.Int) (*dto.AirdropPlan, error) {
+	walletAddresses, err := GetMultiWallets(reqCount)
+	if err != nil || len(walletAddresses) == 0 {
+		return nil, fmt.Errorf("generate wallet failed: %v", err)
+	}
+	return s.planAirdrop(airdropKindERC20, walletAddresses, reqAmount, reqBatchSize)
+}
+
+// PlanAirdropBNB 随机钱包 BNB 空投试运行
+func (s *AirdropService) PlanAirdropBNB(reqCount int, reqBatchSize int, reqAmount []*big.Int) (*dto.AirdropPlan, error) {
+	walletAddresses, err := GetMultiWallets(reqCount)
+	if err != nil || len(walletAddresses) == 0 {
+		return nil, fmt.Errorf("generate wallet failed: %v", err)
+	}
+	return s.planAirdrop(airdropKindBNB, walletAddresses, reqAmount, reqBatchSize)
+}
+
+// PlanCampaignERC20 收款人列表 ERC20 空投试运行
+func (s *AirdropService) PlanCampaignERC20(recipients []dto.AirdropRecipient, batchSize int) (*dto.AirdropPlan, error) {
+	return s.planCampaign(airdropKindERC20, recipients, batchSize)
+}
+
+// PlanCampaignBNB 收款人列表 BNB 空投试运行
+func (s *AirdropService) PlanCampaignBNB(recipients []dto.AirdropRecipient, batchSize int) (*dto.AirdropPlan, error) {
+	return s.planCampaign(airdropKindBNB, recipients, batchSize)
+}
+
+func (s *AirdropService) planCampaign(kind airdropKind, recipients []dto.AirdropRecipient, batchSize int) (*dto.AirdropPlan, error) {
+	addresses, amounts, duplicates, err := ValidateRecipients(recipients)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planAirdrop(kind, addresses, amounts, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	plan.Duplicates = duplicates
+	return plan, nil
+}
+
+// planAirdrop 按实际发送时的方式切分批次，逐批 EstimateGas，并核对 BNB、代币余额和授权额度
+func (s *AirdropService) planAirdrop(kind airdropKind, addresses []common.Address, amounts []*big.Int, batchSize int) (*dto.AirdropPlan, error) {
+	if len(addresses) != len(amounts) {
+		return nil, fmt.Errorf("addresses and amounts length mismatch: %d != %d", len(addresses), len(amounts))
+	}
+	airdropABI, err := airdrop.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse airdrop abi failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	gasPrice, err := s.clientInfo.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggest gas price failed: %w", err)
+	}
+
+	total := utils.CalculateSumOfAmounts(amounts)
+	plan := &dto.AirdropPlan{
+		Kind:            kind.kind,
+		TotalRecipients: len(addresses),
+		TotalAmount:     total.String(),
+		BatchSize:       batchSize,
+		GasPrice:        gasPrice.String(),
+	}
+	// 预执行成功批次的 gas 与收款人数，用于估算失败批次
+	var estimatedGas, estimatedRecipients uint64
+	for start := 0; start < len(addresses); start += batchSize {
+		end := start + batchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		batchAmount := utils.CalculateSumOfAmounts(amounts[start:end])
+		var value *big.Int
+		if kind.payable {
+			value = batchAmount
+		}
+		batchPlan := dto.AirdropBatchPlan{
+			BatchNum:   len(plan.Batches),
+			Recipients: end - start,
+			Amount:     batchAmount.String(),
+		}
+		gas, err := preflight(ctx, s.clientInfo, contractCall{
+			To:     airdropContractAddr,
+			Value:  value,
+			ABI:    airdropABI,
+			Method: kind.method,
+			Args:   []interface{}{addresses[start:end], amounts[start:end]},
+		})
+		if err != nil {
+			batchPlan.Error = err.Error()
+			batchPlan.Code = contractErrorCode(err)
+			plan.FailedBatches++
+		} else {
+			estimatedGas += gas
+			estimatedRecipients += uint64(end - start)
+		}
+		batchPlan.EstimatedGas = gas
+		plan.Batches = append(plan.Batches, batchPlan)
+	}
+	plan.TotalBatches = len(plan.Batches)
+
+	// 预执行失败的批次没有 gas 估算，按成功批次的单人平均 gas 补上；全部失败时费用未知
+	plan.CostUnknown = estimatedRecipients == 0
+	if !plan.CostUnknown {
+		for i := range plan.Batches {
+			if plan.Batches[i].Error != "" {
+				plan.Batches[i].EstimatedGas = estimatedGas * uint64(plan.Batches[i].Recipients) / estimatedRecipients
+				plan.Batches[i].GasFallback = true
+			}
+			plan.EstimatedGas += plan.Batches[i].EstimatedGas
+		}
+	}
+
+	bnbRequired := new(big.Int)
+	if !plan.CostUnknown {
+		cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(plan.EstimatedGas))
+		plan.EstimatedCost = cost.String()
+		bnbRequired.Set(cost)
+	}
+	if kind.payable {
+		bnbRequired.Add(bnbRequired, total)
+	}
+	bnbBalance, err := s.clientInfo.Client.BalanceAt(ctx, s.clientInfo.FromAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get bnb balance failed: %w", err)
+	}
+	plan.BnbBalance = bnbBalance.String()
+	plan.BnbRequired = bnbRequired.String()
+	plan.BnbSufficient = !plan.CostUnknown && bnbBalance.Cmp(bnbRequired) >= 0
+
+	// BNB 空投不涉及代币
+	plan.TokenSufficient = true
+	plan.AllowanceSufficient = true
+	if !kind.payable {
+		contract, err := s.NewAirdropContract()
+		if err != nil {
+			return nil, err
+		}
+		callOpts := &bind.CallOpts{Context: ctx}
+		tokenAddr, err := contract.AirdropToken(callOpts)
+		if err != nil {
+			return nil, fmt.Errorf("get airdrop token failed: %w", err)
+		}
+		token, err := mtk.NewContracts(tokenAddr, s.clientInfo.Client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create airdrop token contract: %w", err)
+		}
+		tokenBalance, err := token.BalanceOf(callOpts, s.clientInfo.FromAddress)
+		if err != nil {
+			return nil, fmt.Errorf("get token balance failed: %w", err)
+		}
+		allowance, err := token.Allowance(callOpts, s.clientInfo.FromAddress, airdropContractAddr)
+		if err != nil {
+			return nil, fmt.Errorf("get token allowance failed: %w", err)
+		}
+		plan.TokenAddress = tokenAddr.Hex()
+		plan.TokenBalance = tokenBalance.String()
+		plan.TokenSufficient = tokenBalance.Cmp(total) >= 0
+		plan.Allowance = allowance.String()
+		plan.AllowanceSufficient = allowance.Cmp(total) >= 0
+	}
+
+	plan.Executable = plan.BnbSufficient && plan.TokenSufficient && plan.AllowanceSufficient && plan.FailedBatches == 0
+	return plan, nil
+}
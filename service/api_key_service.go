@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"net"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"staking-interaction/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApiKeyInvalid      = errors.New("api key is invalid or revoked")
+	ErrApiKeyNotFound     = errors.New("api key not found")
+	ErrApiKeySignature    = errors.New("api request signature is invalid")
+	ErrApiKeyTimestamp    = errors.New("api request timestamp is out of range")
+	ErrApiKeyNonce        = errors.New("api request nonce is invalid or already used")
+	ErrApiKeyIPDenied     = errors.New("client ip is not allowed for this api key")
+	ErrInvalidApiKeyScope = errors.New("invalid api key scope")
+	ErrInvalidAllowedIP   = errors.New("invalid allowed ip")
+	ErrApiKeyMasterKey    = errors.New("auth.api_key.master_key must be 64 hex characters")
+)
+
+// apiKeyScopes API Key 可申请的权限，管理类权限只授予钱包登录的账户
+var apiKeyScopes = []string{config.PermTransfer, config.PermAirdrop, config.PermStake}
+
+// SignedApiRequest 中间件从请求中取出的签名信息
+type SignedApiRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	Path      string
+	Body      []byte
+	ClientIP  string
+}
+
+// ApiKeyService 服务间调用的 API Key 签发、轮换和请求验签
+//
+//	api_key_nonce:<keyId>:<nonce>   已使用的请求 nonce，保留两倍时间误差窗口
+type ApiKeyService struct {
+	redis  *redis.Client
+	config config.ApiKeyConfig
+}
+
+func NewApiKeyService(redis *redis.Client) *ApiKeyService {
+	return &ApiKeyService{
+		redis:  redis,
+		config: conf.AuthConfig.ApiKey,
+	}
+}
+
+// Authenticate 校验时间戳、来源 IP、HMAC 签名和 nonce，返回 API Key 的请求身份
+func (s *ApiKeyService) Authenticate(ctx context.Context, req *SignedApiRequest) (*dto.Principal, error) {
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrApiKeyTimestamp
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew > s.config.MaxSkew || skew < -s.config.MaxSkew {
+		return nil, ErrApiKeyTimestamp
+	}
+	if len(req.Nonce) < 16 || len(req.Nonce) > 64 {
+		return nil, ErrApiKeyNonce
+	}
+
+	key, err := repository.GetApiKey(req.KeyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrApiKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.Status != config.ApiKeyStatusActive {
+		return nil, ErrApiKeyInvalid
+	}
+	if !ipAllowed(splitList(key.AllowedIPs), req.ClientIP) {
+		return nil, ErrApiKeyIPDenied
+	}
+
+	ok, err := s.verifySignature(key, req)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrApiKeySignature
+	}
+
+	// 签名通过后再占用 nonce，避免伪造请求消耗合法 nonce
+	stored, err := s.redis.SetNX(ctx, apiKeyNonceKey(key.KeyID, req.Nonce), 1, 2*s.config.MaxSkew).Result()
+	if err != nil {
+		return nil, fmt.Errorf("store api nonce failed: %w", err)
+	}
+	if !stored {
+		return nil, ErrApiKeyNonce
+	}
+
+	return &dto.Principal{
+		ApiKeyID: key.KeyID,
+		Scopes:   splitList(key.Scopes),
+	}, nil
+}
+
+// verifySignature 先用当前密钥验签，轮换宽限期内再尝试旧密钥
+func (s *ApiKeyService) verifySignature(key *model.ApiKey, req *SignedApiRequest) (bool, error) {
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return false, nil
+	}
+	secrets := []string{key.SecretEnc}
+	if key.PrevSecretEnc != "" && key.PrevSecretExpires != nil && time.Now().Before(*key.PrevSecretExpires) {
+		secrets = append(secrets, key.PrevSecretEnc)
+	}
+	for _, enc := range secrets {
+		secret, err := s.decryptSecret(enc)
+		if err != nil {
+			return false, err
+		}
+		expected, _ := hex.DecodeString(utils.ApiSignature(secret, req.Method, req.Path, req.Timestamp, req.Nonce, req.Body))
+		if hmac.Equal(signature, expected) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Create 为客户端签发 API Key，密钥只在响应中返回一次
+func (s *ApiKeyService) Create(actor string, req dto.ApiKeyCreateRequest) (*dto.ApiKeySecretResponse, error) {
+	if err := validateApiKeyScopes(req.Scopes); err != nil {
+		return nil, err
+	}
+	if err := validateAllowedIPs(req.AllowedIPs); err != nil {
+		return nil, err
+	}
+	keyID, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	keyID = "ak_" + keyID
+	secret, enc, err := s.newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &model.ApiKey{
+		KeyID:      keyID,
+		ClientName: req.ClientName,
+		SecretEnc:  enc,
+		Scopes:     strings.Join(req.Scopes, ","),
+		AllowedIPs: strings.Join(req.AllowedIPs, ","),
+		Status:     config.ApiKeyStatusActive,
+		CreatedBy:  actor,
+	}
+	if err := repository.AddApiKey(key); err != nil {
+		return nil, err
+	}
+	if err := addApiKeyAuditLog(actor, config.AdminActionCreateApiKey, key); err != nil {
+		return nil, err
+	}
+	return &dto.ApiKeySecretResponse{KeyID: keyID, Secret: secret}, nil
+}
+
+// Rotate 生成新密钥，旧密钥在 rotation_grace 内仍可签名，便于客户端切换
+func (s *ApiKeyService) Rotate(actor string, keyID string) (*dto.ApiKeySecretResponse, error) {
+	key, err := getApiKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.Status != config.ApiKeyStatusActive {
+		return nil, ErrApiKeyInvalid
+	}
+	secret, enc, err := s.newSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expires := now.Add(s.config.RotationGrace)
+	key.PrevSecretEnc = key.SecretEnc
+	key.PrevSecretExpires = &expires
+	key.SecretEnc = enc
+	key.RotatedAt = &now
+	if err := repository.UpdateApiKey(key); err != nil {
+		return nil, err
+	}
+	if err := addApiKeyAuditLog(actor, config.AdminActionRotateApiKey, key); err != nil {
+		return nil, err
+	}
+	return &dto.ApiKeySecretResponse{KeyID: keyID, Secret: secret, PrevSecretExpires: &expires}, nil
+}
+
+// Revoke 立即吊销 API Key，包括宽限期内的旧密钥
+func (s *ApiKeyService) Revoke(actor string, keyID string) error {
+	key, err := getApiKey(keyID)
+	if err != nil {
+		return err
+	}
+	if key.Status == config.ApiKeyStatusRevoked {
+		return nil
+	}
+	key.Status = config.ApiKeyStatusRevoked
+	key.PrevSecretEnc = ""
+	key.PrevSecretExpires = nil
+	if err := repository.UpdateApiKey(key); err != nil {
+		return err
+	}
+	return addApiKeyAuditLog(actor, config.AdminActionRevokeApiKey, key)
+}
+
+func (s *ApiKeyService) List() ([]dto.ApiKeyInfo, error) {
+	keys, err := repository.GetApiKeys()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.ApiKeyInfo, 0, len(keys))
+	for _, key := range keys {
+		status := "active"
+		if key.Status == config.ApiKeyStatusRevoked {
+			status = "revoked"
+		}
+		res = append(res, dto.ApiKeyInfo{
+			KeyID:             key.KeyID,
+			ClientName:        key.ClientName,
+			Scopes:            splitList(key.Scopes),
+			AllowedIPs:        splitList(key.AllowedIPs),
+			Status:            status,
+			CreatedBy:         key.CreatedBy,
+			RotatedAt:         key.RotatedAt,
+			PrevSecretExpires: key.PrevSecretExpires,
+			CreatedAt:         key.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// newSecret 生成 32 字节随机密钥，返回明文和加密后的值
+func (s *ApiKeyService) newSecret() (string, string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	enc, err := s.encryptSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, enc, nil
+}
+
+// encryptSecret AES-256-GCM 加密，结果为 base64(nonce || ciphertext)
+func (s *ApiKeyService) encryptSecret(secret string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce failed: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func (s *ApiKeyService) decryptSecret(enc string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("api secret is corrupted")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt api secret failed: %w", err)
+	}
+	return string(plain), nil
+}
+
+func (s *ApiKeyService) cipher() (cipher.AEAD, error) {
+	masterKey, err := hex.DecodeString(s.config.MasterKey)
+	if err != nil || len(masterKey) != 32 {
+		return nil, ErrApiKeyMasterKey
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func addApiKeyAuditLog(actor string, action string, key *model.ApiKey) error {
+	params, _ := json.Marshal(map[string]interface{}{
+		"clientName": key.ClientName,
+		"scopes":     key.Scopes,
+		"allowedIps": key.AllowedIPs,
+	})
+	return repository.AddAdminAuditLog(&model.AdminAuditLog{
+		Actor:  actor,
+		Action: action,
+		Target: key.KeyID,
+		Params: string(params),
+		Status: config.AdminAuditStatusSuccess,
+	})
+}
+
+func getApiKey(keyID string) (*model.ApiKey, error) {
+	key, err := repository.GetApiKey(keyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrApiKeyNotFound, keyID)
+	}
+	return key, err
+}
+
+func validateApiKeyScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !containsString(apiKeyScopes, scope) {
+			return fmt.Errorf("%w: %s", ErrInvalidApiKeyScope, scope)
+		}
+	}
+	return nil
+}
+
+func validateAllowedIPs(entries []string) error {
+	for _, entry := range entries {
+		if net.ParseIP(entry) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidAllowedIP, entry)
+		}
+	}
+	return nil
+}
+
+// ipAllowed 白名单为空时不限制来源
+func ipAllowed(entries []string, clientIP string) bool {
+	if len(entries) == 0 {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range entries {
+		if allowed := net.ParseIP(entry); allowed != nil {
+			if allowed.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate random id failed: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func apiKeyNonceKey(keyID string, nonce string) string {
+	return fmt.Sprintf("api_key_nonce:%s:%s", keyID, nonce)
+}
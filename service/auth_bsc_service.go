@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts"
@@ -15,7 +14,8 @@ import (
 	"staking-interaction/common/config"
 	"staking-interaction/common/logger"
 	"staking-interaction/dto"
-	"staking-interaction/repository"
+	"staking-interaction/utils"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -42,63 +42,82 @@ func NewAuthBSCService(redis *redis.Client) *AuthBSCService {
 	}
 }
 
-func (a *AuthBSCService) GenerateChallengeMessage(nonce string, timestamp int64) string {
-	return fmt.Sprintf(
-		"Welcome to DApp! Please sign this message to login.\nNonce: %s\nTimestamp: %d\n",
-		nonce, timestamp)
+// IssueLoginNonce 下发登录 nonce，传入地址时返回完整的 EIP-4361 待签名消息
+func (a *AuthBSCService) IssueLoginNonce(ctx context.Context, address string) (*dto.LoginNonceResponse, error) {
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address: %s", address)
+		}
+		address = common.HexToAddress(address).Hex()
+	}
+	siwe := a.config.AuthConfig.Siwe
+	return issueSignInNonce(ctx, a.redis, utils.SignInChainEthereum, strconv.FormatInt(siwe.ChainID, 10), siwe, address, "")
 }
 
-func (a *AuthBSCService) Login(signature string, address string, nonce string, timestamp int64) (string, error) {
-	// 判断nonce是否重复
-	if a.isNonceUsed(nonce) {
-		return "", fmt.Errorf("nonce is already used, nonce:%s", nonce)
+// Login 校验 EIP-4361 消息的域名、URI、链 ID、时间和签名，并消费服务端下发的 nonce
+func (a *AuthBSCService) Login(message string, signature string) (*dto.LoginResponse, error) {
+	siweMessage, err := utils.ParseSignInMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign-in message: %w", err)
 	}
-
-	// 判断timestamp是否有效
-	if !a.isTimestampValid(timestamp) {
-		return "", fmt.Errorf("timestamp is invalid, timestamp:%d, now:%d, delta:%d", timestamp, time.Now().Unix(), a.config.AuthConfig.MaxDelta)
+	siwe := a.config.AuthConfig.Siwe
+	if err := validateSignInMessage(siweMessage, utils.SignInChainEthereum, strconv.FormatInt(siwe.ChainID, 10), siwe, time.Now()); err != nil {
+		return nil, err
+	}
+	// 带 Request ID 的是关联钱包消息，不能用于登录
+	if siweMessage.RequestID != "" {
+		return nil, fmt.Errorf("sign-in message must not carry a request id")
+	}
+	// 地址使用 EIP-55 校验和格式
+	if !common.IsHexAddress(siweMessage.Address) || common.HexToAddress(siweMessage.Address).Hex() != siweMessage.Address {
+		return nil, fmt.Errorf("address is not a valid EIP-55 checksum address: %s", siweMessage.Address)
 	}
-
-	// 生成挑战消息
-	msg := a.GenerateChallengeMessage(nonce, timestamp)
 
 	//确认BSC token
-	isValid, err := a.verifyBSCToken(signature, msg, address)
+	isValid, err := a.verifyBSCToken(signature, message, siweMessage.Address)
 	if err != nil || !isValid {
-		return "", fmt.Errorf("invalid signature, error: %v", err)
+		return nil, fmt.Errorf("%w, error: %v", ErrInvalidSignature, err)
 	}
 
-	account, err := repository.GetAccount(address)
-	if err != nil || account.AccountID == 0 {
-		return "", fmt.Errorf("invalid account, error: %v", err)
+	// 签名通过后再消费 nonce
+	nonceStore := NewLoginNonceStore(a.redis, a.config.AuthConfig.Siwe.NonceTTL)
+	if err := nonceStore.Consume(context.Background(), utils.SignInChainEthereum, siweMessage.Nonce); err != nil {
+		return nil, err
 	}
 
-	// 生成publicKey, JWT token
-	publicKey, jwtToken, err := a.generateJWTToken(account.WalletAddress)
+	// 首次登录的钱包自动注册账户
+	account, err := GetOrRegisterAccount(config.LoginChainBSC, siweMessage.Address)
 	if err != nil {
-		return "", fmt.Errorf("generate jwtToken error: %v", err)
+		return nil, fmt.Errorf("get or register account failed, error: %v", err)
 	}
 
-	// 保存publicKey到config里
-	config.SetEcdsaPublicKey(a.config, publicKey)
+	// 创建会话，签发 access token 和 refresh token，会话地址为本次签名的钱包
+	return NewSessionService(a.redis).CreateSession(context.Background(), config.LoginChainBSC, siweMessage.Address, account.AccountID)
+}
 
-	// 保存jwtToken到redis里
-	if err := a.storeTokenToRedis(jwtToken, account.WalletAddress); err != nil {
-		return "", fmt.Errorf("store token to redis error: %v", err)
+// verifyBSCToken 先按 EOA 签名 ecrecover，不匹配时按合约钱包（EIP-1271/EIP-6492）校验
+func (a *AuthBSCService) verifyBSCToken(signature string, msg string, address string) (bool, error) {
+	sigBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return false, fmt.Errorf("decode signature error: %v", err)
 	}
-
-	// 更新nonce到redis里
-	a.storeNonceToRedis(nonce)
-
-	return jwtToken, nil
+	signer := common.HexToAddress(address)
+	if len(sigBytes) == crypto.SignatureLength {
+		recoveredAddress, err := a.getVerifyAddress(signature, msg)
+		if err == nil && recoveredAddress == signer {
+			return true, nil
+		}
+	}
+	return a.verifyContractSignature(signer, common.BytesToHash(accounts.TextHash([]byte(msg))), sigBytes)
 }
 
-func (a *AuthBSCService) verifyBSCToken(signature string, msg string, address string) (bool, error) {
-	recoveredAddress, err := a.getVerifyAddress(signature, msg)
+// verifyContractSignature 调用钱包合约的 isValidSignature，钱包尚未部署时按 EIP-6492 模拟部署后校验
+func (a *AuthBSCService) verifyContractSignature(signer common.Address, hash common.Hash, signature []byte) (bool, error) {
+	verifier, err := GetContractSignatureVerifier()
 	if err != nil {
-		return false, fmt.Errorf("get recovered address failed: %v", err)
+		return false, err
 	}
-	return recoveredAddress == common.HexToAddress(address), nil
+	return verifier.Verify(context.Background(), signer, hash, signature)
 }
 
 func (a *AuthBSCService) getVerifyAddress(signature string, msg string) (common.Address, error) {
@@ -111,6 +130,13 @@ func (a *AuthBSCService) getVerifyAddress(signature string, msg string) (common.
 	if err != nil {
 		return common.Address{}, fmt.Errorf("decode signature error: %v", err)
 	}
+	if len(sigBytes) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(sigBytes))
+	}
+	// 钱包 personal_sign 返回的 V 为 27/28，Ecrecover 需要 0/1
+	if sigBytes[crypto.RecoveryIDOffset] >= 27 {
+		sigBytes[crypto.RecoveryIDOffset] -= 27
+	}
 
 	// 恢复公钥
 	// 以太坊签名采用 ECDSA，可以通过签名和消息哈希反向推导出公钥（并不是所有椭圆曲线算法都能这样，但 SECP256k1 可以）
@@ -130,50 +156,31 @@ func (a *AuthBSCService) getVerifyAddress(signature string, msg string) (common.
 	return addr, nil
 }
 
-func (a *AuthBSCService) generateJWTToken(walletAddress string) (*ecdsa.PublicKey, string, error) {
-	// 生成密钥对
-	private, err := crypto.HexToECDSA(a.config.AuthConfig.EcdsaPrivateKey)
+func (a *AuthBSCService) generateJWTToken(walletAddress string, sid string) (string, error) {
+	keyManager, err := GetJWTKeyManager()
 	if err != nil {
-		return nil, "", fmt.Errorf("HexToECDSA error: %v", err)
+		return "", err
 	}
 
 	// 构造claims
 	claims := dto.CustomClaims{
 		WalletAddress: walletAddress,
+		SessionID:     sid,
+		Chain:         config.LoginChainBSC,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.config.AuthConfig.JwtExpiration)), //过期时间（当前时间+24小时）
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.config.AuthConfig.JwtExpiration)), //过期时间
 			IssuedAt:  jwt.NewNumericDate(time.Now()),                                        //签发时间（当前时间）
 		},
 	}
-	// 用 ES256 算法（ECDSA-P256）创建 token
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-
-	// 用私钥签名，得到 token 字符串
-	jwtToken, err := token.SignedString(private)
-
-	return &private.PublicKey, jwtToken, err
-}
-
-func (a *AuthBSCService) storeTokenToRedis(token string, address string) error {
-	key := fmt.Sprintf("token_bsc:%s", address)
-	a.redis.Set(context.Background(), key, token, a.config.AuthConfig.JwtExpiration)
-	return nil
-}
-
-func (a *AuthBSCService) isNonceUsed(nonce string) bool {
-	key := fmt.Sprintf("nonce:%s", nonce)
-	exist, _ := a.redis.Exists(context.Background(), key).Result()
-	return exist > 0
-}
-
-func (a *AuthBSCService) storeNonceToRedis(nonce string) {
-	key := fmt.Sprintf("nonce:%s", nonce)
-	a.redis.Set(context.Background(), key, 1, a.config.AuthConfig.JwtExpiration)
+	// 用当前 ES256 密钥（ECDSA-P256）签名，header 中带 kid
+	return keyManager.Sign(JWTAlgES256, claims)
 }
 
-// isTimestampValid 允许的时间误差窗口, 防止因为客户端和服务端之间时间不同步造成误判
-func (a *AuthBSCService) isTimestampValid(ts int64) bool {
-	now := time.Now().Unix()
-	delta := int64(a.config.MaxDelta * 60)
-	return ts <= now+delta && ts >= now-delta
+// issueAccessToken 签发绑定会话 ID 的 access token
+func (a *AuthBSCService) issueAccessToken(address string, sid string) (string, error) {
+	jwtToken, err := a.generateJWTToken(address, sid)
+	if err != nil {
+		return "", fmt.Errorf("generate jwtToken error: %v", err)
+	}
+	return jwtToken, nil
 }
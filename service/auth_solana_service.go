@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"crypto/ed25519"
-	"encoding/hex"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v4"
@@ -13,7 +12,7 @@ import (
 	"staking-interaction/common/config"
 	"staking-interaction/common/logger"
 	"staking-interaction/dto"
-	"staking-interaction/repository"
+	"staking-interaction/utils"
 	"time"
 )
 
@@ -39,106 +38,111 @@ func NewAuthSolanaService(redis *redis.Client) *AuthSolanaService {
 	}
 }
 
-func (a *AuthSolanaService) GenerateSolanaMessage(nonce string, timestamp int64) string {
-	return fmt.Sprintf("SOLANA LOGIN: %s - %d", nonce, timestamp)
+// IssueLoginNonce 下发登录 nonce，传入地址时返回完整的 SIWS 待签名消息
+func (a *AuthSolanaService) IssueLoginNonce(ctx context.Context, address string) (*dto.LoginNonceResponse, error) {
+	if address != "" {
+		if _, err := decodeSolanaPublicKey(address); err != nil {
+			return nil, err
+		}
+	}
+	siws := a.config.AuthConfig.Siws
+	return issueSignInNonce(ctx, a.redis, utils.SignInChainSolana, siws.Cluster, siws, address, "")
 }
 
-func (a *AuthSolanaService) Login(signature string, address string, nonce string, timestamp int64) (string, error) {
-	if a.isNonceUsed(nonce) {
-		return "", fmt.Errorf("nonce is already used, nonce:%s", nonce)
+// Login 校验 SIWS 消息的域名、URI、集群、时间和 ed25519 签名，并消费服务端下发的 nonce
+func (a *AuthSolanaService) Login(message string, signature string) (*dto.LoginResponse, error) {
+	siwsMessage, err := utils.ParseSignInMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sign-in message: %w", err)
 	}
-	if !a.isTimestampValid(timestamp) {
-		return "", fmt.Errorf("timestamp is invalid, timestamp:%d, now:%d, delta:%d", timestamp, time.Now().Unix(), a.config.AuthConfig.MaxDelta)
+	siws := a.config.AuthConfig.Siws
+	if err := validateSignInMessage(siwsMessage, utils.SignInChainSolana, siws.Cluster, siws, time.Now()); err != nil {
+		return nil, err
 	}
-	msg := a.GenerateSolanaMessage(nonce, timestamp)
+	// 带 Request ID 的是关联钱包消息，不能用于登录
+	if siwsMessage.RequestID != "" {
+		return nil, fmt.Errorf("sign-in message must not carry a request id")
+	}
+	address := siwsMessage.Address
 
-	isValid, err := a.verifySolanaToken(msg, signature, address)
+	isValid, err := a.verifySolanaToken(message, signature, address)
 	if err != nil {
-		return "", fmt.Errorf("verify solana token failed, signature:%s, msg:%s, address:%s, err:%s", signature, msg, address, err)
+		return nil, fmt.Errorf("%w, address:%s, err:%s", ErrInvalidSignature, address, err)
 	}
 	if !isValid {
-		return "", fmt.Errorf("invalid signature, signature:%s, msg:%s, address:%s", signature, msg, address)
+		return nil, fmt.Errorf("%w, address:%s", ErrInvalidSignature, address)
 	}
 
-	account, err := repository.GetAccount(address)
-	if err != nil || account.AccountID == 0 {
-		return "", fmt.Errorf("invalid account, error: %v, address:%s", err, address)
+	// 签名通过后再消费 nonce
+	nonceStore := NewLoginNonceStore(a.redis, siws.NonceTTL)
+	if err := nonceStore.Consume(context.Background(), utils.SignInChainSolana, siwsMessage.Nonce); err != nil {
+		return nil, err
 	}
 
-	publicKey, jwtToken, err := a.generateJWTToken(address)
-	config.SetEd25519PublicKey(a.config, publicKey)
-
+	// 首次登录的钱包自动注册账户
+	account, err := GetOrRegisterAccount(config.LoginChainSolana, address)
 	if err != nil {
-		return "", fmt.Errorf("generate jwtToken error: %v", err)
-	}
-
-	if err := a.storeTokenToRedis(jwtToken, address); err != nil {
-		return "", fmt.Errorf("store token to redis error: %v", err)
+		return nil, fmt.Errorf("get or register account failed, error: %v, address:%s", err, address)
 	}
 
-	a.storeNonceToRedis(nonce)
-	return jwtToken, nil
+	// 创建会话，签发 access token 和 refresh token
+	return NewSessionService(a.redis).CreateSession(context.Background(), config.LoginChainSolana, address, account.AccountID)
 }
 
 func (a *AuthSolanaService) verifySolanaToken(msg string, signatureB58 string, addressB58 string) (bool, error) {
 	// 解码公钥（base58转[]byte），
-	addressBytes, err := base58.Decode(addressB58)
+	publicKey, err := decodeSolanaPublicKey(addressB58)
 	if err != nil {
-		return false, fmt.Errorf("decode public key failed, error: %v", err)
+		return false, err
 	}
 	// 解码签名（base58转[]byte）
 	sigBytes, err := base58.Decode(signatureB58)
 	if err != nil {
 		return false, fmt.Errorf("decode signature failed, error: %v", err)
 	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return false, fmt.Errorf("invalid signature length: %d", len(sigBytes))
+	}
 	// ed25519验签
-	return ed25519.Verify(addressBytes, []byte(msg), sigBytes), nil
+	return ed25519.Verify(publicKey, []byte(msg), sigBytes), nil
+}
+
+// decodeSolanaPublicKey Solana 地址即 base58 编码的 ed25519 公钥
+func decodeSolanaPublicKey(addressB58 string) (ed25519.PublicKey, error) {
+	addressBytes, err := base58.Decode(addressB58)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key failed, error: %v", err)
+	}
+	if len(addressBytes) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid solana address: %s", addressB58)
+	}
+	return addressBytes, nil
 }
 
-func (a *AuthSolanaService) generateJWTToken(walletAddress string) (*ed25519.PublicKey, string, error) {
-	// 生成的ed25519密钥
-	seedBytes, err := hex.DecodeString(a.config.AuthConfig.Ed25519Seed)
+func (a *AuthSolanaService) generateJWTToken(walletAddress string, sid string) (string, error) {
+	keyManager, err := GetJWTKeyManager()
 	if err != nil {
-		return nil, "", fmt.Errorf("decode seed failed, error: %v", err)
+		return "", err
 	}
-	private := ed25519.NewKeyFromSeed(seedBytes)      // 64字节私钥
-	publicKey := private.Public().(ed25519.PublicKey) // 直接获取公钥
 
 	claims := dto.CustomClaims{
 		WalletAddress: walletAddress,
+		SessionID:     sid,
+		Chain:         config.LoginChainSolana,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.config.AuthConfig.JwtExpiration)), //过期时间（当前时间+24小时）
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.config.AuthConfig.JwtExpiration)), //过期时间
 			IssuedAt:  jwt.NewNumericDate(time.Now()),                                        //签发时间（当前时间）
 		},
 	}
-	// 创建一个使用 EdDSA 算法签名的 JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
-	// 用配置中的密钥进行签名，
-	jwtToken, err := token.SignedString(private)
-	//返回token字符串
-	return &publicKey, jwtToken, err
-}
-
-func (a *AuthSolanaService) storeTokenToRedis(token string, address string) error {
-	key := fmt.Sprintf("token_solana:%s", address)
-	a.redis.Set(context.Background(), key, token, a.config.AuthConfig.JwtExpiration)
-	return nil
-}
-
-func (a *AuthSolanaService) isNonceUsed(nonce string) bool {
-	key := fmt.Sprintf("nonce:%s", nonce)
-	exist, _ := a.redis.Exists(context.Background(), key).Result()
-	return exist > 0
+	// 用当前 EdDSA 密钥签名，header 中带 kid
+	return keyManager.Sign(JWTAlgEdDSA, claims)
 }
 
-func (a *AuthSolanaService) storeNonceToRedis(nonce string) {
-	key := fmt.Sprintf("nonce:%s", nonce)
-	a.redis.Set(context.Background(), key, 1, a.config.AuthConfig.JwtExpiration)
-}
-
-// isTimestampValid 允许的时间误差窗口, 防止因为客户端和服务端之间时间不同步造成误判
-func (a *AuthSolanaService) isTimestampValid(ts int64) bool {
-	now := time.Now().Unix()
-	delta := int64(a.config.AuthConfig.MaxDelta * 60)
-	return ts <= now+delta && ts >= now-delta
+// issueAccessToken 签发绑定会话 ID 的 access token
+func (a *AuthSolanaService) issueAccessToken(address string, sid string) (string, error) {
+	jwtToken, err := a.generateJWTToken(address, sid)
+	if err != nil {
+		return "", fmt.Errorf("generate jwtToken error: %v", err)
+	}
+	return jwtToken, nil
 }
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"math/big"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/contracts/mtk"
+	"staking-interaction/dto"
+	"staking-interaction/repository"
+	"staking-interaction/utils"
+	"time"
+)
+
+// bnbDecimals BNB 的精度固定为 18
+const bnbDecimals = 18
+
+// balanceTokenTypes 余额接口按该顺序返回
+var balanceTokenTypes = []int{config.TokenTypeBNB, config.TokenTypeMTK}
+
+type BalanceService struct {
+	redis  *redis.Client
+	config *config.Config
+}
+
+func NewBalanceService(redis *redis.Client) *BalanceService {
+	return &BalanceService{
+		redis:  redis,
+		config: config.Get(),
+	}
+}
+
+// GetAccountBalances 账户各代币的可用、冻结和待确认充值金额
+func (s *BalanceService) GetAccountBalances(ctx context.Context, accountID int) (*dto.AccountBalances, error) {
+	balances := map[int]*big.Int{}
+	asset, err := repository.GetAccountAsset(accountID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	// 没有资产记录的账户余额为 0
+	if asset != nil {
+		if balances[config.TokenTypeBNB], err = parseAssetBalance(asset.BnbBalance); err != nil {
+			return nil, err
+		}
+		if balances[config.TokenTypeMTK], err = parseAssetBalance(asset.MtkBalance); err != nil {
+			return nil, err
+		}
+	}
+
+	addresses, err := accountEVMAddresses(accountID)
+	if err != nil {
+		return nil, err
+	}
+	frozen, err := frozenWithdrawals(addresses)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := NewPendingDepositStore(s.redis).Get(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.AccountBalances{AccountID: accountID, Balances: make([]dto.TokenBalance, 0, len(balanceTokenTypes))}
+	for _, tokenType := range balanceTokenTypes {
+		decimals := s.tokenDecimals(tokenType)
+		available := new(big.Int)
+		if balances[tokenType] != nil {
+			available.Set(balances[tokenType])
+		}
+		// 提现成功后才扣减余额，处理中的提现从可用余额中扣除
+		available.Sub(available, frozen[tokenType])
+		if available.Sign() < 0 {
+			available.SetInt64(0)
+		}
+		res.Balances = append(res.Balances, dto.TokenBalance{
+			Token:          tokenNames[tokenType],
+			Decimals:       decimals,
+			Available:      utils.FormatUnits(available, decimals),
+			Frozen:         utils.FormatUnits(frozen[tokenType], decimals),
+			PendingDeposit: utils.FormatUnits(pending[tokenType], decimals),
+		})
+	}
+	return res, nil
+}
+
+// GetAdminAccountBalances 账户余额之外再查询账户关联 BSC 钱包的链上余额，单个钱包查询失败不影响其他钱包
+func (s *BalanceService) GetAdminAccountBalances(ctx context.Context, clientInfo *adapter.InitClient, accountID int) (*dto.AdminAccountBalances, error) {
+	if _, err := getAccountByID(accountID); err != nil {
+		return nil, err
+	}
+	balances, err := s.GetAccountBalances(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := accountEVMAddresses(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	// 所有钱包按同一区块查询，便于对照
+	blockNumber, err := clientInfo.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get block number failed: %w", err)
+	}
+	token, err := mtk.NewContracts(common.HexToAddress(s.config.BlockchainConfig.Contracts.Token), clientInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token contract: %w", err)
+	}
+	block := new(big.Int).SetUint64(blockNumber)
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: block}
+
+	res := &dto.AdminAccountBalances{AccountBalances: *balances, BlockNumber: blockNumber, OnChain: make([]dto.WalletOnChainBalance, 0, len(addresses))}
+	for _, address := range addresses {
+		wallet := dto.WalletOnChainBalance{Address: address}
+		addr := common.HexToAddress(address)
+		bnbBalance, err := clientInfo.Client.BalanceAt(ctx, addr, block)
+		if err != nil {
+			wallet.Error = fmt.Sprintf("get bnb balance failed: %v", err)
+			res.OnChain = append(res.OnChain, wallet)
+			continue
+		}
+		tokenBalance, err := token.BalanceOf(callOpts, addr)
+		if err != nil {
+			wallet.Error = fmt.Sprintf("get token balance failed: %v", err)
+			res.OnChain = append(res.OnChain, wallet)
+			continue
+		}
+		wallet.Balances = map[string]string{
+			tokenNames[config.TokenTypeBNB]: utils.FormatUnits(bnbBalance, s.tokenDecimals(config.TokenTypeBNB)),
+			tokenNames[config.TokenTypeMTK]: utils.FormatUnits(tokenBalance, s.tokenDecimals(config.TokenTypeMTK)),
+		}
+		res.OnChain = append(res.OnChain, wallet)
+	}
+	return res, nil
+}
+
+func (s *BalanceService) tokenDecimals(tokenType int) uint8 {
+	if tokenType == config.TokenTypeBNB {
+		return bnbDecimals
+	}
+	return s.config.BlockchainConfig.Contracts.TokenDecimals
+}
+
+// accountEVMAddresses 账户关联的 BSC 钱包，包含注册时记录在 account 表的钱包
+func accountEVMAddresses(accountID int) ([]string, error) {
+	wallets, err := repository.GetAccountWallets(accountID)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	for _, wallet := range wallets {
+		if wallet.Chain == config.LoginChainBSC {
+			addresses = append(addresses, wallet.Address)
+		}
+	}
+	account, err := repository.GetAccountByID(accountID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if account != nil && common.IsHexAddress(account.WalletAddress) && !containsString(addresses, account.WalletAddress) {
+		addresses = append(addresses, account.WalletAddress)
+	}
+	return addresses, nil
+}
+
+// frozenWithdrawals 处理中的提现金额，tokenType -> 金额
+func frozenWithdrawals(addresses []string) (map[int]*big.Int, error) {
+	withdrawals, err := repository.GetWithdrawalsByAddresses(addresses, []int{config.WithdrawStatusInit, config.WithdrawStatusPending})
+	if err != nil {
+		return nil, err
+	}
+	frozen := make(map[int]*big.Int)
+	for _, tokenType := range balanceTokenTypes {
+		frozen[tokenType] = new(big.Int)
+	}
+	for _, withdrawal := range withdrawals {
+		amount, err := parseAssetBalance(withdrawal.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if frozen[withdrawal.TokenType] == nil {
+			frozen[withdrawal.TokenType] = new(big.Int)
+		}
+		frozen[withdrawal.TokenType].Add(frozen[withdrawal.TokenType], amount)
+	}
+	return frozen, nil
+}
+
+// parseAssetBalance 余额字段默认值为空字符串，按 0 处理
+func parseAssetBalance(value string) (*big.Int, error) {
+	if value == "" {
+		return new(big.Int), nil
+	}
+	return utils.StringToBigInt(value)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"math/big"
+	"staking-interaction/adapter"
+	"staking-interaction/contracts/airdrop"
+	"staking-interaction/contracts/mtk"
+	"staking-interaction/contracts/stake"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 合约预执行错误码
+const (
+	ErrCodeContractRevert    = "CONTRACT_REVERT"
+	ErrCodeInsufficientFunds = "INSUFFICIENT_FUNDS"
+	ErrCodeSimulationFailed  = "SIMULATION_FAILED" // 节点调用失败，不是合约 revert
+)
+
+// ContractError 预执行失败时返回给调用方的结构化错误，交易不会被广播
+type ContractError struct {
+	Code   string `json:"code"`
+	Method string `json:"method"`
+	Reason string `json:"reason"`
+}
+
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("%s: %s simulation failed: %s", e.Code, e.Method, e.Reason)
+}
+
+// contractCall 描述一次待发送的合约写操作
+type contractCall struct {
+	To     common.Address
+	Value  *big.Int
+	ABI    *abi.ABI // 为 nil 时表示普通转账
+	Method string
+	Args   []interface{}
+}
+
+var (
+	contractABIs     []*abi.ABI
+	contractABIsOnce sync.Once
+)
+
+// knownABIs 用于解码自定义错误的合约 ABI 集合，合约间调用时 revert 可能来自任意一个合约
+func knownABIs() []*abi.ABI {
+	contractABIsOnce.Do(func() {
+		for _, meta := range []interface {
+			GetAbi() (*abi.ABI, error)
+		}{stake.ContractsMetaData, airdrop.ContractsMetaData, mtk.ContractsMetaData} {
+			if parsed, err := meta.GetAbi(); err == nil {
+				contractABIs = append(contractABIs, parsed)
+			}
+		}
+	})
+	return contractABIs
+}
+
+// preflight 基于 pending 状态执行 eth_call 和 EstimateGas，预执行失败时返回 *ContractError
+func preflight(ctx context.Context, clientInfo *adapter.InitClient, call contractCall) (uint64, error) {
+	var data []byte
+	if call.ABI != nil {
+		packed, err := call.ABI.Pack(call.Method, call.Args...)
+		if err != nil {
+			return 0, fmt.Errorf("pack %s calldata failed: %w", call.Method, err)
+		}
+		data = packed
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	msg := ethereum.CallMsg{
+		From:  clientInfo.FromAddress,
+		To:    &call.To,
+		Value: call.Value,
+		Data:  data,
+	}
+	if _, err := clientInfo.Client.PendingCallContract(ctx, msg); err != nil {
+		return 0, newContractError(call.Method, err)
+	}
+	gas, err := clientInfo.Client.EstimateGasAtBlock(ctx, msg, big.NewInt(int64(rpc.PendingBlockNumber)))
+	if err != nil {
+		return 0, newContractError(call.Method, err)
+	}
+	return gas, nil
+}
+
+func newContractError(method string, err error) *ContractError {
+	if method == "" {
+		method = "transfer"
+	}
+	if data, ok := revertData(err); ok {
+		return &ContractError{Code: ErrCodeContractRevert, Method: method, Reason: decodeRevert(data)}
+	}
+	if strings.Contains(strings.ToLower(err.Error()), "insufficient funds") {
+		return &ContractError{Code: ErrCodeInsufficientFunds, Method: method, Reason: err.Error()}
+	}
+	if strings.Contains(err.Error(), "execution reverted") {
+		return &ContractError{Code: ErrCodeContractRevert, Method: method, Reason: err.Error()}
+	}
+	return &ContractError{Code: ErrCodeSimulationFailed, Method: method, Reason: err.Error()}
+}
+
+// contractErrorCode 返回预执行错误码，非预执行错误返回空字符串
+func contractErrorCode(err error) string {
+	var contractErr *ContractError
+	if errors.As(err, &contractErr) {
+		return contractErr.Code
+	}
+	return ""
+}
+
+// revertData 从 RPC 错误中提取 revert 返回数据
+func revertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil || len(data) < 4 {
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeRevert 解码 Error(string)、Panic(uint256) 及合约 ABI 中声明的自定义错误
+func decodeRevert(data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	for _, parsed := range knownABIs() {
+		for name, abiErr := range parsed.Errors {
+			if string(abiErr.ID[:4]) != string(data[:4]) {
+				continue
+			}
+			unpacked, err := abiErr.Unpack(data)
+			if err != nil {
+				return name
+			}
+			values, _ := unpacked.([]interface{})
+			args := make([]string, 0, len(values))
+			for _, v := range values {
+				args = append(args, fmt.Sprintf("%v", v))
+			}
+			return fmt.Sprintf("%s(%s)", name, strings.Join(args, ", "))
+		}
+	}
+	return fmt.Sprintf("unknown revert: %s", hexutil.Encode(data))
+}
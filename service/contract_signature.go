@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"strings"
+	"sync"
+	"time"
+)
+
+const erc1271ABIJson = `[{"inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	// erc1271MagicValue isValidSignature(bytes32,bytes) 的函数选择器，签名有效时合约返回该值
+	erc1271MagicValue = hexutil.MustDecode("0x1626ba7e")
+	// erc6492MagicSuffix EIP-6492 包装签名的固定后缀
+	erc6492MagicSuffix = hexutil.MustDecode("0x6492649264926492649264926492649264926492649264926492649264926492")
+	// erc6492Unwrap 包装签名的内容 abi.encode(address factory, bytes factoryCalldata, bytes signature)
+	erc6492Unwrap = abi.Arguments{
+		{Type: mustNewABIType("address")},
+		{Type: mustNewABIType("bytes")},
+		{Type: mustNewABIType("bytes")},
+	}
+)
+
+// counterfactualValidatorCode 在一次 eth_call 中先调用工厂部署钱包，再调用 isValidSignature，返回 64 字节：
+// [0:32] isValidSignature 返回值，[32:64] staticcall 是否成功。
+// 代码之后拼接参数：signer(32) | factory(32) | len(factoryCalldata)(32) | factoryCalldata | isValidSignature calldata
+//
+//	PUSH1 0x3c DUP1 CODESIZE SUB SWAP1 PUSH1 0 CODECOPY                      // 参数复制到 memory[0:]
+//	PUSH1 0 PUSH1 0 PUSH1 0x40 MLOAD PUSH1 0x60 PUSH1 0 PUSH1 0x20 MLOAD GAS CALL POP
+//	PUSH1 0x20 PUSH1 0 (PUSH1 0x60 PUSH1 0x40 MLOAD ADD) (PUSH1 0x3c CODESIZE SUB) SUB
+//	(PUSH1 0x60 PUSH1 0x40 MLOAD ADD) PUSH1 0 MLOAD GAS STATICCALL
+//	PUSH1 0x20 MSTORE PUSH1 0x40 PUSH1 0 RETURN
+var counterfactualValidatorCode = hexutil.MustDecode("0x603c8038039060003960006000604051606060006020515af15060206000606060405101603c3803036060604051016000515afa60205260406000f3")
+
+// ContractSignatureVerifier 校验合约钱包（Safe 等）的签名，支持已部署钱包的 EIP-1271 和未部署钱包的 EIP-6492
+type ContractSignatureVerifier struct {
+	caller bind.ContractCaller
+	abi    abi.ABI
+}
+
+var (
+	contractSignatureVerifier     *ContractSignatureVerifier
+	contractSignatureVerifierOnce sync.Once
+	contractSignatureVerifierErr  error
+)
+
+// GetContractSignatureVerifier 返回进程内共享的校验器，所有请求复用同一个 RPC 客户端
+func GetContractSignatureVerifier() (*ContractSignatureVerifier, error) {
+	contractSignatureVerifierOnce.Do(func() {
+		client, err := ethclient.Dial(conf.BlockchainConfig.RpcURL)
+		if err != nil {
+			contractSignatureVerifierErr = fmt.Errorf("dial rpc for contract signature failed: %w", err)
+			return
+		}
+		contractSignatureVerifier, contractSignatureVerifierErr = NewContractSignatureVerifier(client)
+	})
+	return contractSignatureVerifier, contractSignatureVerifierErr
+}
+
+// NewContractSignatureVerifier caller 可以是 ethclient 或 simulated backend 的客户端
+func NewContractSignatureVerifier(caller bind.ContractCaller) (*ContractSignatureVerifier, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc1271ABIJson))
+	if err != nil {
+		return nil, err
+	}
+	return &ContractSignatureVerifier{caller: caller, abi: parsed}, nil
+}
+
+// Verify 校验 signer 对 hash 的签名，只有 signer 已部署或签名为 EIP-6492 格式时才调用合约，其余直接返回 false
+func (v *ContractSignatureVerifier) Verify(ctx context.Context, signer common.Address, hash common.Hash, signature []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	code, err := v.caller.CodeAt(ctx, signer, nil)
+	if err != nil {
+		return false, fmt.Errorf("get code of %s failed: %w", signer.Hex(), err)
+	}
+
+	if !IsERC6492Signature(signature) {
+		if len(code) == 0 {
+			return false, nil
+		}
+		return v.isValidSignature(ctx, signer, hash, signature)
+	}
+
+	factory, factoryCalldata, inner, err := unwrapERC6492Signature(signature)
+	if err != nil {
+		return false, err
+	}
+	// 钱包已部署时直接使用内层签名
+	if len(code) > 0 {
+		return v.isValidSignature(ctx, signer, hash, inner)
+	}
+	return v.isValidCounterfactual(ctx, signer, hash, factory, factoryCalldata, inner)
+}
+
+func (v *ContractSignatureVerifier) isValidSignature(ctx context.Context, signer common.Address, hash common.Hash, signature []byte) (bool, error) {
+	data, err := v.abi.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, fmt.Errorf("pack isValidSignature failed: %w", err)
+	}
+	out, err := v.caller.CallContract(ctx, ethereum.CallMsg{To: &signer, Data: data}, nil)
+	if err != nil {
+		// 合约未实现 EIP-1271 或 revert 均视为签名无效
+		return false, nil
+	}
+	return len(out) >= 4 && bytes.Equal(out[:4], erc1271MagicValue), nil
+}
+
+// isValidCounterfactual 通过不带 to 的 eth_call 执行校验代码，模拟部署后再校验，不会真正上链
+func (v *ContractSignatureVerifier) isValidCounterfactual(ctx context.Context, signer common.Address, hash common.Hash, factory common.Address, factoryCalldata []byte, signature []byte) (bool, error) {
+	validation, err := v.abi.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, fmt.Errorf("pack isValidSignature failed: %w", err)
+	}
+	length := make([]byte, 32)
+	binary.BigEndian.PutUint64(length[24:], uint64(len(factoryCalldata)))
+
+	data := make([]byte, 0, len(counterfactualValidatorCode)+96+len(factoryCalldata)+len(validation))
+	data = append(data, counterfactualValidatorCode...)
+	data = append(data, common.LeftPadBytes(signer.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(factory.Bytes(), 32)...)
+	data = append(data, length...)
+	data = append(data, factoryCalldata...)
+	data = append(data, validation...)
+
+	out, err := v.caller.CallContract(ctx, ethereum.CallMsg{Data: data}, nil)
+	if err != nil {
+		return false, fmt.Errorf("simulate counterfactual validation failed: %w", err)
+	}
+	if len(out) != 64 || out[63] != 1 {
+		return false, nil
+	}
+	return bytes.Equal(out[:4], erc1271MagicValue), nil
+}
+
+// IsERC6492Signature 判断签名是否带 EIP-6492 后缀
+func IsERC6492Signature(signature []byte) bool {
+	return len(signature) > len(erc6492MagicSuffix) && bytes.HasSuffix(signature, erc6492MagicSuffix)
+}
+
+func unwrapERC6492Signature(signature []byte) (common.Address, []byte, []byte, error) {
+	values, err := erc6492Unwrap.Unpack(signature[:len(signature)-len(erc6492MagicSuffix)])
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("decode EIP-6492 signature failed: %w", err)
+	}
+	return values[0].(common.Address), values[1].([]byte), values[2].([]byte), nil
+}
+
+func mustNewABIType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+// wallet1271Runtime 最小 EIP-1271 钱包：ecrecover(hash, v, r, s) 等于 owner 时返回 0x1626ba7e，否则返回 0
+//
+//	hash -> mem[0x00]，v（签名第 65 字节）-> mem[0x20]，r -> mem[0x40]，s -> mem[0x60]
+//	STATICCALL ecrecover，结果写入 mem[0x80]
+//	mstore(0, eq(mload(0x80), owner) * (0x1626ba7e << 224))，return(0, 32)
+func wallet1271Runtime(owner common.Address) []byte {
+	code := hexutil.MustDecode("0x60043560005260a43560001a602052606435604052608435606052602060806080600060015afa5060805173")
+	code = append(code, owner.Bytes()...)
+	return append(code, hexutil.MustDecode("0x14631626ba7e60e01b0260005260206000f3")...)
+}
+
+// initCode 构造函数只把 runtime 原样返回：PUSH1 len DUP1 PUSH1 0x0b PUSH1 0 CODECOPY PUSH1 0 RETURN
+func initCode(runtime []byte) []byte {
+	code := []byte{0x60, byte(len(runtime)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	return append(code, runtime...)
+}
+
+// walletFactoryRuntime EIP-6492 工厂：以 calldata 前 32 字节为 salt，CREATE2 部署内嵌的钱包 init code 并返回地址
+func walletFactoryRuntime(walletInit []byte) []byte {
+	n := byte(len(walletInit))
+	code := []byte{
+		0x60, n, 0x60, 0x19, 0x60, 0x00, 0x39, // CODECOPY(0, 0x19, n)，0x19 为本段代码长度
+		0x60, 0x00, 0x35, // salt
+		0x60, n, 0x60, 0x00, 0x60, 0x00, 0xf5, // CREATE2(0, 0, n, salt)
+		0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3, // return address
+	}
+	return append(code, walletInit...)
+}
+
+type contractSignatureEnv struct {
+	backend  *backends.SimulatedBackend
+	verifier *ContractSignatureVerifier
+	owner    *ecdsa.PrivateKey
+	other    *ecdsa.PrivateKey
+	deployed common.Address
+	factory  common.Address
+	salt     common.Hash
+	// counterfactual 工厂按 salt 部署后的地址，测试中始终未部署
+	counterfactual common.Address
+}
+
+func newContractSignatureEnv(t *testing.T) *contractSignatureEnv {
+	t.Helper()
+	deployer, _ := crypto.GenerateKey()
+	owner, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	deployerAddr := crypto.PubkeyToAddress(deployer.PublicKey)
+
+	backend := backends.NewSimulatedBackend(types.GenesisAlloc{
+		deployerAddr: {Balance: new(big.Int).Lsh(big.NewInt(1), 100)},
+	}, 30_000_000)
+	t.Cleanup(func() { backend.Close() })
+
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(deployer, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletInit := initCode(wallet1271Runtime(crypto.PubkeyToAddress(owner.PublicKey)))
+	deploy := func(code []byte) common.Address {
+		addr, _, _, err := bind.DeployContract(opts, abi.ABI{}, code, backend)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backend.Commit()
+		return addr
+	}
+	deployed := deploy(walletInit)
+	factory := deploy(initCode(walletFactoryRuntime(walletInit)))
+
+	verifier, err := NewContractSignatureVerifier(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := common.HexToHash("0x6492")
+	return &contractSignatureEnv{
+		backend:        backend,
+		verifier:       verifier,
+		owner:          owner,
+		other:          other,
+		deployed:       deployed,
+		factory:        factory,
+		salt:           salt,
+		counterfactual: crypto.CreateAddress2(factory, salt, crypto.Keccak256(walletInit)),
+	}
+}
+
+// personalSign 与钱包 personal_sign 一致，V 为 27/28
+func personalSign(t *testing.T, key *ecdsa.PrivateKey, msg string) []byte {
+	t.Helper()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig
+}
+
+func (e *contractSignatureEnv) wrap6492(t *testing.T, sig []byte) []byte {
+	t.Helper()
+	wrapped, err := erc6492Unwrap.Pack(e.factory, e.salt.Bytes(), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(wrapped, erc6492MagicSuffix...)
+}
+
+func TestContractSignatureVerifier(t *testing.T) {
+	env := newContractSignatureEnv(t)
+	msg := "staking-interaction wants you to sign in"
+	hash := common.BytesToHash(accounts.TextHash([]byte(msg)))
+	ownerSig := personalSign(t, env.owner, msg)
+	otherSig := personalSign(t, env.other, msg)
+
+	if code, err := env.backend.CodeAt(context.Background(), env.counterfactual, nil); err != nil || len(code) != 0 {
+		t.Fatalf("counterfactual wallet should not be deployed, code=%x err=%v", code, err)
+	}
+
+	tests := []struct {
+		name      string
+		signer    common.Address
+		signature []byte
+		want      bool
+	}{
+		{name: "deployed wallet valid", signer: env.deployed, signature: ownerSig, want: true},
+		{name: "deployed wallet invalid", signer: env.deployed, signature: otherSig, want: false},
+		{name: "deployed wallet wrapped in 6492", signer: env.deployed, signature: env.wrap6492(t, ownerSig), want: true},
+		{name: "undeployed wallet without 6492", signer: env.counterfactual, signature: ownerSig, want: false},
+		{name: "counterfactual wallet valid", signer: env.counterfactual, signature: env.wrap6492(t, ownerSig), want: true},
+		{name: "counterfactual wallet invalid", signer: env.counterfactual, signature: env.wrap6492(t, otherSig), want: false},
+		{name: "deployed wallet wrapped invalid", signer: env.deployed, signature: env.wrap6492(t, otherSig), want: false},
+		// 工厂部署出的地址与 signer 不一致
+		{name: "factory does not deploy signer", signer: common.HexToAddress("0x6492"), signature: env.wrap6492(t, ownerSig), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := env.verifier.Verify(context.Background(), tt.signer, hash, tt.signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// eth_call 只是模拟部署，校验之后钱包仍未部署
+	if code, _ := env.backend.CodeAt(context.Background(), env.counterfactual, nil); len(code) != 0 {
+		t.Fatal("counterfactual validation must not deploy the wallet")
+	}
+}
+
+func TestIsERC6492Signature(t *testing.T) {
+	if IsERC6492Signature(erc6492MagicSuffix) {
+		t.Fatal("bare magic suffix is not a wrapped signature")
+	}
+	if !IsERC6492Signature(append([]byte{0x01}, erc6492MagicSuffix...)) {
+		t.Fatal("signature with magic suffix should be detected")
+	}
+	if IsERC6492Signature(make([]byte, 65)) {
+		t.Fatal("plain signature should not be detected")
+	}
+}
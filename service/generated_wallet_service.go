@@ -0,0 +1,194 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"time"
+)
+
+const (
+	minExportPasswordLen = 8
+	maxExportWallets     = 10000
+)
+
+var (
+	ErrWalletPersistDisabled = errors.New("wallet keystore password is not configured")
+	ErrExportPasswordTooWeak = fmt.Errorf("export password should be at least %d characters", minExportPasswordLen)
+	ErrNoWalletsToExport     = errors.New("no generated wallets match the filter")
+	ErrExportFilterRequired  = errors.New("generationId or campaign is required")
+)
+
+// GenerateWallets 生成随机钱包，persist 为 true 时以 keystore 加密保存并返回本次生成的批次 ID
+func GenerateWallets(count int, persist bool, source string, campaign string) ([]common.Address, string, error) {
+	if persist && conf.BlockchainConfig.Airdrop.WalletKeystorePassword == "" {
+		return nil, "", ErrWalletPersistDisabled
+	}
+	keys, err := generateKeys(count)
+	if err != nil {
+		return nil, "", err
+	}
+	addresses := make([]common.Address, 0, len(keys))
+	for _, key := range keys {
+		addresses = append(addresses, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	if !persist {
+		return addresses, "", nil
+	}
+	generationID, err := storeWallets(keys, source, campaign)
+	if err != nil {
+		return nil, "", err
+	}
+	return addresses, generationID, nil
+}
+
+// GetGeneratedWallets 查询已保存的生成钱包，不包含私钥
+func GetGeneratedWallets(generationID string, campaign string, limit int) ([]model.GeneratedWallet, error) {
+	return repository.GetGeneratedWallets(generationID, campaign, limit)
+}
+
+// ExportGeneratedWallets 导出钱包 zip 包，每个 keystore 使用请求的密码重新加密，导出操作写入审计日志
+func ExportGeneratedWallets(actor string, req dto.WalletExportRequest) ([]byte, int, error) {
+	if req.GenerationID == "" && req.Campaign == "" {
+		return nil, 0, ErrExportFilterRequired
+	}
+	if len(req.Password) < minExportPasswordLen {
+		return nil, 0, ErrExportPasswordTooWeak
+	}
+	serverPassword := conf.BlockchainConfig.Airdrop.WalletKeystorePassword
+	if serverPassword == "" {
+		return nil, 0, ErrWalletPersistDisabled
+	}
+	wallets, err := repository.GetGeneratedWallets(req.GenerationID, req.Campaign, maxExportWallets)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(wallets) == 0 {
+		return nil, 0, ErrNoWalletsToExport
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	manifest := make([]dto.ExportedWallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		key, err := keystore.DecryptKey([]byte(wallet.Keystore), serverPassword)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decrypt keystore of %s failed: %w", wallet.Address, err)
+		}
+		keyJSON, err := keystore.EncryptKey(key, req.Password, keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			return nil, 0, fmt.Errorf("encrypt keystore of %s failed: %w", wallet.Address, err)
+		}
+		file := fmt.Sprintf("keystore/%s.json", wallet.Address)
+		writer, err := archive.Create(file)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, err := writer.Write(keyJSON); err != nil {
+			return nil, 0, err
+		}
+		manifest = append(manifest, dto.ExportedWallet{
+			Address:      wallet.Address,
+			File:         file,
+			Source:       wallet.Source,
+			Campaign:     wallet.Campaign,
+			GenerationID: wallet.GenerationID,
+			CreatedAt:    wallet.CreatedAt,
+		})
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	writer, err := archive.Create("manifest.json")
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := writer.Write(manifestJSON); err != nil {
+		return nil, 0, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, 0, err
+	}
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"generationId": req.GenerationID,
+		"campaign":     req.Campaign,
+		"count":        len(wallets),
+	})
+	if err := repository.AddAdminAuditLog(&model.AdminAuditLog{
+		Actor:  actor,
+		Action: config.AdminActionExportWallets,
+		Params: string(params),
+		Status: config.AdminAuditStatusSuccess,
+	}); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), len(wallets), nil
+}
+
+// generateKeys 生成 secp256k1 随机私钥
+func generateKeys(count int) ([]*ecdsa.PrivateKey, error) {
+	keys := make([]*ecdsa.PrivateKey, 0, count)
+	for i := 0; i < count; i++ {
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, privateKey)
+	}
+	return keys, nil
+}
+
+// storeWallets 使用服务端密码加密私钥后保存，数量较多时使用 light scrypt 参数
+func storeWallets(keys []*ecdsa.PrivateKey, source string, campaign string) (string, error) {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", fmt.Errorf("generate generation id failed: %w", err)
+	}
+	generationID := hex.EncodeToString(idBytes)
+
+	now := time.Now()
+	password := conf.BlockchainConfig.Airdrop.WalletKeystorePassword
+	wallets := make([]model.GeneratedWallet, 0, len(keys))
+	for _, privateKey := range keys {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return "", err
+		}
+		key := &keystore.Key{
+			Id:         id,
+			Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+			PrivateKey: privateKey,
+		}
+		keyJSON, err := keystore.EncryptKey(key, password, keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			return "", fmt.Errorf("encrypt keystore failed: %w", err)
+		}
+		wallets = append(wallets, model.GeneratedWallet{
+			Address:      key.Address.Hex(),
+			Keystore:     string(keyJSON),
+			Source:       source,
+			Campaign:     campaign,
+			GenerationID: generationID,
+			CreatedAt:    now,
+		})
+	}
+	if err := repository.AddGeneratedWallets(wallets); err != nil {
+		return "", err
+	}
+	return generationID, nil
+}
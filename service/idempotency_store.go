@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"staking-interaction/common/config"
+)
+
+var (
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInFlight = errors.New("request with the same idempotency key is still in progress")
+)
+
+// IdempotentResponse 首次请求的响应，重试时原样返回
+type IdempotentResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// idempotencyRecord Response 为空表示首次请求仍在处理
+type idempotencyRecord struct {
+	Fingerprint string              `json:"fingerprint"`
+	Response    *IdempotentResponse `json:"response,omitempty"`
+}
+
+// IdempotencyStore 在 Redis 中保存 Idempotency-Key 对应的请求指纹和首次响应
+type IdempotencyStore struct {
+	redis  *redis.Client
+	config *config.Config
+}
+
+func NewIdempotencyStore(redis *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{
+		redis:  redis,
+		config: config.Get(),
+	}
+}
+
+// Begin 占用 key 后返回 nil，由调用方执行请求；key 已完成且指纹一致时返回保存的响应
+func (s *IdempotencyStore) Begin(ctx context.Context, scope string, key string, fingerprint string) (*IdempotentResponse, error) {
+	redisKey := idempotencyKey(scope, key)
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	// 占用记录在两次操作之间过期时重试一次
+	for i := 0; i < 2; i++ {
+		ok, err := s.redis.SetNX(ctx, redisKey, data, s.config.AppConfig.Idempotency.LockTimeout).Result()
+		if err != nil {
+			return nil, fmt.Errorf("lock idempotency key failed: %w", err)
+		}
+		if ok {
+			return nil, nil
+		}
+
+		stored, err := s.redis.Get(ctx, redisKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get idempotency record failed: %w", err)
+		}
+		var record idempotencyRecord
+		if err := json.Unmarshal(stored, &record); err != nil {
+			return nil, fmt.Errorf("decode idempotency record failed: %w", err)
+		}
+		if record.Fingerprint != fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if record.Response == nil {
+			return nil, ErrIdempotencyKeyInFlight
+		}
+		return record.Response, nil
+	}
+	return nil, ErrIdempotencyKeyInFlight
+}
+
+// Complete 保存首次响应，保存期内的重试直接返回该响应
+func (s *IdempotencyStore) Complete(ctx context.Context, scope string, key string, fingerprint string, response *IdempotentResponse) error {
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Response: response})
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Set(ctx, idempotencyKey(scope, key), data, s.config.AppConfig.Idempotency.TTL).Err(); err != nil {
+		return fmt.Errorf("save idempotency response failed: %w", err)
+	}
+	return nil
+}
+
+// Release 请求未正常结束时释放 key，允许客户端重试
+func (s *IdempotencyStore) Release(ctx context.Context, scope string, key string) error {
+	return s.redis.Del(ctx, idempotencyKey(scope, key)).Err()
+}
+
+func idempotencyKey(scope string, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", scope, key)
+}
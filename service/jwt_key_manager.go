@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sort"
+	"staking-interaction/common/config"
+	"staking-interaction/common/logger"
+	redisClient "staking-interaction/common/redis"
+	"staking-interaction/dto"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	JWTAlgES256 = "ES256"
+	JWTAlgEdDSA = "EdDSA"
+
+	jwtKeyManifestFile = "keys.json"
+	// 签名前超过该时长重新读取清单，使其他实例轮换出的新密钥尽快生效
+	jwtKeyReloadInterval = time.Minute
+	// 遇到未知 kid 时重新读取清单的最小间隔，避免伪造 kid 反复触发读盘
+	jwtKeyMissReloadInterval = 10 * time.Second
+)
+
+var jwtKeyAlgs = []string{JWTAlgES256, JWTAlgEdDSA}
+
+var (
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+	ErrUnsupportedJWTAlg = errors.New("unsupported jwt algorithm")
+
+	errJWTKeyManagerNotInitialized = errors.New("jwt key manager not initialized")
+)
+
+// jwtSigningKey 清单中的一条密钥记录，RetiredAt 非空表示已被轮换（或到该时间被轮换），宽限期内仍可验签
+type jwtSigningKey struct {
+	Kid         string     `json:"kid"`
+	Alg         string     `json:"alg"`
+	File        string     `json:"file"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ActivatesAt *time.Time `json:"activatesAt,omitempty"` // 开始用于签名的时间，为空时从 CreatedAt 起生效
+	RetiredAt   *time.Time `json:"retiredAt,omitempty"`
+
+	private crypto.Signer
+}
+
+// activatedAt 密钥开始用于签名的时间
+func (k *jwtSigningKey) activatedAt() time.Time {
+	if k.ActivatesAt != nil {
+		return *k.ActivatesAt
+	}
+	return k.CreatedAt
+}
+
+// JWTKeyManager 管理 access token 的签名密钥：签名使用各算法最新的密钥并写入 kid，验签按 kid 查找
+type JWTKeyManager struct {
+	mu       sync.RWMutex
+	config   config.SigningKeyConfig
+	keys     []*jwtSigningKey
+	loadedAt time.Time
+	log      *logrus.Logger
+}
+
+var (
+	jwtKeyManager     *JWTKeyManager
+	jwtKeyManagerOnce sync.Once
+	jwtKeyManagerErr  error
+)
+
+// InitJWTKeyManager 启动时加载密钥目录，缺少的算法会生成初始密钥；
+// 生成前持有与 JWTKeyRotator 相同的轮换锁，多实例同时启动时只有一个实例写清单
+func InitJWTKeyManager(lockManager *redisClient.LockManager) (*JWTKeyManager, error) {
+	jwtKeyManagerOnce.Do(func() {
+		m := &JWTKeyManager{
+			config: conf.AuthConfig.SigningKeys,
+			log:    logger.GetLogger(),
+		}
+		if err := m.init(lockManager); err != nil {
+			jwtKeyManagerErr = fmt.Errorf("init jwt key manager failed: %w", err)
+			return
+		}
+		jwtKeyManager = m
+	})
+	return jwtKeyManager, jwtKeyManagerErr
+}
+
+// GetJWTKeyManager 返回进程内共享的密钥管理器，需先在启动时调用 InitJWTKeyManager
+func GetJWTKeyManager() (*JWTKeyManager, error) {
+	jwtKeyManagerOnce.Do(func() {
+		jwtKeyManagerErr = errJWTKeyManagerNotInitialized
+	})
+	return jwtKeyManager, jwtKeyManagerErr
+}
+
+func (m *JWTKeyManager) init(lockManager *redisClient.LockManager) error {
+	if err := os.MkdirAll(m.config.Dir, 0700); err != nil {
+		return fmt.Errorf("create key dir failed: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.reloadLocked(); err != nil {
+		return err
+	}
+	if len(m.missingAlgsLocked(time.Now())) == 0 {
+		return nil
+	}
+
+	lock, err := lockManager.AcquireJWTKeyRotationLock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(context.Background()); err != nil {
+			m.log.WithFields(logrus.Fields{
+				"module":     "jwt_key_manager",
+				"action":     "unlock",
+				"error_code": "UNLOCK_FAIL",
+				"detail":     err.Error(),
+			}).Warn("Release jwt key rotation lock failed")
+		}
+	}()
+	// 等锁期间其他实例可能已生成密钥，以持锁后读到的清单为准
+	if err := m.reloadLocked(); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, alg := range m.missingAlgsLocked(now) {
+		key, err := m.generateLocked(alg, now)
+		if err != nil {
+			return err
+		}
+		m.keys = append(m.keys, key)
+		if err := m.saveManifestLocked(); err != nil {
+			return err
+		}
+		m.log.WithFields(logrus.Fields{
+			"module": "jwt_key_manager",
+			"action": "generate",
+			"kid":    key.Kid,
+			"alg":    alg,
+		}).Info("Generated initial jwt signing key")
+	}
+	return nil
+}
+
+// Sign 使用 alg 对应的当前密钥签发 token，header 中写入 kid
+func (m *JWTKeyManager) Sign(alg string, claims jwt.Claims) (string, error) {
+	m.reloadIfStale(jwtKeyReloadInterval)
+
+	m.mu.RLock()
+	key := m.activeLocked(alg, time.Now())
+	m.mu.RUnlock()
+	if key == nil {
+		return "", fmt.Errorf("%w: no active key for %s", ErrUnknownSigningKey, alg)
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedJWTAlg, alg)
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.private)
+}
+
+// Keyfunc 供 jwt.Parse 使用，按 header 中的 kid 返回公钥，并要求 token 算法与密钥一致
+func (m *JWTKeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("%w: token has no kid", ErrUnknownSigningKey)
+	}
+	key := m.verificationKey(kid)
+	if key == nil {
+		m.reloadIfStale(jwtKeyMissReloadInterval)
+		key = m.verificationKey(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSigningKey, kid)
+	}
+	if token.Method.Alg() != key.Alg {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.private.Public(), nil
+}
+
+// JWKS 返回当前密钥、已预发布的下一把密钥和宽限期内旧密钥的公钥集合
+func (m *JWTKeyManager) JWKS() (*dto.JWKSResponse, error) {
+	m.reloadIfStale(jwtKeyReloadInterval)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res := &dto.JWKSResponse{Keys: make([]dto.JSONWebKey, 0, len(m.keys))}
+	now := time.Now()
+	for _, key := range m.keys {
+		if !m.verifiableLocked(key, now) {
+			continue
+		}
+		jwk, err := toJSONWebKey(key)
+		if err != nil {
+			return nil, err
+		}
+		res.Keys = append(res.Keys, *jwk)
+	}
+	return res, nil
+}
+
+// RotateDue 在当前密钥使用满 rotation_interval 前一个检查间隔生成下一把密钥，先发布到 JWKS，
+// 到期后才用于签名，同时旧密钥进入宽限期；并清理宽限期已过的旧密钥，返回新生成的 kid
+func (m *JWTKeyManager) RotateDue(now time.Time) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// 以磁盘清单为准，避免覆盖其他实例的轮换结果
+	if err := m.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	var rotated []string
+	changed := m.pruneLocked(now)
+	for _, alg := range jwtKeyAlgs {
+		active := m.activeLocked(alg, now)
+		if active == nil && m.nextLocked(alg, now) == nil {
+			// 没有可用密钥时立即生成并生效
+			key, err := m.generateLocked(alg, now)
+			if err != nil {
+				return rotated, err
+			}
+			m.keys = append(m.keys, key)
+			rotated = append(rotated, key.Kid)
+			changed = true
+			continue
+		}
+		if active == nil || m.config.RotationInterval <= 0 || m.nextLocked(alg, now) != nil {
+			continue
+		}
+		due := active.activatedAt().Add(m.config.RotationInterval)
+		if now.Before(due.Add(-m.config.CheckInterval)) {
+			continue
+		}
+		// 下一把密钥至少提前一个检查间隔发布，验签方按间隔刷新 JWKS 时已拿到新公钥
+		activatesAt := due
+		if earliest := now.Add(m.config.CheckInterval); activatesAt.Before(earliest) {
+			activatesAt = earliest
+		}
+		activatesAt = activatesAt.UTC()
+		key, err := m.generateLocked(alg, now)
+		if err != nil {
+			return rotated, err
+		}
+		key.ActivatesAt = &activatesAt
+		retiredAt := activatesAt
+		active.RetiredAt = &retiredAt
+		m.keys = append(m.keys, key)
+		rotated = append(rotated, key.Kid)
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	if err := m.saveManifestLocked(); err != nil {
+		return nil, err
+	}
+	return rotated, nil
+}
+
+func (m *JWTKeyManager) verificationKey(kid string) *jwtSigningKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := time.Now()
+	for _, key := range m.keys {
+		if key.Kid == kid && m.verifiableLocked(key, now) {
+			return key
+		}
+	}
+	return nil
+}
+
+func (m *JWTKeyManager) verifiableLocked(key *jwtSigningKey, now time.Time) bool {
+	return key.RetiredAt == nil || now.Before(key.RetiredAt.Add(m.config.GraceWindow))
+}
+
+// activeLocked 返回 alg 对应的当前签名密钥：已生效且未轮换的最新密钥
+func (m *JWTKeyManager) activeLocked(alg string, now time.Time) *jwtSigningKey {
+	var active *jwtSigningKey
+	for _, key := range m.keys {
+		if key.Alg != alg || now.Before(key.activatedAt()) {
+			continue
+		}
+		if key.RetiredAt != nil && !now.Before(*key.RetiredAt) {
+			continue
+		}
+		if active == nil || key.activatedAt().After(active.activatedAt()) {
+			active = key
+		}
+	}
+	return active
+}
+
+// nextLocked 返回 alg 对应已发布、尚未生效的下一把密钥
+func (m *JWTKeyManager) nextLocked(alg string, now time.Time) *jwtSigningKey {
+	for _, key := range m.keys {
+		if key.Alg == alg && key.RetiredAt == nil && now.Before(key.activatedAt()) {
+			return key
+		}
+	}
+	return nil
+}
+
+// missingAlgsLocked 返回没有任何可用密钥的算法
+func (m *JWTKeyManager) missingAlgsLocked(now time.Time) []string {
+	var missing []string
+	for _, alg := range jwtKeyAlgs {
+		if m.activeLocked(alg, now) == nil && m.nextLocked(alg, now) == nil {
+			missing = append(missing, alg)
+		}
+	}
+	return missing
+}
+
+// pruneLocked 删除宽限期已过的旧密钥及其私钥文件
+func (m *JWTKeyManager) pruneLocked(now time.Time) bool {
+	kept := m.keys[:0]
+	pruned := false
+	for _, key := range m.keys {
+		if m.verifiableLocked(key, now) {
+			kept = append(kept, key)
+			continue
+		}
+		pruned = true
+		if err := os.Remove(filepath.Join(m.config.Dir, key.File)); err != nil && !os.IsNotExist(err) {
+			m.log.WithFields(logrus.Fields{
+				"module":     "jwt_key_manager",
+				"action":     "prune",
+				"error_code": "REMOVE_KEY_FAIL",
+				"kid":        key.Kid,
+				"detail":     err.Error(),
+			}).Warn("Remove expired jwt signing key failed")
+		}
+	}
+	m.keys = kept
+	return pruned
+}
+
+func (m *JWTKeyManager) reloadIfStale(maxAge time.Duration) {
+	m.mu.RLock()
+	stale := time.Since(m.loadedAt) > maxAge
+	m.mu.RUnlock()
+	if !stale {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if time.Since(m.loadedAt) <= maxAge {
+		return
+	}
+	if err := m.reloadLocked(); err != nil {
+		// 读取失败时继续使用内存中的密钥
+		m.loadedAt = time.Now()
+		m.log.WithFields(logrus.Fields{
+			"module":     "jwt_key_manager",
+			"action":     "reload",
+			"error_code": "RELOAD_KEYS_FAIL",
+			"detail":     err.Error(),
+		}).Warn("Reload jwt signing keys failed")
+	}
+}
+
+// reloadLocked 读取清单及私钥文件，清单不存在时视为空
+func (m *JWTKeyManager) reloadLocked() error {
+	data, err := os.ReadFile(filepath.Join(m.config.Dir, jwtKeyManifestFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read key manifest failed: %w", err)
+	}
+	var keys []*jwtSigningKey
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return fmt.Errorf("decode key manifest failed: %w", err)
+		}
+	}
+	for _, key := range keys {
+		private, err := readPrivateKeyPEM(filepath.Join(m.config.Dir, key.File))
+		if err != nil {
+			return fmt.Errorf("load key %s failed: %w", key.Kid, err)
+		}
+		if err := checkKeyAlg(key.Alg, private); err != nil {
+			return fmt.Errorf("load key %s failed: %w", key.Kid, err)
+		}
+		key.private = private
+	}
+	m.keys = keys
+	m.loadedAt = time.Now()
+	return nil
+}
+
+// saveManifestLocked 先写临时文件再重命名，避免其他实例读到半个清单
+func (m *JWTKeyManager) saveManifestLocked() error {
+	sort.Slice(m.keys, func(i, j int) bool { return m.keys[i].CreatedAt.Before(m.keys[j].CreatedAt) })
+	data, err := json.MarshalIndent(m.keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode key manifest failed: %w", err)
+	}
+	path := filepath.Join(m.config.Dir, jwtKeyManifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write key manifest failed: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace key manifest failed: %w", err)
+	}
+	return nil
+}
+
+// generateLocked 生成新密钥并写入 PKCS#8 PEM 文件
+func (m *JWTKeyManager) generateLocked(alg string, now time.Time) (*jwtSigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case JWTAlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case JWTAlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedJWTAlg, alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate %s key failed: %w", alg, err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s key failed: %w", alg, err)
+	}
+	kid, err := keyID(alg, private.Public())
+	if err != nil {
+		return nil, err
+	}
+	file := kid + ".pem"
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(m.config.Dir, file), data, 0600); err != nil {
+		return nil, fmt.Errorf("write key %s failed: %w", kid, err)
+	}
+	return &jwtSigningKey{
+		Kid:       kid,
+		Alg:       alg,
+		File:      file,
+		CreatedAt: now.UTC(),
+		private:   private,
+	}, nil
+}
+
+// keyID 取公钥 SPKI 编码的 SHA-256 前 8 字节作为 kid
+func keyID(alg string, public crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", fmt.Errorf("marshal public key failed: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return strings.ToLower(alg) + "-" + hex.EncodeToString(sum[:8]), nil
+}
+
+func readPrivateKeyPEM(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s is not a PKCS#8 PEM private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a signing key", path)
+	}
+	return signer, nil
+}
+
+func checkKeyAlg(alg string, private crypto.Signer) error {
+	switch alg {
+	case JWTAlgES256:
+		if key, ok := private.(*ecdsa.PrivateKey); ok && key.Curve == elliptic.P256() {
+			return nil
+		}
+	case JWTAlgEdDSA:
+		if _, ok := private.(ed25519.PrivateKey); ok {
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedJWTAlg, alg)
+	}
+	return fmt.Errorf("key type does not match %s", alg)
+}
+
+func toJSONWebKey(key *jwtSigningKey) (*dto.JSONWebKey, error) {
+	jwk := &dto.JSONWebKey{Kid: key.Kid, Alg: key.Alg, Use: "sig"}
+	switch public := key.private.Public().(type) {
+	case *ecdsa.PublicKey:
+		ecdhKey, err := public.ECDH()
+		if err != nil {
+			return nil, fmt.Errorf("convert key %s failed: %w", key.Kid, err)
+		}
+		// 未压缩点格式 0x04 || X || Y
+		point := ecdhKey.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+		jwk.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return nil, fmt.Errorf("%w: key %s", ErrUnsupportedJWTAlg, key.Kid)
+	}
+	return jwk, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	"testing"
+	"time"
+)
+
+func TestJWTKeyManagerRotateDuePrePublishes(t *testing.T) {
+	m := &JWTKeyManager{
+		config: config.SigningKeyConfig{
+			Dir:              t.TempDir(),
+			RotationInterval: 24 * time.Hour,
+			GraceWindow:      time.Hour,
+			CheckInterval:    10 * time.Minute,
+		},
+		log: logrus.New(),
+	}
+	start := time.Now()
+	initial, err := m.RotateDue(start)
+	if err != nil || len(initial) != len(jwtKeyAlgs) {
+		t.Fatalf("initial RotateDue = %v, %v", initial, err)
+	}
+	old := m.activeLocked(JWTAlgES256, start)
+
+	// 距到期超过一个检查间隔时不生成
+	if rotated, err := m.RotateDue(start.Add(23 * time.Hour)); err != nil || len(rotated) != 0 {
+		t.Fatalf("early RotateDue = %v, %v", rotated, err)
+	}
+
+	checkAt := start.Add(24*time.Hour - 5*time.Minute)
+	rotated, err := m.RotateDue(checkAt)
+	if err != nil || len(rotated) != len(jwtKeyAlgs) {
+		t.Fatalf("RotateDue = %v, %v", rotated, err)
+	}
+	next := m.nextLocked(JWTAlgES256, checkAt)
+	if next == nil {
+		t.Fatal("next key should be published before activation")
+	}
+	// 至少提前一个检查间隔发布
+	if got := next.activatedAt(); got.Before(checkAt.Add(m.config.CheckInterval)) {
+		t.Fatalf("next key activates at %v, want not before %v", got, checkAt.Add(m.config.CheckInterval))
+	}
+	if active := m.activeLocked(JWTAlgES256, checkAt); active == nil || active.Kid != old.Kid {
+		t.Fatal("old key should keep signing until the next key activates")
+	}
+	if !m.verifiableLocked(next, checkAt) {
+		t.Fatal("next key should be listed in JWKS before activation")
+	}
+	// 重复检查不会再生成
+	if rotated, err := m.RotateDue(checkAt.Add(time.Minute)); err != nil || len(rotated) != 0 {
+		t.Fatalf("repeated RotateDue = %v, %v", rotated, err)
+	}
+
+	activation := next.activatedAt()
+	if active := m.activeLocked(JWTAlgES256, activation); active == nil || active.Kid != next.Kid {
+		t.Fatal("next key should sign after activation")
+	}
+	if key := m.verificationKey(old.Kid); key == nil || !m.verifiableLocked(key, activation.Add(m.config.GraceWindow-time.Second)) {
+		t.Fatal("old key should verify during the grace window")
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"strconv"
+	"time"
+)
+
+const (
+	LedgerFormatCSV  = "csv"
+	LedgerFormatJSON = "json"
+
+	ledgerDefaultLimit = 50
+	ledgerMaxLimit     = 200
+	ledgerExportBatch  = 500
+	ledgerExportMaxRow = 10000
+)
+
+var (
+	ErrInvalidLedgerQuery   = errors.New("invalid ledger query")
+	ErrLedgerExportTooLarge = fmt.Errorf("export exceeds %d records, narrow the date range", ledgerExportMaxRow)
+)
+
+var (
+	tokenNames = map[int]string{
+		config.TokenTypeBNB: "bnb",
+		config.TokenTypeMTK: "mtk",
+	}
+	ledgerBillTypeNames = map[int]string{
+		config.BillTypeRecharge:   "recharge",
+		config.BillTypeWithdrawal: "withdrawal",
+	}
+)
+
+// GetAccountBills 分页查询账户账单
+func GetAccountBills(accountID int, query dto.LedgerQuery) (*dto.BillPage, error) {
+	filter, err := ledgerFilter(accountID, query)
+	if err != nil {
+		return nil, err
+	}
+	bills, err := repository.GetBills(filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &dto.BillPage{Items: toBillRecords(bills)}
+	if len(bills) == filter.Limit {
+		page.NextCursor = strconv.FormatUint(bills[len(bills)-1].ID, 10)
+	}
+	return page, nil
+}
+
+// GetAccountDeposits 分页查询账户充值记录
+func GetAccountDeposits(accountID int, query dto.LedgerQuery) (*dto.DepositPage, error) {
+	filter, err := depositFilter(accountID, query)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := repository.GetTransactionLogs(filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &dto.DepositPage{Items: toDepositRecords(logs)}
+	if len(logs) == filter.Limit {
+		page.NextCursor = strconv.FormatUint(logs[len(logs)-1].LogID, 10)
+	}
+	return page, nil
+}
+
+// ExportAccountBills 导出符合条件的全部账单，忽略 cursor 和 limit
+func ExportAccountBills(accountID int, query dto.LedgerQuery) ([]byte, error) {
+	filter, err := ledgerFilter(accountID, query)
+	if err != nil {
+		return nil, err
+	}
+	filter.BeforeID = 0
+	filter.Limit = ledgerExportBatch
+
+	var records []dto.BillRecord
+	for {
+		bills, err := repository.GetBills(filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, toBillRecords(bills)...)
+		if len(records) > ledgerExportMaxRow {
+			return nil, ErrLedgerExportTooLarge
+		}
+		if len(bills) < filter.Limit {
+			break
+		}
+		filter.BeforeID = bills[len(bills)-1].ID
+	}
+
+	if query.Format == LedgerFormatJSON {
+		return json.Marshal(records)
+	}
+	rows := [][]string{{"id", "token", "type", "amount", "fee", "pre_balance", "next_balance", "created_at"}}
+	for _, r := range records {
+		rows = append(rows, []string{
+			strconv.FormatUint(r.ID, 10), r.Token, r.Type, r.Amount, r.Fee, r.PreBalance, r.NextBalance,
+			r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return writeCSV(rows)
+}
+
+// ExportAccountDeposits 导出符合条件的全部充值记录，忽略 cursor 和 limit
+func ExportAccountDeposits(accountID int, query dto.LedgerQuery) ([]byte, error) {
+	filter, err := depositFilter(accountID, query)
+	if err != nil {
+		return nil, err
+	}
+	filter.BeforeID = 0
+	filter.Limit = ledgerExportBatch
+
+	var records []dto.DepositRecord
+	for {
+		logs, err := repository.GetTransactionLogs(filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, toDepositRecords(logs)...)
+		if len(records) > ledgerExportMaxRow {
+			return nil, ErrLedgerExportTooLarge
+		}
+		if len(logs) < filter.Limit {
+			break
+		}
+		filter.BeforeID = logs[len(logs)-1].LogID
+	}
+
+	if query.Format == LedgerFormatJSON {
+		return json.Marshal(records)
+	}
+	rows := [][]string{{"id", "token", "hash", "amount", "from_address", "to_address", "block_number", "created_at"}}
+	for _, r := range records {
+		rows = append(rows, []string{
+			strconv.FormatUint(r.ID, 10), r.Token, r.Hash, r.Amount, r.FromAddress, r.ToAddress, r.BlockNumber,
+			r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return writeCSV(rows)
+}
+
+// ledgerFilter 校验查询参数并转换为仓储层的过滤条件
+func ledgerFilter(accountID int, query dto.LedgerQuery) (repository.LedgerFilter, error) {
+	filter := repository.LedgerFilter{AccountID: accountID, Limit: query.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = ledgerDefaultLimit
+	}
+	if filter.Limit < 0 || filter.Limit > ledgerMaxLimit {
+		return filter, fmt.Errorf("%w: limit should be between 1 and %d", ErrInvalidLedgerQuery, ledgerMaxLimit)
+	}
+	switch query.Format {
+	case "", LedgerFormatCSV, LedgerFormatJSON:
+	default:
+		return filter, fmt.Errorf("%w: format should be csv or json", ErrInvalidLedgerQuery)
+	}
+	if query.Token != "" {
+		if filter.TokenType = lookupName(tokenNames, query.Token); filter.TokenType == 0 {
+			return filter, fmt.Errorf("%w: unknown token %s", ErrInvalidLedgerQuery, query.Token)
+		}
+	}
+	if query.Type != "" {
+		if filter.BillType = lookupName(ledgerBillTypeNames, query.Type); filter.BillType == 0 {
+			return filter, fmt.Errorf("%w: unknown type %s", ErrInvalidLedgerQuery, query.Type)
+		}
+	}
+
+	var err error
+	if filter.From, err = parseLedgerTime(query.From, false); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseLedgerTime(query.To, true); err != nil {
+		return filter, err
+	}
+	for _, amount := range []string{query.MinAmount, query.MaxAmount} {
+		if amount == "" {
+			continue
+		}
+		if value, ok := new(big.Int).SetString(amount, 10); !ok || value.Sign() < 0 {
+			return filter, fmt.Errorf("%w: amount should be a non-negative integer in the smallest unit: %s", ErrInvalidLedgerQuery, amount)
+		}
+	}
+	filter.MinAmount = query.MinAmount
+	filter.MaxAmount = query.MaxAmount
+
+	if query.Cursor != "" {
+		if filter.BeforeID, err = strconv.ParseUint(query.Cursor, 10, 64); err != nil {
+			return filter, fmt.Errorf("%w: invalid cursor", ErrInvalidLedgerQuery)
+		}
+	}
+	return filter, nil
+}
+
+// depositFilter 充值记录没有账单类型，传入 type 时直接报错而不是忽略
+func depositFilter(accountID int, query dto.LedgerQuery) (repository.LedgerFilter, error) {
+	if query.Type != "" {
+		return repository.LedgerFilter{}, fmt.Errorf("%w: type is not supported for deposits", ErrInvalidLedgerQuery)
+	}
+	return ledgerFilter(accountID, query)
+}
+
+// parseLedgerTime 日期格式的结束时间包含当天，转换为次日零点
+func parseLedgerTime(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: time should be RFC3339 or 2006-01-02: %s", ErrInvalidLedgerQuery, value)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func lookupName(names map[int]string, name string) int {
+	for value, n := range names {
+		if n == name {
+			return value
+		}
+	}
+	return 0
+}
+
+func toBillRecords(bills []model.Bill) []dto.BillRecord {
+	records := make([]dto.BillRecord, 0, len(bills))
+	for _, bill := range bills {
+		records = append(records, dto.BillRecord{
+			ID:          bill.ID,
+			Token:       tokenNames[bill.TokenType],
+			Type:        ledgerBillTypeNames[bill.BillType],
+			Amount:      bill.Amount,
+			Fee:         bill.Fee,
+			PreBalance:  bill.PreBalance,
+			NextBalance: bill.NextBalance,
+			CreatedAt:   bill.CreatedAt,
+		})
+	}
+	return records
+}
+
+func toDepositRecords(logs []model.TransactionLog) []dto.DepositRecord {
+	records := make([]dto.DepositRecord, 0, len(logs))
+	for _, log := range logs {
+		records = append(records, dto.DepositRecord{
+			ID:          log.LogID,
+			Token:       tokenNames[log.TokenType],
+			Hash:        log.Hash,
+			Amount:      log.Amount,
+			FromAddress: log.FromAddress,
+			ToAddress:   log.ToAddress,
+			BlockNumber: log.BlockNumber,
+			CreatedAt:   log.CreatedAt,
+		})
+	}
+	return records
+}
+
+func writeCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("write csv failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+var ErrLoginNonceInvalid = errors.New("login nonce is invalid, expired or already used")
+
+// LoginNonceStore 服务端下发的登录 nonce，验证时原子删除，保证每个 nonce 只能登录一次
+type LoginNonceStore struct {
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+func NewLoginNonceStore(redis *redis.Client, ttl time.Duration) *LoginNonceStore {
+	return &LoginNonceStore{
+		redis: redis,
+		ttl:   ttl,
+	}
+}
+
+// Issue 生成 32 位十六进制 nonce，并记录其所属链，防止跨链使用
+func (s *LoginNonceStore) Issue(ctx context.Context, chain string) (string, time.Time, error) {
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return "", time.Time{}, fmt.Errorf("generate nonce failed: %w", err)
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	if err := s.redis.Set(ctx, loginNonceKey(nonce), chain, s.ttl).Err(); err != nil {
+		return "", time.Time{}, fmt.Errorf("store nonce failed: %w", err)
+	}
+	return nonce, time.Now().Add(s.ttl), nil
+}
+
+// Consume 校验并删除 nonce
+func (s *LoginNonceStore) Consume(ctx context.Context, chain string, nonce string) error {
+	value, err := s.redis.GetDel(ctx, loginNonceKey(nonce)).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrLoginNonceInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("consume nonce failed: %w", err)
+	}
+	if value != chain {
+		return ErrLoginNonceInvalid
+	}
+	return nil
+}
+
+func loginNonceKey(nonce string) string {
+	return fmt.Sprintf("login_nonce:%s", nonce)
+}
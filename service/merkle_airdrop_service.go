@@ -0,0 +1,135 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"staking-interaction/utils"
+	"time"
+)
+
+// CreateMerkleAirdrop 校验收款人后构建 Merkle 树，保存根及每个地址的证明
+func CreateMerkleAirdrop(request *dto.MerkleAirdropRequest) (*dto.MerkleAirdropResponse, error) {
+	contractAddr := request.ContractAddress
+	if contractAddr == "" {
+		contractAddr = config.Get().BlockchainConfig.Contracts.Claim
+	}
+	if !common.IsHexAddress(contractAddr) {
+		return nil, fmt.Errorf("invalid claim contract address: %q", contractAddr)
+	}
+	addresses, amounts, duplicates, err := ValidateRecipients(request.Recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]common.Hash, len(addresses))
+	for i := range addresses {
+		hashes[i] = utils.MerkleLeaf(uint64(i), addresses[i], amounts[i])
+	}
+	tree, err := utils.NewMerkleTree(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	leaves := make([]model.MerkleAirdropLeaf, 0, len(addresses))
+	for i := range addresses {
+		proof, err := tree.Proof(i)
+		if err != nil {
+			return nil, err
+		}
+		proofJSON, err := json.Marshal(proof)
+		if err != nil {
+			return nil, fmt.Errorf("marshal proof failed: %w", err)
+		}
+		leaves = append(leaves, model.MerkleAirdropLeaf{
+			LeafIndex: uint64(i),
+			Address:   addresses[i].Hex(),
+			Amount:    amounts[i].String(),
+			Leaf:      hashes[i].Hex(),
+			Proof:     string(proofJSON),
+			CreatedAt: now,
+		})
+	}
+
+	airdrop := &model.MerkleAirdrop{
+		Name:            request.Name,
+		ContractAddress: common.HexToAddress(contractAddr).Hex(),
+		Root:            tree.Root().Hex(),
+		TotalRecipients: len(addresses),
+		TotalAmount:     utils.CalculateSumOfAmounts(amounts).String(),
+		ClaimedAmount:   "0",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := repository.CreateMerkleAirdrop(airdrop, leaves); err != nil {
+		return nil, err
+	}
+	res := toMerkleAirdropResponse(airdrop)
+	res.Duplicates = duplicates
+	return res, nil
+}
+
+func GetMerkleAirdrop(id uint64) (*dto.MerkleAirdropResponse, error) {
+	airdrop, err := repository.GetMerkleAirdrop(id)
+	if err != nil {
+		return nil, err
+	}
+	return toMerkleAirdropResponse(airdrop), nil
+}
+
+// GetMerkleProof 返回地址的领取参数和证明，返回前用根重新校验一次
+func GetMerkleProof(id uint64, address common.Address) (*dto.MerkleProofResponse, error) {
+	airdrop, err := repository.GetMerkleAirdrop(id)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := repository.GetMerkleAirdropLeaf(id, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	var proof []common.Hash
+	if err := json.Unmarshal([]byte(leaf.Proof), &proof); err != nil {
+		return nil, fmt.Errorf("decode proof failed: %w", err)
+	}
+	amount, err := utils.StringToBigInt(leaf.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.VerifyMerkleProof(proof, common.HexToHash(airdrop.Root), utils.MerkleLeaf(leaf.LeafIndex, address, amount)) {
+		return nil, fmt.Errorf("stored proof does not match root of airdrop %d", id)
+	}
+
+	proofHex := make([]string, 0, len(proof))
+	for _, node := range proof {
+		proofHex = append(proofHex, node.Hex())
+	}
+	return &dto.MerkleProofResponse{
+		AirdropID:       airdrop.ID,
+		ContractAddress: airdrop.ContractAddress,
+		Root:            airdrop.Root,
+		Index:           leaf.LeafIndex,
+		Address:         leaf.Address,
+		Amount:          leaf.Amount,
+		Proof:           proofHex,
+		Claimed:         leaf.Claimed,
+		ClaimTxHash:     leaf.ClaimTxHash,
+	}, nil
+}
+
+func toMerkleAirdropResponse(airdrop *model.MerkleAirdrop) *dto.MerkleAirdropResponse {
+	return &dto.MerkleAirdropResponse{
+		ID:              airdrop.ID,
+		Name:            airdrop.Name,
+		ContractAddress: airdrop.ContractAddress,
+		Root:            airdrop.Root,
+		TotalRecipients: airdrop.TotalRecipients,
+		TotalAmount:     airdrop.TotalAmount,
+		ClaimedCount:    airdrop.ClaimedCount,
+		ClaimedAmount:   airdrop.ClaimedAmount,
+	}
+}
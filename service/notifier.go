@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"staking-interaction/common/config"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"strings"
+	"time"
+)
+
+// MaturityNotice 质押到期通知内容
+type MaturityNotice struct {
+	StakeIndex  string    `json:"stakeIndex"`
+	UserAddress string    `json:"userAddress"`
+	Amount      string    `json:"amount"`
+	Milestone   string    `json:"milestone"`
+	EndTime     time.Time `json:"endTime"`
+}
+
+func (n MaturityNotice) title() string {
+	if n.Milestone == config.StakeMilestoneMatured {
+		return fmt.Sprintf("Stake #%s has matured", n.StakeIndex)
+	}
+	return fmt.Sprintf("Stake #%s is about to mature", n.StakeIndex)
+}
+
+func (n MaturityNotice) content() string {
+	if n.Milestone == config.StakeMilestoneMatured {
+		return fmt.Sprintf("Your stake #%s of %s reached its end time at %s and can be withdrawn now.",
+			n.StakeIndex, n.Amount, n.EndTime.UTC().Format(config.TIME_FORMAT))
+	}
+	return fmt.Sprintf("Your stake #%s of %s will mature at %s (UTC).",
+		n.StakeIndex, n.Amount, n.EndTime.UTC().Format(config.TIME_FORMAT))
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Channel() string
+	Notify(ctx context.Context, notice MaturityNotice) error
+}
+
+// NewNotifiers 按配置的渠道顺序创建通知器
+func NewNotifiers(conf config.MaturityConfig) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0, len(conf.Channels))
+	for _, channel := range conf.Channels {
+		switch channel {
+		case config.NotifyChannelWebhook:
+			if conf.Webhook.URL == "" {
+				return nil, fmt.Errorf("webhook channel enabled but webhook url is empty")
+			}
+			notifiers = append(notifiers, &WebhookNotifier{
+				url:    conf.Webhook.URL,
+				client: &http.Client{Timeout: conf.Webhook.Timeout},
+			})
+		case config.NotifyChannelEmail:
+			if conf.Email.Host == "" || len(conf.Email.To) == 0 {
+				return nil, fmt.Errorf("email channel enabled but smtp host or recipients are empty")
+			}
+			notifiers = append(notifiers, &EmailNotifier{config: conf.Email})
+		case config.NotifyChannelInbox:
+			notifiers = append(notifiers, &InboxNotifier{})
+		default:
+			return nil, fmt.Errorf("unknown notify channel: %s", channel)
+		}
+	}
+	return notifiers, nil
+}
+
+// WebhookNotifier 以 JSON POST 推送通知，非 2xx 响应视为失败
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (w *WebhookNotifier) Channel() string {
+	return config.NotifyChannelWebhook
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, notice MaturityNotice) error {
+	body, err := json.Marshal(notice)
+	if err != nil {
+		return fmt.Errorf("marshal webhook body failed: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier 运维通知渠道：通过 SMTP 把所有用户的到期通知发送到配置的运维收件人，不会发给用户本人，
+// 邮件中带用户地址；用户侧通知使用站内信渠道
+type EmailNotifier struct {
+	config config.EmailConfig
+}
+
+func (e *EmailNotifier) Channel() string {
+	return config.NotifyChannelEmail
+}
+
+func (e *EmailNotifier) Notify(_ context.Context, notice MaturityNotice) error {
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+	msg := strings.Join([]string{
+		"From: " + e.config.From,
+		"To: " + strings.Join(e.config.To, ", "),
+		"Subject: " + notice.title(),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		fmt.Sprintf("Address: %s", notice.UserAddress),
+		notice.content(),
+	}, "\r\n")
+	addr := fmt.Sprintf("%s:%d", e.config.Host, e.config.Port)
+	if err := smtp.SendMail(addr, auth, e.config.From, e.config.To, []byte(msg)); err != nil {
+		return fmt.Errorf("send email failed: %w", err)
+	}
+	return nil
+}
+
+// InboxNotifier 写入站内信
+type InboxNotifier struct{}
+
+func (i *InboxNotifier) Channel() string {
+	return config.NotifyChannelInbox
+}
+
+func (i *InboxNotifier) Notify(_ context.Context, notice MaturityNotice) error {
+	return repository.AddInboxMessage(&model.InboxMessage{
+		Address:   notice.UserAddress,
+		Category:  "stake_" + notice.Milestone,
+		Title:     notice.title(),
+		Content:   notice.content(),
+		CreatedAt: time.Now(),
+	})
+}
+
+// GetInboxMessages 查询账户关联的全部钱包的站内信，最近 100 条
+func GetInboxMessages(accountID int) ([]model.InboxMessage, error) {
+	wallets, err := repository.GetAccountWallets(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wallets) == 0 {
+		return []model.InboxMessage{}, nil
+	}
+	addresses := make([]string, 0, len(wallets))
+	for _, wallet := range wallets {
+		addresses = append(addresses, wallet.Address)
+	}
+	return repository.GetInboxMessages(addresses, 100)
+}
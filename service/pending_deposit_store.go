@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+const (
+	pendingDepositKey = "pending_deposits"
+	// pendingDepositTTL 区块同步停止刷新后快照自动失效
+	pendingDepositTTL = time.Minute
+)
+
+// PendingDepositStore 区块同步服务写入已上链但未达到确认区块数的充值，按账户和代币类型汇总，每次扫描整体替换
+type PendingDepositStore struct {
+	redis *redis.Client
+}
+
+func NewPendingDepositStore(redis *redis.Client) *PendingDepositStore {
+	return &PendingDepositStore{redis: redis}
+}
+
+// Replace 用最新一次扫描结果替换快照，deposits 为 accountID -> tokenType -> 金额
+func (s *PendingDepositStore) Replace(ctx context.Context, deposits map[int]map[int]*big.Int) error {
+	fields := make(map[string]interface{}, len(deposits))
+	for accountID, amounts := range deposits {
+		data, err := json.Marshal(amounts)
+		if err != nil {
+			return err
+		}
+		fields[strconv.Itoa(accountID)] = data
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, pendingDepositKey)
+	if len(fields) > 0 {
+		pipe.HSet(ctx, pendingDepositKey, fields)
+		pipe.Expire(ctx, pendingDepositKey, pendingDepositTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("save pending deposits failed: %w", err)
+	}
+	return nil
+}
+
+// Get 返回账户待确认的充值金额，tokenType -> 金额
+func (s *PendingDepositStore) Get(ctx context.Context, accountID int) (map[int]*big.Int, error) {
+	data, err := s.redis.HGet(ctx, pendingDepositKey, strconv.Itoa(accountID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return map[int]*big.Int{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get pending deposits failed: %w", err)
+	}
+	amounts := make(map[int]*big.Int)
+	if err := json.Unmarshal(data, &amounts); err != nil {
+		return nil, fmt.Errorf("decode pending deposits failed: %w", err)
+	}
+	return amounts, nil
+}
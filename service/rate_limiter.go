@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"math"
+	"staking-interaction/common/config"
+	"time"
+)
+
+var ErrUnknownRateLimitPolicy = errors.New("unknown rate limit policy")
+
+// tokenBucketScript 令牌桶扣减，使用 Redis 服务器时间避免多实例时钟偏差
+// KEYS[1] 桶 key，ARGV: 每毫秒补充令牌数, 桶容量
+// 返回 {是否放行, 需等待的毫秒数}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return {allowed, wait}
+`)
+
+// RateLimiter 基于 Redis 的令牌桶限流和签名失败封禁
+type RateLimiter struct {
+	redis  *redis.Client
+	config *config.Config
+}
+
+func NewRateLimiter(redis *redis.Client) *RateLimiter {
+	return &RateLimiter{
+		redis:  redis,
+		config: config.Get(),
+	}
+}
+
+// Allow 从 subject 在该策略下的令牌桶取一个令牌，被限流时返回需要等待的时长
+func (r *RateLimiter) Allow(ctx context.Context, policy string, subject string) (time.Duration, error) {
+	rule, ok := r.config.AppConfig.RateLimit.Policies[policy]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownRateLimitPolicy, policy)
+	}
+	rate := float64(rule.Limit) / float64(rule.Period.Milliseconds())
+	key := fmt.Sprintf("rate_limit:%s:%s", policy, subject)
+	res, err := tokenBucketScript.Run(ctx, r.redis, []string{key}, rate, rule.Burst).Int64Slice()
+	if err != nil {
+		return 0, fmt.Errorf("run token bucket script failed: %w", err)
+	}
+	if res[0] == 1 {
+		return 0, nil
+	}
+	return time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// LockedFor 返回客户端 IP 剩余的封禁时长，未封禁时为 0
+func (r *RateLimiter) LockedFor(ctx context.Context, ip string) (time.Duration, error) {
+	ttl, err := r.redis.PTTL(ctx, lockoutKey(ip)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("get lockout ttl failed: %w", err)
+	}
+	// key 不存在时 PTTL 返回负值
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordFailure 记录一次签名校验失败，窗口内失败次数达到上限时封禁该 IP 并返回封禁时长
+func (r *RateLimiter) RecordFailure(ctx context.Context, ip string) (time.Duration, error) {
+	lockout := r.config.AppConfig.RateLimit.Lockout
+	key := fmt.Sprintf("auth_failure:%s", ip)
+
+	failures, err := r.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("record auth failure failed: %w", err)
+	}
+	// 窗口从第一次失败开始计算
+	if failures == 1 {
+		if err := r.redis.Expire(ctx, key, lockout.Window).Err(); err != nil {
+			return 0, fmt.Errorf("set auth failure window failed: %w", err)
+		}
+	}
+	if failures < int64(lockout.MaxFailures) {
+		return 0, nil
+	}
+
+	pipe := r.redis.TxPipeline()
+	pipe.Set(ctx, lockoutKey(ip), failures, lockout.Duration)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("lock out client failed: %w", err)
+	}
+	return lockout.Duration, nil
+}
+
+// RetryAfterSeconds Retry-After 响应头的秒数，向上取整且至少 1 秒
+func RetryAfterSeconds(wait time.Duration) int {
+	return int(math.Max(1, math.Ceil(wait.Seconds())))
+}
+
+func lockoutKey(ip string) string {
+	return fmt.Sprintf("auth_lockout:%s", ip)
+}
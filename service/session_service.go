@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/common/config"
+	"staking-interaction/common/logger"
+	"staking-interaction/dto"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrSessionInactive     = errors.New("session is not active")
+	errSessionNotFound     = errors.New("session not found")
+)
+
+// loginSession 一次登录产生的会话，也是 refresh token 的轮换族，只有最新的 refresh token 有效
+type loginSession struct {
+	Address     string    `json:"address"`
+	Chain       string    `json:"chain"`
+	AccountID   int       `json:"accountId"`
+	RefreshHash string    `json:"refreshHash"` // 当前有效 refresh token 的 sha256
+	CreatedAt   time.Time `json:"createdAt"`
+	RefreshedAt time.Time `json:"refreshedAt"`
+}
+
+// SessionService 基于 Redis 的多会话管理，同一钱包可同时保持多个会话
+//
+//	session:<sid>                  会话内容
+//	session_index:<chain>:<addr>   钱包的会话 ID 集合
+//	refresh_token:<sha256>         refresh token 所属会话，轮换后保留用于检测重放
+type SessionService struct {
+	redis  *redis.Client
+	config *config.Config
+	log    *logrus.Logger
+}
+
+func NewSessionService(redis *redis.Client) *SessionService {
+	return &SessionService{
+		redis:  redis,
+		config: config.Get(),
+		log:    logger.GetLogger(),
+	}
+}
+
+// CreateSession 登录成功后创建会话，签发 access token 和 refresh token
+func (s *SessionService) CreateSession(ctx context.Context, chain string, address string, accountID int) (*dto.LoginResponse, error) {
+	sid, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &loginSession{
+		Address:     address,
+		Chain:       chain,
+		AccountID:   accountID,
+		RefreshHash: hashRefreshToken(refreshToken),
+		CreatedAt:   now,
+		RefreshedAt: now,
+	}
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	ttl := s.config.AuthConfig.RefreshExpiration
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(sid), payload, ttl)
+		pipe.Set(ctx, refreshTokenKey(session.RefreshHash), sid, ttl)
+		pipe.SAdd(ctx, sessionIndexKey(chain, address), sid)
+		pipe.Expire(ctx, sessionIndexKey(chain, address), ttl)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store session failed: %w", err)
+	}
+	return s.loginResponse(sid, session, refreshToken)
+}
+
+// Refresh 轮换 refresh token 并签发新的 access token，已轮换的旧 token 再次使用时吊销整个会话
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*dto.LoginResponse, error) {
+	sid, session, err := s.sessionByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	newToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	oldHash := session.RefreshHash
+	session.RefreshHash = hashRefreshToken(newToken)
+	session.RefreshedAt = time.Now()
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.config.AuthConfig.RefreshExpiration
+	// 乐观锁保证同一个 refresh token 并发刷新时只有一个成功
+	err = s.redis.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := s.getSession(ctx, tx, sid)
+		if err != nil {
+			return err
+		}
+		if current.RefreshHash != oldHash {
+			return ErrRefreshTokenInvalid
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, sessionKey(sid), payload, ttl)
+			pipe.Set(ctx, refreshTokenKey(session.RefreshHash), sid, ttl)
+			pipe.Expire(ctx, refreshTokenKey(oldHash), ttl)
+			pipe.Expire(ctx, sessionIndexKey(session.Chain, session.Address), ttl)
+			return nil
+		})
+		return err
+	}, sessionKey(sid))
+	if errors.Is(err, redis.TxFailedErr) || errors.Is(err, errSessionNotFound) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.loginResponse(sid, session, newToken)
+}
+
+// Logout 吊销 refresh token 所属的会话
+func (s *SessionService) Logout(ctx context.Context, refreshToken string) error {
+	sid, session, err := s.sessionByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.revoke(ctx, sid, session)
+}
+
+// LogoutAll 吊销 refresh token 所属钱包的全部会话，返回吊销数量
+func (s *SessionService) LogoutAll(ctx context.Context, refreshToken string) (int, error) {
+	_, session, err := s.sessionByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return 0, err
+	}
+	return s.RevokeAll(ctx, session.Chain, session.Address)
+}
+
+// RevokeAll 吊销钱包的全部会话
+func (s *SessionService) RevokeAll(ctx context.Context, chain string, address string) (int, error) {
+	indexKey := sessionIndexKey(chain, address)
+	sids, err := s.redis.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("get sessions failed: %w", err)
+	}
+	keys := make([]string, 0, len(sids)+1)
+	for _, sid := range sids {
+		keys = append(keys, sessionKey(sid))
+	}
+	revoked := 0
+	if len(keys) > 0 {
+		n, err := s.redis.Del(ctx, keys...).Result()
+		if err != nil {
+			return 0, fmt.Errorf("revoke sessions failed: %w", err)
+		}
+		revoked = int(n)
+	}
+	if err := s.redis.Del(ctx, indexKey).Err(); err != nil {
+		return revoked, fmt.Errorf("clear session index failed: %w", err)
+	}
+	return revoked, nil
+}
+
+// Principal 校验 access token 中的会话仍然有效，且与 token 的钱包地址和链一致，返回请求身份
+func (s *SessionService) Principal(ctx context.Context, claims *dto.CustomClaims) (*dto.Principal, error) {
+	if claims.SessionID == "" {
+		return nil, ErrSessionInactive
+	}
+	session, err := s.getSession(ctx, s.redis, claims.SessionID)
+	if errors.Is(err, errSessionNotFound) {
+		return nil, ErrSessionInactive
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.Address != claims.WalletAddress || session.Chain != claims.Chain {
+		return nil, ErrSessionInactive
+	}
+	// 角色每次请求实时读取，撤销后立即生效
+	roles, err := GetAccountRoles(session.AccountID, session.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.Principal{
+		WalletAddress: session.Address,
+		Chain:         session.Chain,
+		AccountID:     session.AccountID,
+		SessionID:     claims.SessionID,
+		Roles:         roles,
+	}, nil
+}
+
+// sessionByRefreshToken 查找 refresh token 所属会话，token 已被轮换时视为重放并吊销会话
+func (s *SessionService) sessionByRefreshToken(ctx context.Context, refreshToken string) (string, *loginSession, error) {
+	hash := hashRefreshToken(refreshToken)
+	sid, err := s.redis.Get(ctx, refreshTokenKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("get refresh token failed: %w", err)
+	}
+	session, err := s.getSession(ctx, s.redis, sid)
+	if errors.Is(err, errSessionNotFound) {
+		return "", nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if session.RefreshHash != hash {
+		s.log.WithFields(logrus.Fields{
+			"module":     "session_service",
+			"action":     "refresh",
+			"error_code": "REFRESH_TOKEN_REUSED",
+			"chain":      session.Chain,
+			"address":    session.Address,
+			"sid":        sid,
+		}).Warn("Rotated refresh token reused, revoking session")
+		if err := s.revoke(ctx, sid, session); err != nil {
+			return "", nil, err
+		}
+		return "", nil, ErrRefreshTokenReused
+	}
+	return sid, session, nil
+}
+
+func (s *SessionService) getSession(ctx context.Context, client redis.Cmdable, sid string) (*loginSession, error) {
+	payload, err := client.Get(ctx, sessionKey(sid)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get session failed: %w", err)
+	}
+	var session loginSession
+	if err := json.Unmarshal(payload, &session); err != nil {
+		return nil, fmt.Errorf("decode session failed: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *SessionService) revoke(ctx context.Context, sid string, session *loginSession) error {
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(sid))
+		pipe.SRem(ctx, sessionIndexKey(session.Chain, session.Address), sid)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("revoke session failed: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionService) loginResponse(sid string, session *loginSession, refreshToken string) (*dto.LoginResponse, error) {
+	token, err := s.issueAccessToken(session.Chain, session.Address, sid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &dto.LoginResponse{
+		Token:            token,
+		RefreshToken:     refreshToken,
+		SessionID:        sid,
+		ExpiresAt:        now.Add(s.config.AuthConfig.JwtExpiration),
+		RefreshExpiresAt: now.Add(s.config.AuthConfig.RefreshExpiration),
+	}, nil
+}
+
+// issueAccessToken 按登录链使用对应算法签发 access token
+func (s *SessionService) issueAccessToken(chain string, address string, sid string) (string, error) {
+	switch chain {
+	case config.LoginChainBSC:
+		return NewAuthBSCService(s.redis).issueAccessToken(address, sid)
+	case config.LoginChainSolana:
+		return NewAuthSolanaService(s.redis).issueAccessToken(address, sid)
+	}
+	return "", fmt.Errorf("unsupported login chain: %s", chain)
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random token failed: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sessionKey(sid string) string {
+	return fmt.Sprintf("session:%s", sid)
+}
+
+func sessionIndexKey(chain string, address string) string {
+	return fmt.Sprintf("session_index:%s:%s", chain, address)
+}
+
+func refreshTokenKey(hash string) string {
+	return fmt.Sprintf("refresh_token:%s", hash)
+}
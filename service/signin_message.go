@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"staking-interaction/common/config"
+	"staking-interaction/dto"
+	"staking-interaction/utils"
+	"time"
+)
+
+// ErrInvalidSignature 签名消息验签失败，用于统计失败次数触发封禁
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// signInClockSkew 允许的客户端与服务端时钟误差
+const signInClockSkew = time.Minute
+
+// validateSignInMessage 登录消息必须绑定本服务的域名、URI 和链，签发时间在 nonce 有效期内且未过期
+func validateSignInMessage(m *utils.SignInMessage, chain string, chainID string, conf config.SignInConfig, now time.Time) error {
+	switch {
+	case m.Chain != chain:
+		return fmt.Errorf("unsupported sign-in account type: %s", m.Chain)
+	case m.Domain != conf.Domain:
+		return fmt.Errorf("domain mismatch: %s", m.Domain)
+	case m.URI != conf.URI:
+		return fmt.Errorf("uri mismatch: %s", m.URI)
+	case m.Version != "1":
+		return fmt.Errorf("unsupported version: %s", m.Version)
+	case m.ChainID != chainID:
+		return fmt.Errorf("chain id mismatch: %s", m.ChainID)
+	case m.IssuedAt.After(now.Add(signInClockSkew)):
+		return fmt.Errorf("issued at is in the future: %s", m.IssuedAt.Format(time.RFC3339))
+	case m.IssuedAt.Before(now.Add(-conf.NonceTTL - signInClockSkew)):
+		return fmt.Errorf("sign-in message is too old, issued at: %s", m.IssuedAt.Format(time.RFC3339))
+	case m.ExpirationTime != nil && !now.Before(*m.ExpirationTime):
+		return fmt.Errorf("sign-in message expired at %s", m.ExpirationTime.Format(time.RFC3339))
+	case m.NotBefore != nil && now.Add(signInClockSkew).Before(*m.NotBefore):
+		return fmt.Errorf("sign-in message is not valid before %s", m.NotBefore.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// issueSignInNonce 下发一次性 nonce，address 不为空时附带完整的待签名消息，关联钱包时 requestID 标明目标账户
+func issueSignInNonce(ctx context.Context, redis *redis.Client, chain string, chainID string, conf config.SignInConfig, address string, requestID string) (*dto.LoginNonceResponse, error) {
+	nonce, expiresAt, err := NewLoginNonceStore(redis, conf.NonceTTL).Issue(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	expiresAt = expiresAt.UTC().Truncate(time.Second)
+	res := &dto.LoginNonceResponse{
+		Nonce:     nonce,
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+		Domain:    conf.Domain,
+		URI:       conf.URI,
+		ChainID:   chainID,
+		Statement: conf.Statement,
+		RequestID: requestID,
+	}
+	if address != "" {
+		message := &utils.SignInMessage{
+			Domain:         conf.Domain,
+			Chain:          chain,
+			Address:        address,
+			Statement:      conf.Statement,
+			URI:            conf.URI,
+			Version:        "1",
+			ChainID:        chainID,
+			Nonce:          nonce,
+			IssuedAt:       issuedAt,
+			ExpirationTime: &expiresAt,
+			RequestID:      requestID,
+		}
+		res.Message = message.String()
+	}
+	return res, nil
+}
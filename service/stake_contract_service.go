@@ -1,11 +1,16 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"math/big"
 	"staking-interaction/adapter"
 	"staking-interaction/common/config"
+	"staking-interaction/contracts/mtk"
 	"staking-interaction/contracts/stake"
 	"staking-interaction/dto"
 	"staking-interaction/model"
@@ -43,19 +48,42 @@ func (s *StakeService) Stake(amount int64, period uint8) (response *dto.StakeRes
 		return nil, fmt.Errorf("failed to create staking contract: %v", err)
 	}
 	auth := s.clientInfo.Auth
+	stakeAmount := big.NewInt(amount)
+
+	// 质押前确认 stakingToken 对质押合约的授权额度，不足时先 approve 并等待上链
+	approveHash, err := s.ensureAllowance(stakingContract, stakeAmount)
+	if err != nil {
+		return nil, fmt.Errorf("ensure allowance failed: %w", err)
+	}
+
+	// 预执行质押交易，可预见的 revert 直接返回，不广播交易
+	stakeABI, err := stake.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse stake abi failed: %w", err)
+	}
+	if _, err := preflight(context.Background(), s.clientInfo, contractCall{
+		To:     stakeContractAddr,
+		ABI:    stakeABI,
+		Method: "stake",
+		Args:   []interface{}{stakeAmount, period},
+	}); err != nil {
+		return nil, fmt.Errorf("stake preflight failed: %w", err)
+	}
 
 	trans, err := stakingContract.Stake(
 		auth,
-		big.NewInt(amount),
+		stakeAmount,
 		period,
 	)
 
 	if trans == nil || err != nil {
 		return nil, fmt.Errorf("Stake transaction error: %w", err)
 	}
+	RecordOutboundTx(trans, s.clientInfo.FromAddress, config.TxPurposeStake)
 
 	response = &dto.StakeResponse{
 		Hash:            trans.Hash().String(),
+		ApproveHash:     approveHash,
 		ContractAddress: stakeContractAddr.String(),
 		FromAddress:     s.clientInfo.FromAddress,
 		Method:          "stake",
@@ -64,6 +92,62 @@ func (s *StakeService) Stake(amount int64, period uint8) (response *dto.StakeRes
 	return response, nil
 }
 
+// ensureAllowance 检查授权额度，额度充足时返回空 hash，否则返回 approve 交易 hash
+func (s *StakeService) ensureAllowance(stakingContract *stake.Contracts, amount *big.Int) (string, error) {
+	tokenAddr, err := stakingContract.StakingToken(&bind.CallOpts{})
+	if err != nil {
+		return "", fmt.Errorf("get staking token failed: %w", err)
+	}
+	tokenContract, err := mtk.NewContracts(tokenAddr, s.clientInfo.Client)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token contract: %w", err)
+	}
+
+	allowance, err := tokenContract.Allowance(&bind.CallOpts{}, s.clientInfo.FromAddress, stakeContractAddr)
+	if err != nil {
+		return "", fmt.Errorf("get allowance failed: %w", err)
+	}
+	if allowance.Cmp(amount) >= 0 {
+		return "", nil
+	}
+
+	stakeConf := cfg.BlockchainConfig.Stake
+	approveAmount := amount
+	if stakeConf.ApproveMax {
+		approveAmount = abi.MaxUint256
+	}
+
+	tokenABI, err := mtk.ContractsMetaData.GetAbi()
+	if err != nil {
+		return "", fmt.Errorf("parse token abi failed: %w", err)
+	}
+	if _, err := preflight(context.Background(), s.clientInfo, contractCall{
+		To:     tokenAddr,
+		ABI:    tokenABI,
+		Method: "approve",
+		Args:   []interface{}{stakeContractAddr, approveAmount},
+	}); err != nil {
+		return "", fmt.Errorf("approve preflight failed: %w", err)
+	}
+
+	tx, err := tokenContract.Approve(s.clientInfo.Auth, stakeContractAddr, approveAmount)
+	if tx == nil || err != nil {
+		return "", fmt.Errorf("approve transaction error: %w", err)
+	}
+	RecordOutboundTx(tx, s.clientInfo.FromAddress, config.TxPurposeApprove)
+
+	ctx, cancel := context.WithTimeout(context.Background(), stakeConf.ApproveTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, s.clientInfo.Client, tx)
+	if err != nil {
+		return tx.Hash().Hex(), fmt.Errorf("wait approve mined failed: %w, hash: %s", err, tx.Hash().Hex())
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return tx.Hash().Hex(), fmt.Errorf("approve transaction reverted, hash: %s", tx.Hash().Hex())
+	}
+	return tx.Hash().Hex(), nil
+}
+
 func (s *StakeService) Withdraw(index *big.Int) (response *dto.StakeResponse, err error) {
 	stakingContract, err := s.NewStakeContract()
 	if err != nil {
@@ -71,11 +155,25 @@ func (s *StakeService) Withdraw(index *big.Int) (response *dto.StakeResponse, er
 	}
 	auth := s.clientInfo.Auth
 
+	stakeABI, err := stake.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse stake abi failed: %w", err)
+	}
+	if _, err := preflight(context.Background(), s.clientInfo, contractCall{
+		To:     stakeContractAddr,
+		ABI:    stakeABI,
+		Method: "withdraw",
+		Args:   []interface{}{index},
+	}); err != nil {
+		return nil, fmt.Errorf("withdraw preflight failed: %w", err)
+	}
+
 	trans, err := stakingContract.Withdraw(auth, index)
 
 	if trans == nil || err != nil {
 		return nil, fmt.Errorf("withdraw transaction error: %w", err)
 	}
+	RecordOutboundTx(trans, s.clientInfo.FromAddress, config.TxPurposeWithdraw)
 
 	response = &dto.StakeResponse{
 		Hash:            trans.Hash().String(),
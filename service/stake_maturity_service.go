@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/common/logger"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"time"
+)
+
+// 单个渠道的最大发送次数，超过后不再重试
+const maxNotifyAttempts = 5
+
+type StakeMaturityService struct {
+	clientInfo *adapter.InitClient
+	notifiers  []Notifier
+	config     config.MaturityConfig
+}
+
+func NewStakeMaturityService(
+	clientInfo *adapter.InitClient,
+	notifiers []Notifier,
+	config config.MaturityConfig,
+) *StakeMaturityService {
+	return &StakeMaturityService{
+		clientInfo: clientInfo,
+		notifiers:  notifiers,
+		config:     config,
+	}
+}
+
+// ScanMaturity 扫描活跃质押，对即将到期和已到期的质押按渠道发送通知，返回发送成功和失败的次数
+func (s *StakeMaturityService) ScanMaturity(ctx context.Context, now time.Time) (sent int, failed int, err error) {
+	stakes, err := repository.GetActiveStakes()
+	if err != nil {
+		return 0, 0, err
+	}
+	users := make(map[string]struct{})
+	for _, stake := range stakes {
+		users[stake.FromAddress] = struct{}{}
+	}
+
+	stakingContract, err := NewStakeService(s.clientInfo).NewStakeContract()
+	if err != nil {
+		return 0, 0, err
+	}
+	for user := range users {
+		// 到期时间以合约为准，索引记录只用于确定需要扫描的地址
+		positions, err := stakingContract.GetUserActiveStakes(&bind.CallOpts{Context: ctx}, common.HexToAddress(user))
+		if err != nil {
+			return sent, failed, fmt.Errorf("get active stakes of %s failed: %w", user, err)
+		}
+		for _, position := range positions {
+			if !position.IsActive {
+				continue
+			}
+			endTime := time.Unix(position.EndTime.Int64(), 0)
+			var milestone string
+			switch {
+			case !now.Before(endTime):
+				milestone = config.StakeMilestoneMatured
+			case endTime.Sub(now) <= s.config.UpcomingWindow:
+				milestone = config.StakeMilestoneUpcoming
+			default:
+				continue
+			}
+			notice := MaturityNotice{
+				StakeIndex:  position.StakeIndex.String(),
+				UserAddress: user,
+				Amount:      position.Amount.String(),
+				Milestone:   milestone,
+				EndTime:     endTime,
+			}
+			for _, notifier := range s.notifiers {
+				status, err := s.notifyOnce(ctx, notifier, notice)
+				if err != nil {
+					return sent, failed, err
+				}
+				switch status {
+				case config.NotifyStatusSent:
+					sent++
+				case config.NotifyStatusFailed:
+					failed++
+				}
+			}
+		}
+	}
+	return sent, failed, nil
+}
+
+// notifyOnce 按 (stake_index, milestone, channel) 去重发送，返回本次发送状态，已发送或超过重试次数时返回 0
+func (s *StakeMaturityService) notifyOnce(ctx context.Context, notifier Notifier, notice MaturityNotice) (int8, error) {
+	record, err := repository.GetStakeNotification(notice.StakeIndex, notice.Milestone, notifier.Channel())
+	if err != nil {
+		return 0, err
+	}
+	if record != nil && (record.Status == config.NotifyStatusSent || record.Attempts >= maxNotifyAttempts) {
+		return 0, nil
+	}
+	if record == nil {
+		record = &model.StakeNotification{
+			StakeIndex:  notice.StakeIndex,
+			Milestone:   notice.Milestone,
+			Channel:     notifier.Channel(),
+			UserAddress: notice.UserAddress,
+			Amount:      notice.Amount,
+			EndTime:     notice.EndTime,
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	record.Attempts++
+	record.Status = config.NotifyStatusSent
+	record.Error = ""
+	if err := notifier.Notify(ctx, notice); err != nil {
+		record.Status = config.NotifyStatusFailed
+		record.Error = truncate(err.Error(), 512)
+		logger.GetLogger().WithFields(logrus.Fields{
+			"module":      "stake_maturity",
+			"action":      "notify",
+			"stake_index": notice.StakeIndex,
+			"milestone":   notice.Milestone,
+			"channel":     notifier.Channel(),
+			"attempts":    record.Attempts,
+			"error_code":  "NOTIFY_FAIL",
+			"detail":      err.Error(),
+		}).Error("Send stake maturity notification failed")
+	}
+	record.UpdatedAt = time.Now()
+	if err := repository.SaveStakeNotification(record); err != nil {
+		return 0, err
+	}
+	return record.Status, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
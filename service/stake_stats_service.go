@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"math/big"
+	"sort"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/contracts/mtk"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"time"
+)
+
+type StakeStatsService struct {
+	clientInfo *adapter.InitClient
+}
+
+func NewStakeStatsService(
+	clientInfo *adapter.InitClient,
+) *StakeStatsService {
+	return &StakeStatsService{
+		clientInfo: clientInfo,
+	}
+}
+
+// GetStats 根据已索引的 stake 记录统计当前 TVL、活跃质押人数及各周期分布，APY 从合约读取
+func (s *StakeStatsService) GetStats(ctx context.Context) (*dto.StakeStatsResponse, error) {
+	stakes, err := repository.GetActiveStakes()
+	if err != nil {
+		return nil, err
+	}
+
+	tvl := new(big.Int)
+	stakers := make(map[string]struct{})
+	periodAmounts := make(map[uint8]*big.Int)
+	periodCounts := make(map[uint8]int)
+	for _, stake := range stakes {
+		amount, ok := new(big.Int).SetString(stake.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid stake amount %q at index %s", stake.Amount, stake.IndexNum)
+		}
+		tvl.Add(tvl, amount)
+		stakers[stake.FromAddress] = struct{}{}
+		if periodAmounts[stake.Period] == nil {
+			periodAmounts[stake.Period] = new(big.Int)
+		}
+		periodAmounts[stake.Period].Add(periodAmounts[stake.Period], amount)
+		periodCounts[stake.Period]++
+	}
+
+	stakingContract, err := NewStakeService(s.clientInfo).NewStakeContract()
+	if err != nil {
+		return nil, err
+	}
+	periods := make([]dto.StakePeriodStat, 0, len(periodAmounts))
+	for period, amount := range periodAmounts {
+		apy, err := stakingContract.Apy(&bind.CallOpts{Context: ctx}, period)
+		if err != nil {
+			return nil, fmt.Errorf("get apy of period %d failed: %w", period, err)
+		}
+		periods = append(periods, dto.StakePeriodStat{
+			Period:       period,
+			ActiveStakes: periodCounts[period],
+			Amount:       amount.String(),
+			Apy:          apy.String(),
+		})
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Period < periods[j].Period })
+
+	res := &dto.StakeStatsResponse{
+		Tvl:           tvl.String(),
+		ActiveStakers: len(stakers),
+		ActiveStakes:  len(stakes),
+		Periods:       periods,
+	}
+	// 对账快照为可选数据，未生成过快照时不影响统计结果
+	if snapshot, err := repository.GetLatestTvlSnapshot(); err == nil {
+		res.OnChain = snapshot
+	}
+	return res, nil
+}
+
+// GetDailyStats 按自然日(UTC)统计 [from, to) 区间内的质押与提取笔数和金额
+func (s *StakeStatsService) GetDailyStats(from time.Time, to time.Time) ([]dto.StakeDailyStat, error) {
+	stakes, err := repository.GetStakesBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type dailyAmount struct {
+		stakeCount     int
+		stakeAmount    *big.Int
+		withdrawCount  int
+		withdrawAmount *big.Int
+	}
+	days := make(map[string]*dailyAmount)
+	for day := from.UTC().Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		days[day.Format(time.DateOnly)] = &dailyAmount{stakeAmount: new(big.Int), withdrawAmount: new(big.Int)}
+	}
+	for _, stake := range stakes {
+		day, ok := days[stake.Timestamp.UTC().Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(stake.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid stake amount %q at index %s", stake.Amount, stake.IndexNum)
+		}
+		switch stake.Method {
+		case config.StakedEventName:
+			day.stakeCount++
+			day.stakeAmount.Add(day.stakeAmount, amount)
+		case config.WithdrawnEventName:
+			day.withdrawCount++
+			day.withdrawAmount.Add(day.withdrawAmount, amount)
+		}
+	}
+
+	res := make([]dto.StakeDailyStat, 0, len(days))
+	for date, day := range days {
+		res = append(res, dto.StakeDailyStat{
+			Date:           date,
+			StakeCount:     day.stakeCount,
+			StakeAmount:    day.stakeAmount.String(),
+			WithdrawCount:  day.withdrawCount,
+			WithdrawAmount: day.withdrawAmount.String(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Date < res[j].Date })
+	return res, nil
+}
+
+// CheckOnChainTvl 对比索引 TVL 与质押合约持有的 stakingToken 余额，并保存对账快照
+func (s *StakeStatsService) CheckOnChainTvl(ctx context.Context) (*model.StakeTvlSnapshot, error) {
+	stakes, err := repository.GetActiveStakes()
+	if err != nil {
+		return nil, err
+	}
+	indexedTvl := new(big.Int)
+	for _, stake := range stakes {
+		amount, ok := new(big.Int).SetString(stake.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid stake amount %q at index %s", stake.Amount, stake.IndexNum)
+		}
+		indexedTvl.Add(indexedTvl, amount)
+	}
+
+	blockNumber, err := s.clientInfo.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get block number failed: %w", err)
+	}
+	stakingContract, err := NewStakeService(s.clientInfo).NewStakeContract()
+	if err != nil {
+		return nil, err
+	}
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)}
+	tokenAddr, err := stakingContract.StakingToken(callOpts)
+	if err != nil {
+		return nil, fmt.Errorf("get staking token failed: %w", err)
+	}
+	token, err := mtk.NewContracts(tokenAddr, s.clientInfo.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staking token contract: %w", err)
+	}
+	onchainTvl, err := token.BalanceOf(callOpts, stakeContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("get stake contract balance failed: %w", err)
+	}
+
+	snapshot := &model.StakeTvlSnapshot{
+		IndexedTvl:  indexedTvl.String(),
+		OnchainTvl:  onchainTvl.String(),
+		Diff:        new(big.Int).Sub(onchainTvl, indexedTvl).String(),
+		BlockNumber: int64(blockNumber),
+		CreatedDate: time.Now(),
+	}
+	if err := repository.AddTvlSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
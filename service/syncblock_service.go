@@ -49,7 +49,7 @@ func (s *SyncBlockService) SyncBlocks() {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		currentBlock, err := s.client.BlockNumber(ctx)
 		if err != nil {
-			fmt.Println("SyncBlockInfo: Failed to get current block number: %v", err)
+			fmt.Printf("SyncBlockInfo: Failed to get current block number: %v\n", err)
 			cancel()
 			time.Sleep(10 * time.Second)
 			continue
@@ -62,7 +62,7 @@ func (s *SyncBlockService) SyncBlocks() {
 		}
 		block, err := s.client.BlockByNumber(ctx, big.NewInt(int64(doneBlock)))
 		if err != nil {
-			fmt.Println("SyncBlockInfo: Failed to get block: %v", err)
+			fmt.Printf("SyncBlockInfo: Failed to get block: %v\n", err)
 			cancel()
 			time.Sleep(10 * time.Second)
 			continue
@@ -116,11 +116,11 @@ func (s *SyncBlockService) SyncBlocks() {
 				}
 			}
 
-			fmt.Println("Block Transaction Hash: %v", tx.Hash().Hex())
-			fmt.Println("Block Transaction ContractAddress: %v", receipt.ContractAddress.Hex())
-			fmt.Println("Block Transaction To: %v", toAddr.Hex())
-			fmt.Println("Block Transaction Value: %v", tx.Value())
-			fmt.Println("Block Transaction Data: %v", common.Bytes2Hex(tx.Data()))
+			fmt.Printf("Block Transaction Hash: %v\n", tx.Hash().Hex())
+			fmt.Printf("Block Transaction ContractAddress: %v\n", receipt.ContractAddress.Hex())
+			fmt.Printf("Block Transaction To: %v\n", toAddr.Hex())
+			fmt.Printf("Block Transaction Value: %v\n", tx.Value())
+			fmt.Printf("Block Transaction Data: %v\n", common.Bytes2Hex(tx.Data()))
 
 		}
 		doneBlock++
@@ -45,13 +45,32 @@ func (s *TransactionService) SendErc20(addr string, amount *big.Int) (res *dto.E
 		return nil, fmt.Errorf("contract create failed: %v\n", err)
 	}
 
+	// 预执行 transfer，余额不足等可预见的 revert 不再广播
+	tokenABI, err := mtk.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("parse token abi failed: %w", err)
+	}
+	if _, err := preflight(context.Background(), s.clientInfo, contractCall{
+		To:     contractAddr,
+		ABI:    tokenABI,
+		Method: "transfer",
+		Args:   []interface{}{toAddress, amount},
+	}); err != nil {
+		return nil, fmt.Errorf("transfer preflight failed: %w", err)
+	}
+
 	tx, err := mtkContract.Transfer(auth, toAddress, amount)
 	if tx == nil || err != nil {
 		return nil, fmt.Errorf("transfer failed: %v\n", err)
 	}
+	RecordOutboundTx(tx, s.clientInfo.FromAddress, config.TxPurposeTransferERC)
+	receipt, err := checkTxStatus(ethClient, tx.Hash())
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("check transaction failed: %v", err)
+	}
 	sym, _ := mtkContract.Symbol(&bind.CallOpts{})
 	decimal, _ := mtkContract.Decimals(&bind.CallOpts{})
-	res = &dto.ERCRes{Hash: tx.Hash().Hex(), Symbol: sym, Decimal: decimal, BlockNumber: nil}
+	res = &dto.ERCRes{Hash: tx.Hash().Hex(), Symbol: sym, Decimal: decimal, BlockNumber: receipt.BlockNumber}
 	fmt.Println("TransactionService SendErc20:--- ", res.Hash)
 	return res, nil
 }
@@ -72,8 +91,11 @@ func (s *TransactionService) SendBNB(addr string, amount *big.Int) (res *dto.ERC
 	if err != nil {
 		return nil, fmt.Errorf("retrieve gas price failed: %v", err)
 	}
-	// 1.3 BNB普通转账固定GasLimit为21000
+	// 1.3 BNB普通转账固定GasLimit为21000，发送前预执行确认余额充足
 	gasLimit := uint64(21000)
+	if _, err := preflight(context.Background(), s.clientInfo, contractCall{To: toAddress, Value: amount}); err != nil {
+		return nil, fmt.Errorf("transfer preflight failed: %w", err)
+	}
 	// 2. 创建BNB转账交易（普通交易，不涉及合约）
 	tx := types.NewTx(&types.LegacyTx{
 		Nonce:    initialNonce,
@@ -92,6 +114,7 @@ func (s *TransactionService) SendBNB(addr string, amount *big.Int) (res *dto.ERC
 	if err := ethClient.SendTransaction(context.Background(), signedTx); err != nil {
 		return nil, fmt.Errorf("send transaction failed: %v", err)
 	}
+	RecordOutboundTx(signedTx, fromAddress, config.TxPurposeTransferBNB)
 	receipt, err := checkTxStatus(ethClient, signedTx.Hash())
 	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
 		return nil, fmt.Errorf("check transaction failed: %v", err)
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"math/big"
+	"staking-interaction/adapter"
+	"staking-interaction/common/config"
+	"staking-interaction/common/logger"
+	"staking-interaction/dto"
+	"staking-interaction/model"
+	"staking-interaction/repository"
+	"time"
+)
+
+type TxTrackerService struct {
+	clientInfo *adapter.InitClient
+}
+
+func NewTxTrackerService(
+	clientInfo *adapter.InitClient,
+) *TxTrackerService {
+	return &TxTrackerService{
+		clientInfo: clientInfo,
+	}
+}
+
+// RecordOutboundTx 记录接口发起的交易，交易已广播，记录失败只打日志不影响返回
+func RecordOutboundTx(tx *types.Transaction, from common.Address, purpose string) {
+	record := model.OutboundTx{
+		Hash:        tx.Hash().Hex(),
+		Purpose:     purpose,
+		FromAddress: from.Hex(),
+		Value:       "0",
+		Nonce:       tx.Nonce(),
+		Status:      config.OutboundTxStatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if tx.To() != nil {
+		record.ToAddress = tx.To().Hex()
+	}
+	if tx.Value() != nil {
+		record.Value = tx.Value().String()
+	}
+	if err := repository.AddOutboundTx(&record); err != nil {
+		logger.GetLogger().WithFields(logrus.Fields{
+			"module":     "tx_tracker",
+			"action":     "record_outbound_tx",
+			"tx_hash":    record.Hash,
+			"purpose":    purpose,
+			"error_code": "RECORD_OUTBOUND_TX_FAIL",
+			"detail":     err.Error(),
+		}).Error("Record outbound transaction failed")
+	}
+}
+
+// RefreshReceipt 查询交易回执并更新状态，交易仍在 pending 时原样返回；
+// 已丢弃的交易也会重新查询，节点落后导致误判时可以恢复
+func (s *TxTrackerService) RefreshReceipt(ctx context.Context, record *model.OutboundTx) (*model.OutboundTx, error) {
+	if record.Status != config.OutboundTxStatusPending && record.Status != config.OutboundTxStatusDropped {
+		return record, nil
+	}
+	receipt, err := s.clientInfo.Client.TransactionReceipt(ctx, common.HexToHash(record.Hash))
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return s.expireIfDropped(ctx, record)
+		}
+		return nil, fmt.Errorf("get transaction receipt failed: %w", err)
+	}
+
+	record.Status = config.OutboundTxStatusSuccess
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		record.Status = config.OutboundTxStatusFailed
+	}
+	record.BlockNumber = receipt.BlockNumber.Uint64()
+	record.GasUsed = receipt.GasUsed
+	if receipt.EffectiveGasPrice != nil {
+		record.GasPrice = receipt.EffectiveGasPrice.String()
+		record.Fee = new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)).String()
+	}
+	record.UpdatedAt = time.Now()
+
+	if err := repository.UpdateOutboundTx(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// expireIfDropped 查不到回执的 pending 交易，发送地址已确认 nonce 越过该交易且节点查不到交易，
+// 或超过最长跟踪时间，标记为已丢弃
+func (s *TxTrackerService) expireIfDropped(ctx context.Context, record *model.OutboundTx) (*model.OutboundTx, error) {
+	if record.Status != config.OutboundTxStatusPending {
+		return record, nil
+	}
+	if time.Since(record.CreatedAt) <= config.Get().BlockchainConfig.Transaction.TrackMaxAge {
+		confirmedNonce, err := s.clientInfo.Client.NonceAt(ctx, common.HexToAddress(record.FromAddress), nil)
+		if err != nil {
+			return nil, fmt.Errorf("get nonce failed: %w", err)
+		}
+		if confirmedNonce <= record.Nonce {
+			return record, nil
+		}
+		// nonce 已被占用但节点还能查到交易，说明回执尚未同步，下一轮再查
+		_, _, err = s.clientInfo.Client.TransactionByHash(ctx, common.HexToHash(record.Hash))
+		if err == nil {
+			return record, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("get transaction failed: %w", err)
+		}
+	}
+
+	record.Status = config.OutboundTxStatusDropped
+	record.UpdatedAt = time.Now()
+	if err := repository.UpdateOutboundTx(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetTxStatus 查询交易状态及当前确认数
+func (s *TxTrackerService) GetTxStatus(ctx context.Context, hash string) (*dto.TxStatusResponse, error) {
+	record, err := repository.GetOutboundTxByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	record, err = s.RefreshReceipt(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmations uint64
+	if record.Status == config.OutboundTxStatusSuccess || record.Status == config.OutboundTxStatusFailed {
+		head, err := s.clientInfo.Client.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get block number failed: %w", err)
+		}
+		if head >= record.BlockNumber {
+			confirmations = head - record.BlockNumber + 1
+		}
+	}
+	return toTxStatusResponse(record, confirmations), nil
+}
+
+// WaitForConfirmations 轮询直到交易达到指定确认数、执行失败、被丢弃或 ctx 超时
+func (s *TxTrackerService) WaitForConfirmations(ctx context.Context, hash string, confirmations uint64) (*dto.TxStatusResponse, error) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		res, err := s.GetTxStatus(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if res.Status == "failed" || res.Status == "dropped" || res.Confirmations >= confirmations {
+			return res, nil
+		}
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func toTxStatusResponse(record *model.OutboundTx, confirmations uint64) *dto.TxStatusResponse {
+	status := "pending"
+	switch record.Status {
+	case config.OutboundTxStatusSuccess:
+		status = "success"
+	case config.OutboundTxStatusFailed:
+		status = "failed"
+	case config.OutboundTxStatusDropped:
+		status = "dropped"
+	}
+	return &dto.TxStatusResponse{
+		Hash:          record.Hash,
+		Purpose:       record.Purpose,
+		FromAddress:   record.FromAddress,
+		ToAddress:     record.ToAddress,
+		Value:         record.Value,
+		Nonce:         record.Nonce,
+		Status:        status,
+		BlockNumber:   record.BlockNumber,
+		Confirmations: confirmations,
+		GasUsed:       record.GasUsed,
+		GasPrice:      record.GasPrice,
+		Fee:           record.Fee,
+		CreatedAt:     record.CreatedAt,
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// API Key 请求签名使用的请求头
+const (
+	ApiKeyHeader       = "X-Api-Key"
+	ApiTimestampHeader = "X-Api-Timestamp"
+	ApiNonceHeader     = "X-Api-Nonce"
+	ApiSignatureHeader = "X-Api-Signature"
+)
+
+// ApiSigningString 待签名字符串，各部分以换行分隔：
+//
+//	METHOD
+//	/path?query
+//	timestamp（Unix 秒）
+//	nonce
+//	hex(sha256(body))
+func ApiSigningString(method string, path string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// ApiSignature 计算请求签名 hex(HMAC-SHA256(secret, signingString))
+func ApiSignature(secret string, method string, path string, timestamp string, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ApiSigningString(method, path, timestamp, nonce, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -52,3 +52,23 @@ func uintToString(i uint64) string {
 func stringToHash(s string) common.Hash {
 	return common.HexToHash(s)
 }
+
+// FormatUnits 将最小单位的整数金额按精度转换为十进制字符串，去掉小数末尾的 0，如 (1500000000000000000, 18) -> "1.5"
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(amount).String()
+	scale := int(decimals)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	res := digits[:len(digits)-scale]
+	if frac := strings.TrimRight(digits[len(digits)-scale:], "0"); frac != "" {
+		res += "." + frac
+	}
+	if amount.Sign() < 0 {
+		res = "-" + res
+	}
+	return res
+}
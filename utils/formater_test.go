@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		want     string
+	}{
+		{amount: "1500000000000000000", decimals: 18, want: "1.5"},
+		{amount: "1000000000000000000", decimals: 18, want: "1"},
+		{amount: "1", decimals: 18, want: "0.000000000000000001"},
+		{amount: "0", decimals: 18, want: "0"},
+		{amount: "-1500000", decimals: 6, want: "-1.5"},
+		{amount: "7", decimals: 0, want: "7"},
+	}
+	for _, tt := range tests {
+		amount, _ := new(big.Int).SetString(tt.amount, 10)
+		if got := FormatUnits(amount, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+	if got := FormatUnits(nil, 18); got != "0" {
+		t.Errorf("FormatUnits(nil) = %s, want 0", got)
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+)
+
+// MerkleTree 排序对哈希的 Merkle 树，与 OpenZeppelin MerkleProof.verify 兼容
+type MerkleTree struct {
+	layers [][]common.Hash
+}
+
+// MerkleLeaf 计算叶子节点 keccak256(abi.encodePacked(uint256 index, address account, uint256 amount))
+func MerkleLeaf(index uint64, account common.Address, amount *big.Int) common.Hash {
+	return crypto.Keccak256Hash(
+		math.U256Bytes(new(big.Int).SetUint64(index)),
+		account.Bytes(),
+		math.U256Bytes(new(big.Int).Set(amount)),
+	)
+}
+
+// NewMerkleTree 按叶子顺序构建 Merkle 树，奇数节点直接提升到上一层
+func NewMerkleTree(leaves []common.Hash) (*MerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("merkle tree needs at least one leaf")
+	}
+	layer := make([]common.Hash, len(leaves))
+	copy(layer, leaves)
+	layers := [][]common.Hash{layer}
+	for len(layer) > 1 {
+		next := make([]common.Hash, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			next = append(next, hashPair(layer[i], layer[i+1]))
+		}
+		layers = append(layers, next)
+		layer = next
+	}
+	return &MerkleTree{layers: layers}, nil
+}
+
+// Root 返回 Merkle 根
+func (t *MerkleTree) Root() common.Hash {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// Proof 返回第 index 个叶子的证明路径，自底向上
+func (t *MerkleTree) Proof(index int) ([]common.Hash, error) {
+	if index < 0 || index >= len(t.layers[0]) {
+		return nil, fmt.Errorf("leaf index %d out of range", index)
+	}
+	proof := make([]common.Hash, 0, len(t.layers)-1)
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		sibling := index ^ 1
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof 校验叶子和证明能否计算出给定的根
+func VerifyMerkleProof(proof []common.Hash, root common.Hash, leaf common.Hash) bool {
+	computed := leaf
+	for _, node := range proof {
+		computed = hashPair(computed, node)
+	}
+	return computed == root
+}
+
+// hashPair 两个节点按字节序排序后拼接哈希，验证时无需记录左右位置
+func hashPair(a common.Hash, b common.Hash) common.Hash {
+	if bytes.Compare(a.Bytes(), b.Bytes()) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a.Bytes(), b.Bytes())
+}
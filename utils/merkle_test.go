@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bytes"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"testing"
+)
+
+func testLeaves(n int) []common.Hash {
+	leaves := make([]common.Hash, n)
+	for i := range leaves {
+		account := common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		leaves[i] = MerkleLeaf(uint64(i), account, big.NewInt(int64(100*(i+1))))
+	}
+	return leaves
+}
+
+// sortedPair 领取合约 (OpenZeppelin MerkleProof) 的节点哈希：keccak256(min(a, b) ++ max(a, b))
+func sortedPair(a common.Hash, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return common.BytesToHash(crypto.Keccak256(append(a.Bytes(), b.Bytes()...)))
+}
+
+func TestMerkleLeafEncodePacked(t *testing.T) {
+	account := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	// abi.encodePacked(uint256 index, address account, uint256 amount)
+	packed := make([]byte, 0, 84)
+	packed = append(packed, common.LeftPadBytes(big.NewInt(7).Bytes(), 32)...)
+	packed = append(packed, account.Bytes()...)
+	packed = append(packed, common.LeftPadBytes(big.NewInt(1e18).Bytes(), 32)...)
+
+	want := common.BytesToHash(crypto.Keccak256(packed))
+	if got := MerkleLeaf(7, account, big.NewInt(1e18)); got != want {
+		t.Fatalf("leaf = %s, want %s", got.Hex(), want.Hex())
+	}
+}
+
+func TestHashPairSorted(t *testing.T) {
+	a := common.HexToHash("0x01")
+	b := common.HexToHash("0xff")
+	if hashPair(a, b) != hashPair(b, a) {
+		t.Fatal("hashPair should not depend on argument order")
+	}
+	if got, want := hashPair(b, a), sortedPair(a, b); got != want {
+		t.Fatalf("hashPair = %s, want %s", got.Hex(), want.Hex())
+	}
+}
+
+func TestMerkleTreeRoot(t *testing.T) {
+	l := testLeaves(3)
+	tests := []struct {
+		name   string
+		leaves []common.Hash
+		root   common.Hash
+	}{
+		{name: "single leaf", leaves: l[:1], root: l[0]},
+		{name: "two leaves", leaves: l[:2], root: sortedPair(l[0], l[1])},
+		// 奇数节点直接提升到上一层
+		{name: "three leaves", leaves: l[:3], root: sortedPair(sortedPair(l[0], l[1]), l[2])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := NewMerkleTree(tt.leaves)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tree.Root() != tt.root {
+				t.Fatalf("root = %s, want %s", tree.Root().Hex(), tt.root.Hex())
+			}
+		})
+	}
+
+	if _, err := NewMerkleTree(nil); err == nil {
+		t.Fatal("empty leaf set should fail")
+	}
+}
+
+func TestMerkleProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		leaves := testLeaves(n)
+		tree, err := NewMerkleTree(leaves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, leaf := range leaves {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("n=%d: proof %d: %v", n, i, err)
+			}
+			if !VerifyMerkleProof(proof, tree.Root(), leaf) {
+				t.Fatalf("n=%d: proof for leaf %d does not verify", n, i)
+			}
+		}
+		if _, err := tree.Proof(n); err == nil {
+			t.Fatalf("n=%d: out of range proof should fail", n)
+		}
+	}
+}
+
+func TestMerkleProofTampered(t *testing.T) {
+	leaves := testLeaves(5)
+	tree, err := NewMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := common.BigToAddress(big.NewInt(0x1000 + 2))
+	proof, err := tree.Proof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		proof func() []common.Hash
+		leaf  common.Hash
+	}{
+		{
+			name: "changed sibling",
+			proof: func() []common.Hash {
+				p := append([]common.Hash(nil), proof...)
+				p[0][31] ^= 1
+				return p
+			},
+			leaf: leaves[2],
+		},
+		{
+			name:  "changed amount",
+			proof: func() []common.Hash { return proof },
+			leaf:  MerkleLeaf(2, account, big.NewInt(301)),
+		},
+		{
+			name:  "changed account",
+			proof: func() []common.Hash { return proof },
+			leaf:  MerkleLeaf(2, common.BigToAddress(big.NewInt(0x2000)), big.NewInt(300)),
+		},
+		{
+			name:  "missing sibling",
+			proof: func() []common.Hash { return proof[1:] },
+			leaf:  leaves[2],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if VerifyMerkleProof(tt.proof(), tree.Root(), tt.leaf) {
+				t.Fatal("tampered proof should not verify")
+			}
+		})
+	}
+	if !VerifyMerkleProof(proof, tree.Root(), MerkleLeaf(2, account, big.NewInt(300))) {
+		t.Fatal("original proof should verify")
+	}
+}
@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SignInChainEthereum = "Ethereum"
+	SignInChainSolana   = "Solana"
+)
+
+// SignInMessage EIP-4361 (Sign-In with Ethereum) 格式的登录消息，Solana 使用相同格式仅替换账户类型
+type SignInMessage struct {
+	Scheme         string // 可选，如 https
+	Domain         string
+	Chain          string // 首行中的账户类型: Ethereum/Solana
+	Address        string
+	Statement      string // 可选，不能包含换行
+	URI            string
+	Version        string
+	ChainID        string
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// String 按 EIP-4361 ABNF 生成待签名消息
+func (m *SignInMessage) String() string {
+	var b strings.Builder
+	if m.Scheme != "" {
+		b.WriteString(m.Scheme + "://")
+	}
+	fmt.Fprintf(&b, "%s wants you to sign in with your %s account:\n", m.Domain, m.Chain)
+	b.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	fmt.Fprintf(&b, "Version: %s\n", m.Version)
+	fmt.Fprintf(&b, "Chain ID: %s\n", m.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s", m.IssuedAt.UTC().Format(time.RFC3339))
+	if m.ExpirationTime != nil {
+		fmt.Fprintf(&b, "\nExpiration Time: %s", m.ExpirationTime.UTC().Format(time.RFC3339))
+	}
+	if m.NotBefore != nil {
+		fmt.Fprintf(&b, "\nNot Before: %s", m.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if m.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, resource := range m.Resources {
+			b.WriteString("\n- " + resource)
+		}
+	}
+	return b.String()
+}
+
+// ParseSignInMessage 解析 EIP-4361 格式消息，字段顺序和格式必须严格符合规范
+func ParseSignInMessage(message string) (*SignInMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	p := &signInParser{lines: lines}
+	m := &SignInMessage{}
+
+	header, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("sign-in message is empty")
+	}
+	const marker = " wants you to sign in with your "
+	idx := strings.Index(header, marker)
+	if idx <= 0 || !strings.HasSuffix(header, " account:") {
+		return nil, fmt.Errorf("invalid sign-in message header: %q", header)
+	}
+	m.Domain = header[:idx]
+	m.Chain = strings.TrimSuffix(header[idx+len(marker):], " account:")
+	if scheme, domain, found := strings.Cut(m.Domain, "://"); found {
+		m.Scheme, m.Domain = scheme, domain
+	}
+	if m.Domain == "" || strings.ContainsAny(m.Domain, " /") {
+		return nil, fmt.Errorf("invalid domain: %q", m.Domain)
+	}
+
+	if m.Address, ok = p.next(); !ok || m.Address == "" {
+		return nil, fmt.Errorf("missing address")
+	}
+	if line, ok := p.next(); !ok || line != "" {
+		return nil, fmt.Errorf("expected empty line after address")
+	}
+	// 有 statement 时为 statement 加空行，否则只有一个空行
+	line, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of message")
+	}
+	if line != "" {
+		m.Statement = line
+		if line, ok = p.next(); !ok || line != "" {
+			return nil, fmt.Errorf("expected empty line after statement")
+		}
+	}
+
+	var err error
+	if m.URI, err = p.field("URI"); err != nil {
+		return nil, err
+	}
+	if m.Version, err = p.field("Version"); err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = p.field("Chain ID"); err != nil {
+		return nil, err
+	}
+	if m.Nonce, err = p.field("Nonce"); err != nil {
+		return nil, err
+	}
+	issuedAt, err := p.field("Issued At")
+	if err != nil {
+		return nil, err
+	}
+	if m.IssuedAt, err = time.Parse(time.RFC3339Nano, issuedAt); err != nil {
+		return nil, fmt.Errorf("invalid issued at: %w", err)
+	}
+	if value, ok := p.optionalField("Expiration Time"); ok {
+		expiration, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiration time: %w", err)
+		}
+		m.ExpirationTime = &expiration
+	}
+	if value, ok := p.optionalField("Not Before"); ok {
+		notBefore, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid not before: %w", err)
+		}
+		m.NotBefore = &notBefore
+	}
+	if value, ok := p.optionalField("Request ID"); ok {
+		m.RequestID = value
+	}
+	if line, ok := p.peek(); ok && line == "Resources:" {
+		p.pos++
+		for {
+			line, ok := p.peek()
+			if !ok || !strings.HasPrefix(line, "- ") {
+				break
+			}
+			m.Resources = append(m.Resources, strings.TrimPrefix(line, "- "))
+			p.pos++
+		}
+	}
+	if line, ok := p.next(); ok {
+		return nil, fmt.Errorf("unexpected line in sign-in message: %q", line)
+	}
+
+	if m.URI == "" || m.Version == "" || m.ChainID == "" {
+		return nil, fmt.Errorf("uri, version and chain id are required")
+	}
+	if len(m.Nonce) < 8 || !isAlphanumeric(m.Nonce) {
+		return nil, fmt.Errorf("nonce should be at least 8 alphanumeric characters")
+	}
+	return m, nil
+}
+
+type signInParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *signInParser) next() (string, bool) {
+	line, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return line, ok
+}
+
+func (p *signInParser) peek() (string, bool) {
+	if p.pos >= len(p.lines) {
+		return "", false
+	}
+	return p.lines[p.pos], true
+}
+
+func (p *signInParser) field(name string) (string, error) {
+	value, ok := p.optionalField(name)
+	if !ok {
+		return "", fmt.Errorf("missing %s", name)
+	}
+	return value, nil
+}
+
+func (p *signInParser) optionalField(name string) (string, bool) {
+	line, ok := p.peek()
+	if !ok || !strings.HasPrefix(line, name+": ") {
+		return "", false
+	}
+	p.pos++
+	return strings.TrimPrefix(line, name+": "), true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}